@@ -0,0 +1,64 @@
+package main
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+)
+
+type passwordChecker interface {
+	CheckPassword(ctx context.Context, userName, password string) (bool, error)
+}
+
+type addrLimiter interface {
+	LimitAddr(ctx context.Context, fullMethod, remoteAddr string) (retryAfter int, limited bool)
+}
+
+// rateLimited rejects a remote address over its limit before next checks its password, the limit of the
+// path is configured as the one of a method with the same name.
+func rateLimited(logger app.Logger, limiter addrLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, limited := limiter.LimitAddr(r.Context(), r.URL.Path, r.RemoteAddr); limited {
+			logger.Warn("admin endpoint rate limit exceeded", map[string]interface{}{"path": r.URL.Path, "remoteAddr": r.RemoteAddr})
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminOnly lets through requests with Basic credentials of an admin of the default tenant, the admin port
+// serves the whole process rather than a tenant.
+func adminOnly(logger app.Logger, checker passwordChecker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userName, password, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+
+			return
+		}
+
+		isAdmin, err := checker.CheckPassword(r.Context(), userName, password)
+		switch {
+		case errors.Is(err, app.ErrNotAdmin):
+			http.Error(w, "only admin has access to this endpoint", http.StatusForbidden)
+			return
+		case err != nil || !isAdmin:
+			logger.Warn("admin endpoint authentication failed", map[string]interface{}{"path": r.URL.Path, "username": userName})
+			w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
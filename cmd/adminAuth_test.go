@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/grpcserver"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type passwordCheckerFunc func(ctx context.Context, userName, password string) (bool, error)
+
+func (f passwordCheckerFunc) CheckPassword(ctx context.Context, userName, password string) (bool, error) {
+	return f(ctx, userName, password)
+}
+
+func TestAdminOnlyLevelHandler(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	checker := passwordCheckerFunc(func(_ context.Context, userName, password string) (bool, error) {
+		switch {
+		case userName == "admin" && password == "admin":
+			return true, nil
+		case userName == "user" && password == "user":
+			return false, app.ErrNotAdmin
+		default:
+			return false, errors.New("invalid password")
+		}
+	})
+	handler := adminOnly(logg, checker, logg.LevelHandler())
+
+	testTable := []struct {
+		name         string
+		username     string
+		password     string
+		expectedCode int
+	}{
+		{name: "anonymous", expectedCode: http.StatusUnauthorized},
+		{name: "wrong password", username: "admin", password: "guess", expectedCode: http.StatusUnauthorized},
+		{name: "not admin", username: "user", password: "user", expectedCode: http.StatusForbidden},
+		{name: "admin", username: "admin", password: "admin", expectedCode: http.StatusOK},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"DEBUG","ttl":"1m"}`))
+			if testCase.username != "" {
+				req.SetBasicAuth(testCase.username, testCase.password)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, testCase.expectedCode, w.Code)
+
+			if testCase.expectedCode == http.StatusUnauthorized {
+				require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+				require.Equal(t, "INFO", logg.LevelState().Level, "the level is not changed")
+			}
+		})
+	}
+
+	require.Equal(t, "DEBUG", logg.LevelState().Level)
+}
+
+func TestRateLimitedAdminEndpoint(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	checks := 0
+	checker := passwordCheckerFunc(func(_ context.Context, _, _ string) (bool, error) {
+		checks++
+		return false, errors.New("invalid password")
+	})
+	limiter := grpcserver.NewRateLimiter(logg, ratelimit.NewMemoryBackend(), ratelimit.Limits{Default: ratelimit.Limit{Rate: 0.01, Burst: 2}})
+	handler := rateLimited(logg, limiter, adminOnly(logg, checker, logg.LevelHandler()))
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"DEBUG"}`))
+		req.RemoteAddr = remoteAddr
+		req.SetBasicAuth("admin", "guess")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		return w
+	}
+
+	require.Equal(t, http.StatusUnauthorized, send("10.0.0.66:5000").Code)
+	require.Equal(t, http.StatusUnauthorized, send("10.0.0.66:5001").Code)
+
+	w := send("10.0.0.66:5002")
+	require.Equal(t, http.StatusTooManyRequests, w.Code, "another port of the same host shares the limit")
+	require.Equal(t, "100", w.Header().Get("Retry-After"))
+	require.Equal(t, 2, checks, "the password is not checked over the limit")
+
+	require.Equal(t, http.StatusUnauthorized, send("10.0.0.7:5000").Code)
+}
@@ -0,0 +1,135 @@
+package main
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"google.golang.org/grpc"
+)
+
+// grpcComponent binds the port at start, so a taken port aborts the startup, and falls back from
+// GracefulStop to Stop when a stream outlives the shutdown timeout.
+func grpcComponent(logger app.Logger, server *grpc.Server, address string) app.Component {
+	var lsn net.Listener
+
+	return app.Component{
+		Name: "server.grpc",
+		Start: func(context.Context) error {
+			var err error
+			lsn, err = net.Listen("tcp", address)
+			return err
+		},
+		Run: func(context.Context) error {
+			logger.Info("starting server on "+lsn.Addr().String(), nil)
+			return server.Serve(lsn)
+		},
+		Stop: func(context.Context) error {
+			server.GracefulStop()
+			return nil
+		},
+		Kill:     server.Stop,
+		Critical: true,
+	}
+}
+
+// httpComponent serves TLS when the server has a TLS config, Shutdown waits for the running requests
+// until the shutdown timeout and Close drops the rest.
+func httpComponent(logger app.Logger, name string, server *http.Server) app.Component {
+	var lsn net.Listener
+
+	return app.Component{
+		Name: name,
+		Start: func(context.Context) error {
+			var err error
+			lsn, err = net.Listen("tcp", server.Addr)
+			return err
+		},
+		Run: func(context.Context) error {
+			logger.Info("starting "+name+" on "+lsn.Addr().String(), map[string]interface{}{"tls": server.TLSConfig != nil})
+
+			var err error
+			if server.TLSConfig != nil {
+				err = server.ServeTLS(lsn, "", "")
+			} else {
+				err = server.Serve(lsn)
+			}
+
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+
+			return err
+		},
+		Stop: server.Shutdown,
+		Kill: func() {
+			_ = server.Close()
+		},
+		Critical: true,
+	}
+}
+
+// workerComponent reports the worker to health checks, it must run until its context is canceled.
+func workerComponent(name string, run func(ctx context.Context)) app.Component {
+	return app.Component{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			run(ctx)
+			return nil
+		},
+	}
+}
+
+type snapshotter interface {
+	SaveSnapshot(path string) error
+}
+
+// snapshotComponent saves the storage every interval, when it is positive, and once more on shutdown
+// after the servers and workers have stopped writing to it, unless the shutdown deadline has passed.
+func snapshotComponent(logger app.Logger, storage snapshotter, path string, interval time.Duration) app.Component {
+	return app.Component{
+		Name: "storage.snapshot",
+		Run: func(ctx context.Context) error {
+			if interval <= 0 {
+				<-ctx.Done()
+				return nil
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					if err := storage.SaveSnapshot(path); err != nil {
+						logger.Error("failed to save storage snapshot", map[string]interface{}{"error": err, "path": path})
+					}
+				}
+			}
+		},
+		Stop: func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("final snapshot skipped: %w", err)
+			}
+
+			saved := make(chan error, 1)
+			go func() {
+				saved <- storage.SaveSnapshot(path)
+			}()
+
+			select {
+			case err := <-saved:
+				return err
+			case <-ctx.Done():
+				return fmt.Errorf("final snapshot not saved in time: %w", ctx.Err())
+			}
+		},
+	}
+}
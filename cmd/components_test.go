@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type slowSnapshotter struct {
+	delay time.Duration
+	saved chan string
+}
+
+func (s *slowSnapshotter) SaveSnapshot(path string) error {
+	time.Sleep(s.delay)
+	s.saved <- path
+
+	return nil
+}
+
+func TestSnapshotComponentStop(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name          string
+		delay         time.Duration
+		timeout       time.Duration
+		expectedError string
+		saved         bool
+	}{
+		{
+			name:    "saved before the deadline",
+			timeout: time.Second,
+			saved:   true,
+		},
+		{
+			name:          "deadline during the save",
+			delay:         time.Second,
+			timeout:       20 * time.Millisecond,
+			expectedError: "final snapshot not saved in time: context deadline exceeded",
+		},
+		{
+			name:          "deadline already passed",
+			expectedError: "final snapshot skipped: context deadline exceeded",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			storage := &slowSnapshotter{delay: testCase.delay, saved: make(chan string, 1)}
+			component := snapshotComponent(logg, storage, "users.json", 0)
+
+			ctx, cancel := context.WithTimeout(context.Background(), testCase.timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := component.Stop(ctx)
+			require.Less(t, time.Since(started), testCase.timeout+500*time.Millisecond)
+
+			if testCase.expectedError != "" {
+				require.EqualError(t, err, testCase.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, testCase.saved, len(storage.saved) == 1)
+		})
+	}
+}
@@ -2,35 +2,522 @@ package main
 
 //nolint:depguard
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/spf13/viper"
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/grpcserver"
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/outbox"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
+	"github.com/Baraulia/X-Labs_Test/internal/webhook"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Config struct {
-	Logger LoggerConf
-	GRPC   GRPCConf
+	Logger      LoggerConf
+	GRPC        GRPCConf
+	HTTP        HTTPConf
+	Admin       AdminConf
+	Health      HealthConf
+	Purger      PurgerConf
+	Attributes  AttributesConf
+	Batch       BatchConf
+	Events      EventsConf
+	Webhooks    WebhooksConf
+	Invitations InvitationsConf
+	Outbox      OutboxConf
+	Tracing     TracingConf
+	Password    PasswordConf
+	Auth        AuthConf
+	TLS         TLSConf
+	RateLimit   RateLimitConf `mapstructure:"rate_limit"`
+	Access      AccessConf
+	Shutdown    ShutdownConf
+	Storage     StorageConf
 }
 
 type LoggerConf struct {
-	Level string `mapstructure:"level" default:"INFO"`
+	Level  string           `mapstructure:"level" default:"INFO"`
+	Format string           `mapstructure:"format" default:"console"`
+	Sinks  []LoggerSinkConf `mapstructure:"sinks"`
+	Redact LoggerRedactConf `mapstructure:"redact"`
+}
+
+// LoggerRedactConf extends the built-in redaction of pkg/logger with extra field names and value regexps.
+type LoggerRedactConf struct {
+	Keys     []string `mapstructure:"keys"`
+	Patterns []string `mapstructure:"patterns"`
+}
+
+type LoggerSinkConf struct {
+	Type     string `mapstructure:"type"`
+	Level    string `mapstructure:"level"`
+	Format   string `mapstructure:"format"`
+	File     LoggerFileConf
+	Sampling LoggerSamplingConf
+}
+
+type LoggerFileConf struct {
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb" default:"100"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	Compress   bool   `mapstructure:"compress"`
+}
+
+type LoggerSamplingConf struct {
+	Initial    int           `mapstructure:"initial"`
+	Thereafter int           `mapstructure:"thereafter"`
+	Tick       time.Duration `mapstructure:"tick" default:"1s"`
+	Level      string        `mapstructure:"level" default:"DEBUG"`
+}
+
+func (c LoggerConf) LoggerConfig() logger.Config {
+	sinks := make([]logger.SinkConfig, 0, len(c.Sinks))
+	for _, sink := range c.Sinks {
+		sinks = append(sinks, logger.SinkConfig{
+			Type:   sink.Type,
+			Level:  sink.Level,
+			Format: sink.Format,
+			File: logger.FileConfig{
+				Path:       sink.File.Path,
+				MaxSizeMB:  sink.File.MaxSizeMB,
+				MaxAgeDays: sink.File.MaxAgeDays,
+				MaxBackups: sink.File.MaxBackups,
+				Compress:   sink.File.Compress,
+			},
+			Sampling: logger.SamplingConfig{
+				Initial:    sink.Sampling.Initial,
+				Thereafter: sink.Sampling.Thereafter,
+				Tick:       sink.Sampling.Tick,
+				Level:      sink.Sampling.Level,
+			},
+		})
+	}
+
+	return logger.Config{
+		Level:          c.Level,
+		Format:         c.Format,
+		Sinks:          sinks,
+		RedactKeys:     c.Redact.Keys,
+		RedactPatterns: c.Redact.Patterns,
+	}
 }
 
 type GRPCConf struct {
-	Port string `mapstructure:"port" default:"50051"`
+	Port       string `mapstructure:"port" default:"50051"`
+	Reflection bool   `mapstructure:"reflection"`
+}
+
+type HealthConf struct {
+	Interval time.Duration `mapstructure:"interval" default:"5s"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"1s"`
+}
+
+type HTTPConf struct {
+	Port string `mapstructure:"port" default:"8080"`
+}
+
+// AdminConf is the operator-only http port serving /metrics and /loglevel, it must not be exposed publicly.
+type AdminConf struct {
+	Port string `mapstructure:"port" default:"9090"`
+}
+
+// ShutdownConf bounds the graceful stop of every component, a component still busy after Timeout is
+// stopped at once, e.g. the grpc server drops its running streams.
+type ShutdownConf struct {
+	Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+}
+
+// StorageConf saves the users, and the webhooks with their delivery queue, to Snapshot.Path on shutdown and
+// every Snapshot.Interval, they are restored from it at startup. An empty path keeps the storage in memory only.
+type StorageConf struct {
+	Snapshot StorageSnapshotConf `mapstructure:"snapshot"`
+}
+
+type StorageSnapshotConf struct {
+	Path     string        `mapstructure:"path"`
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+type PurgerConf struct {
+	Retention time.Duration `mapstructure:"retention" default:"720h"`
+	Interval  time.Duration `mapstructure:"interval" default:"1h"`
+}
+
+// AttributesConf is the attribute schema of every tenant, a tenant listed in Tenants uses its own schema
+// instead.
+type AttributesConf struct {
+	MaxAttributes int                             `mapstructure:"max_attributes"`
+	Schema        map[string]AttributeConf        `mapstructure:"schema"`
+	Tenants       map[string]TenantAttributesConf `mapstructure:"tenants"`
+}
+
+type TenantAttributesConf struct {
+	MaxAttributes int                      `mapstructure:"max_attributes"`
+	Schema        map[string]AttributeConf `mapstructure:"schema"`
+}
+
+type AttributeConf struct {
+	Type      string `mapstructure:"type" default:"string"`
+	MaxLength int    `mapstructure:"max_length"`
+}
+
+func (c AttributesConf) AttributeSchema() app.AttributeSchema {
+	return attributeSchema(c.MaxAttributes, c.Schema)
+}
+
+func (c AttributesConf) TenantAttributeSchemas() map[string]app.AttributeSchema {
+	schemas := make(map[string]app.AttributeSchema, len(c.Tenants))
+	for tenantID, tenant := range c.Tenants {
+		schemas[tenantID] = attributeSchema(tenant.MaxAttributes, tenant.Schema)
+	}
+
+	return schemas
+}
+
+func attributeSchema(maxAttributes int, attributes map[string]AttributeConf) app.AttributeSchema {
+	schema := app.AttributeSchema{
+		MaxAttributes: maxAttributes,
+		Attributes:    make(map[string]app.AttributeRule, len(attributes)),
+	}
+
+	for key, attribute := range attributes {
+		attributeType := app.AttributeType(attribute.Type)
+		if attributeType == "" {
+			attributeType = app.AttributeString
+		}
+
+		schema.Attributes[key] = app.AttributeRule{Type: attributeType, MaxLength: attribute.MaxLength}
+	}
+
+	return schema
+}
+
+type BatchConf struct {
+	HashWorkers int `mapstructure:"hash_workers"`
+}
+
+type EventsConf struct {
+	Capacity int `mapstructure:"capacity" default:"1024"`
+}
+
+// InvitationsConf sets how long an invitation token can be accepted.
+type InvitationsConf struct {
+	TTL time.Duration `mapstructure:"ttl" default:"72h"`
+}
+
+type WebhooksConf struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Workers        int           `mapstructure:"workers" default:"4"`
+	MaxAttempts    int           `mapstructure:"max_attempts" default:"8"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" default:"1s"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" default:"10m"`
+	PollInterval   time.Duration `mapstructure:"poll_interval" default:"1s"`
+	Timeout        time.Duration `mapstructure:"timeout" default:"10s"`
+}
+
+func (c WebhooksConf) DispatcherConfig() webhook.Config {
+	return webhook.Config{
+		Workers:        c.Workers,
+		MaxAttempts:    c.MaxAttempts,
+		InitialBackoff: c.InitialBackoff,
+		MaxBackoff:     c.MaxBackoff,
+		PollInterval:   c.PollInterval,
+		Timeout:        c.Timeout,
+	}
+}
+
+type OutboxConf struct {
+	Publisher string        `mapstructure:"publisher"`
+	BatchSize int           `mapstructure:"batch_size" default:"100"`
+	Interval  time.Duration `mapstructure:"interval" default:"1s"`
+	File      OutboxFileConf
+	NATS      OutboxNATSConf
+}
+
+type OutboxFileConf struct {
+	Path string `mapstructure:"path"`
+}
+
+type OutboxNATSConf struct {
+	Address string        `mapstructure:"address" default:"nats://localhost:4222"`
+	Subject string        `mapstructure:"subject" default:"users"`
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+// NewPublisher builds the configured outbox publisher. An empty publisher disables the outbox;
+// the in-process channel publisher is meant for embedding and cannot be selected here.
+func (c OutboxConf) NewPublisher() (outbox.Publisher, error) {
+	switch c.Publisher {
+	case "":
+		return nil, nil
+	case "file":
+		return outbox.NewFilePublisher(c.File.Path)
+	case "nats":
+		return outbox.NewNATSPublisher(c.NATS.Address, c.NATS.Subject, c.NATS.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher: %s", c.Publisher)
+	}
+}
+
+type PasswordConf struct {
+	MinLength     int  `mapstructure:"min_length"`
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+}
+
+func (c PasswordConf) PasswordPolicy() app.PasswordPolicy {
+	return app.PasswordPolicy{
+		MinLength:     c.MinLength,
+		RequireUpper:  c.RequireUpper,
+		RequireLower:  c.RequireLower,
+		RequireDigit:  c.RequireDigit,
+		RequireSymbol: c.RequireSymbol,
+	}
+}
+
+// RateLimitConf limits the requests of every client, see grpcserver.RateLimiter. Rate is in requests per
+// second; methods listed by their short name ("CreateUser") get their own limit instead of the shared one.
+type RateLimitConf struct {
+	Enabled bool                           `mapstructure:"enabled"`
+	Rate    float64                        `mapstructure:"rate" default:"50"`
+	Burst   int                            `mapstructure:"burst" default:"100"`
+	Methods map[string]RateLimitMethodConf `mapstructure:"methods"`
+}
+
+// AccessConf restricts methods open to anonymous callers and non-admin users, e.g. GetUsers, to admins,
+// see grpcserver.AccessPolicy. It is reloaded without a restart.
+type AccessConf struct {
+	AdminOnly []string `mapstructure:"admin_only"`
+}
+
+type RateLimitMethodConf struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// Limits converts the config, a disabled limiter has no limits.
+func (c RateLimitConf) Limits() ratelimit.Limits {
+	if !c.Enabled {
+		return ratelimit.Limits{}
+	}
+
+	limits := ratelimit.Limits{
+		Default: ratelimit.Limit{Rate: c.Rate, Burst: c.Burst},
+		Methods: make(map[string]ratelimit.Limit, len(c.Methods)),
+	}
+
+	for method, limit := range c.Methods {
+		limits.Methods[method] = ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst}
+	}
+
+	return limits
 }
 
-func NewConfig(path string) (Config, error) {
-	var conf Config
-	viper.SetConfigFile(path)
+// AuthConf holds the credentials of the admin created at startup, the pepper appended to passwords
+// before hashing and the HS256 key of bearer tokens, which are rejected when it is empty. The secrets may
+// be read from files named by the *_file keys, see NewConfig. MaxFailedLogins wrong passwords in a row lock
+// a user, zero disables locking.
+type AuthConf struct {
+	AdminName         string `mapstructure:"admin_name" default:"admin"`
+	AdminPassword     string `mapstructure:"admin_password" default:"admin"`
+	AdminPasswordFile string `mapstructure:"admin_password_file"`
+	Pepper            string `mapstructure:"pepper" default:"secret"`
+	PepperFile        string `mapstructure:"pepper_file"`
+	TokenKey          string `mapstructure:"token_key"`
+	TokenKeyFile      string `mapstructure:"token_key_file"`
+	MaxFailedLogins   int    `mapstructure:"max_failed_logins" default:"5"`
+}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return conf, fmt.Errorf("error while reading config file: %w", err)
+// TLSConf is the PEM certificate and key of the gRPC and HTTP listeners, either inline or read from
+// the *_file keys. TLS is disabled when both are empty; the admin port always serves plain http.
+type TLSConf struct {
+	Cert     string `mapstructure:"cert"`
+	CertFile string `mapstructure:"cert_file"`
+	Key      string `mapstructure:"key"`
+	KeyFile  string `mapstructure:"key_file"`
+}
+
+func (c TLSConf) TLSConfig() (*tls.Config, error) {
+	if c.Cert == "" && c.Key == "" {
+		return nil, nil
 	}
 
-	if err := viper.Unmarshal(&conf); err != nil {
-		return conf, fmt.Errorf("error while unmarshaling config: %w", err)
+	certificate, err := tls.X509KeyPair([]byte(c.Cert), []byte(c.Key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate or key: %w", err)
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}, nil
+}
+
+type TracingConf struct {
+	Exporter    string  `mapstructure:"exporter"`
+	ServiceName string  `mapstructure:"service_name" default:"x-labs-users"`
+	SampleRatio float64 `mapstructure:"sample_ratio" default:"1"`
+	OTLP        TracingOTLPConf
+}
+
+type TracingOTLPConf struct {
+	Endpoint string        `mapstructure:"endpoint" default:"localhost:4317"`
+	Insecure bool          `mapstructure:"insecure"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"10s"`
+}
+
+// NewExporter builds the configured span exporter. An empty exporter disables tracing.
+func (c TracingConf) NewExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch c.Exporter {
+	case "":
+		return nil, nil
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		options := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(c.OTLP.Endpoint),
+			otlptracegrpc.WithTimeout(c.OTLP.Timeout),
+		}
+		if c.OTLP.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+
+		return otlptracegrpc.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", c.Exporter)
+	}
+}
+
+// Validate checks the whole config before it is used at startup or applied on reload and reports
+// every problem found, not only the first one.
+func (c Config) Validate() error {
+	var v configErrors
+
+	if err := c.Logger.LoggerConfig().Validate(); err != nil {
+		v = append(v, fmt.Errorf("logger: %w", err))
 	}
 
-	return conf, nil
+	v.check(validPort(c.GRPC.Port), "grpc.port: invalid port %q", c.GRPC.Port)
+	v.check(c.HTTP.Port == "" || validPort(c.HTTP.Port), "http.port: invalid port %q", c.HTTP.Port)
+	v.check(c.Admin.Port == "" || validPort(c.Admin.Port), "admin.port: invalid port %q", c.Admin.Port)
+	v.check(c.Health.Interval > 0, "health.interval must be positive: %s", c.Health.Interval)
+	v.check(c.Health.Timeout > 0, "health.timeout must be positive: %s", c.Health.Timeout)
+	v.check(c.Shutdown.Timeout > 0, "shutdown.timeout must be positive: %s", c.Shutdown.Timeout)
+	v.check(c.Storage.Snapshot.Interval >= 0, "storage.snapshot.interval must not be negative: %s", c.Storage.Snapshot.Interval)
+	v.check(c.Storage.Snapshot.Interval == 0 || c.Storage.Snapshot.Path != "",
+		"storage.snapshot.path is required by storage.snapshot.interval")
+	v.check(c.Purger.Interval >= 0, "purger.interval must not be negative: %s", c.Purger.Interval)
+	v.check(c.Purger.Interval == 0 || c.Purger.Retention > 0, "purger.retention must be positive: %s", c.Purger.Retention)
+	v.checkAttributes("attributes", c.Attributes.MaxAttributes, c.Attributes.Schema)
+	for tenantID, tenant := range c.Attributes.Tenants {
+		v.check(grpcserver.ValidTenantID(tenantID), "attributes.tenants.%s: invalid tenant ID", tenantID)
+		v.checkAttributes("attributes.tenants."+tenantID, tenant.MaxAttributes, tenant.Schema)
+	}
+
+	v.check(c.Auth.MaxFailedLogins >= 0, "auth.max_failed_logins must not be negative: %d", c.Auth.MaxFailedLogins)
+	v.check(c.Batch.HashWorkers >= 0, "batch.hash_workers must not be negative: %d", c.Batch.HashWorkers)
+	v.check(c.Events.Capacity > 0, "events.capacity must be positive: %d", c.Events.Capacity)
+	v.check(c.Invitations.TTL > 0, "invitations.ttl must be positive: %s", c.Invitations.TTL)
+
+	if c.Webhooks.Enabled {
+		v.check(c.Webhooks.Workers > 0, "webhooks.workers must be positive: %d", c.Webhooks.Workers)
+		v.check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive: %d", c.Webhooks.MaxAttempts)
+		v.check(c.Webhooks.InitialBackoff > 0 && c.Webhooks.MaxBackoff >= c.Webhooks.InitialBackoff,
+			"webhooks: backoff must satisfy 0 < initial_backoff <= max_backoff: %s, %s", c.Webhooks.InitialBackoff, c.Webhooks.MaxBackoff)
+		v.check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval must be positive: %s", c.Webhooks.PollInterval)
+		v.check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive: %s", c.Webhooks.Timeout)
+	}
+
+	switch c.Outbox.Publisher {
+	case "":
+	case "file", "nats":
+		v.check(c.Outbox.Publisher != "file" || c.Outbox.File.Path != "", "outbox.file.path is required by the file publisher")
+		v.check(c.Outbox.BatchSize > 0, "outbox.batch_size must be positive: %d", c.Outbox.BatchSize)
+		v.check(c.Outbox.Interval > 0, "outbox.interval must be positive: %s", c.Outbox.Interval)
+	default:
+		v.check(false, "outbox.publisher: unknown publisher %q", c.Outbox.Publisher)
+	}
+
+	switch c.Tracing.Exporter {
+	case "", "stdout", "otlp":
+	default:
+		v.check(false, "tracing.exporter: unknown exporter %q", c.Tracing.Exporter)
+	}
+
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be within [0, 1]: %v", c.Tracing.SampleRatio)
+	v.check(c.RateLimit.Rate >= 0 && c.RateLimit.Burst >= 0, "rate_limit: rate and burst must not be negative")
+
+	for method, limit := range c.RateLimit.Methods {
+		v.check(serviceMethod(method), "rate_limit.methods.%s: unknown method", method)
+		v.check(limit.Rate >= 0 && limit.Burst >= 0, "rate_limit.methods.%s: rate and burst must not be negative", method)
+	}
+
+	for _, method := range c.Access.AdminOnly {
+		v.check(serviceMethod(method), "access.admin_only: unknown method %q", method)
+	}
+
+	v.check(c.Password.MinLength >= 0, "password.min_length must not be negative: %d", c.Password.MinLength)
+	v.check(c.Auth.AdminName != "", "auth.admin_name is required")
+	v.check(c.Auth.AdminPassword != "", "auth.admin_password is required")
+	v.check(c.Auth.Pepper != "", "auth.pepper is required")
+
+	if _, err := c.TLS.TLSConfig(); err != nil {
+		v = append(v, fmt.Errorf("tls: %w", err))
+	}
+
+	return errors.Join(v...)
+}
+
+// configErrors collects the problems found by Config.Validate.
+type configErrors []error
+
+func (v *configErrors) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		*v = append(*v, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *configErrors) checkAttributes(prefix string, maxAttributes int, schema map[string]AttributeConf) {
+	v.check(maxAttributes >= 0, "%s.max_attributes must not be negative: %d", prefix, maxAttributes)
+
+	for key, attribute := range schema {
+		switch app.AttributeType(attribute.Type) {
+		case "", app.AttributeString, app.AttributeInt, app.AttributeBool:
+		default:
+			v.check(false, "%s.schema.%s.type: unknown type %q", prefix, key, attribute.Type)
+		}
+	}
+}
+
+// serviceMethod reports whether UserService has the method, viper lower-cases the keys of maps.
+func serviceMethod(name string) bool {
+	for _, method := range pb.UserService_ServiceDesc.Methods {
+		if strings.EqualFold(method.MethodName, name) {
+			return true
+		}
+	}
+
+	for _, stream := range pb.UserService_ServiceDesc.Streams {
+		if strings.EqualFold(stream.StreamName, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validPort(port string) bool {
+	number, err := strconv.Atoi(port)
+	return err == nil && number > 0 && number <= 65535
 }
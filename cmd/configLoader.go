@@ -0,0 +1,103 @@
+package main
+
+//nolint:depguard
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix prefixes the environment variables overriding the config file, the key path is upper-cased
+// and joined with underscores: XLABS_LOGGER_LEVEL overrides logger.level.
+const EnvPrefix = "XLABS"
+
+// NewConfig reads the config file, every call starts from a clean viper instance so that a reload
+// never sees keys removed from the file. Values come, by priority, from XLABS_* environment variables,
+// the file and the `default` struct tags; secrets may be read from the files named by their *_file keys.
+func NewConfig(path string) (Config, error) {
+	var conf Config
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	bindKeys(v, reflect.TypeOf(conf), "")
+
+	if err := v.ReadInConfig(); err != nil {
+		return conf, fmt.Errorf("error while reading config file: %w", err)
+	}
+
+	if err := v.Unmarshal(&conf); err != nil {
+		return conf, fmt.Errorf("error while unmarshaling config: %w", err)
+	}
+
+	if err := conf.readSecrets(); err != nil {
+		return conf, err
+	}
+
+	return conf, nil
+}
+
+// bindKeys registers every key of t with v: keys with a `default` tag get it as the default value, the
+// rest are bound to their environment variable, so that both work for keys missing from the file.
+// Fields of list and map elements cannot be addressed by a key, their zero values are left to the
+// consumers of the config.
+func bindKeys(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := field.Tag.Get("mapstructure")
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		key, kind := prefix+name, field.Type.Kind()
+		switch {
+		case kind == reflect.Struct:
+			bindKeys(v, field.Type, key+".")
+		case kind == reflect.Slice || kind == reflect.Map:
+		default:
+			if value, ok := field.Tag.Lookup("default"); ok {
+				v.SetDefault(key, value)
+			} else {
+				_ = v.BindEnv(key)
+			}
+		}
+	}
+}
+
+// readSecrets replaces every secret that has its *_file key set with the content of the file, the file
+// takes precedence over the value and its default.
+func (c *Config) readSecrets() error {
+	var errs []error
+	for _, secret := range []struct {
+		key   string
+		value *string
+		file  string
+	}{
+		{"auth.admin_password", &c.Auth.AdminPassword, c.Auth.AdminPasswordFile},
+		{"auth.pepper", &c.Auth.Pepper, c.Auth.PepperFile},
+		{"auth.token_key", &c.Auth.TokenKey, c.Auth.TokenKeyFile},
+		{"tls.cert", &c.TLS.Cert, c.TLS.CertFile},
+		{"tls.key", &c.TLS.Key, c.TLS.KeyFile},
+	} {
+		if secret.file == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(secret.file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s_file: %w", secret.key, err))
+			continue
+		}
+
+		// a trailing newline left by editors and `echo` is not a part of a password
+		*secret.value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestNewConfigDefaults(t *testing.T) {
+	config, err := NewConfig(writeConfig(t, "logger:\n  level: WARN\n"))
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	require.Equal(t, "WARN", config.Logger.Level)
+	require.Equal(t, "console", config.Logger.Format)
+	require.Equal(t, "50051", config.GRPC.Port)
+	require.Equal(t, 5*time.Second, config.Health.Interval)
+	require.Equal(t, 720*time.Hour, config.Purger.Retention)
+	require.Equal(t, 1024, config.Events.Capacity)
+	require.Equal(t, 72*time.Hour, config.Invitations.TTL)
+	require.Equal(t, "users", config.Outbox.NATS.Subject)
+	require.Equal(t, 1.0, config.Tracing.SampleRatio)
+	require.Equal(t, "admin", config.Auth.AdminName)
+	require.Equal(t, 5, config.Auth.MaxFailedLogins)
+}
+
+func TestNewConfigEnvironment(t *testing.T) {
+	secrets := t.TempDir()
+	passwordFile := filepath.Join(secrets, "admin_password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+
+	t.Setenv("XLABS_LOGGER_LEVEL", "ERROR")
+	t.Setenv("XLABS_GRPC_PORT", "6000")
+	t.Setenv("XLABS_WEBHOOKS_ENABLED", "true")
+	t.Setenv("XLABS_OUTBOX_NATS_TIMEOUT", "3s")
+	t.Setenv("XLABS_AUTH_ADMIN_PASSWORD_FILE", passwordFile)
+	t.Setenv("XLABS_AUTH_PEPPER", "pepper")
+
+	config, err := NewConfig(writeConfig(t, "logger:\n  level: WARN\ngrpc:\n  port: 50051\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, "ERROR", config.Logger.Level)
+	require.Equal(t, "6000", config.GRPC.Port)
+	require.True(t, config.Webhooks.Enabled)
+	require.Equal(t, 3*time.Second, config.Outbox.NATS.Timeout)
+	require.Equal(t, "s3cret", config.Auth.AdminPassword)
+	require.Equal(t, "pepper", config.Auth.Pepper)
+}
+
+func TestNewConfigMissingSecretFiles(t *testing.T) {
+	t.Setenv("XLABS_AUTH_PEPPER_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("XLABS_TLS_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	_, err := NewConfig(writeConfig(t, "logger:\n  level: INFO\n"))
+	require.ErrorContains(t, err, "auth.pepper_file")
+	require.ErrorContains(t, err, "tls.key_file")
+}
+
+func TestRepositoryConfig(t *testing.T) {
+	config, err := NewConfig("../configs/config.yaml")
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	config, err := NewConfig(writeConfig(t, `
+logger:
+  level: LOUD
+grpc:
+  port: grpc
+auth:
+  max_failed_logins: -1
+attributes:
+  schema:
+    age:
+      type: float
+  tenants:
+    acme:
+      max_attributes: -1
+      schema:
+        region:
+          type: list
+    _acme:
+      max_attributes: 1
+outbox:
+  publisher: file
+tracing:
+  sample_ratio: 2
+rate_limit:
+  methods:
+    NoSuchMethod:
+      rate: 1
+access:
+  admin_only: [GetUsers, NoSuchMethod]
+tls:
+  cert: not a certificate
+`))
+	require.NoError(t, err)
+
+	err = config.Validate()
+	for _, expected := range []string{
+		"logger: unsupported level of logger: LOUD",
+		`grpc.port: invalid port "grpc"`,
+		"auth.max_failed_logins must not be negative: -1",
+		`attributes.schema.age.type: unknown type "float"`,
+		"attributes.tenants.acme.max_attributes must not be negative: -1",
+		`attributes.tenants.acme.schema.region.type: unknown type "list"`,
+		"attributes.tenants._acme: invalid tenant ID",
+		"outbox.file.path is required",
+		"tracing.sample_ratio must be within [0, 1]",
+		"tls: invalid certificate or key",
+		"rate_limit.methods.nosuchmethod: unknown method",
+		`access.admin_only: unknown method "NoSuchMethod"`,
+	} {
+		require.ErrorContains(t, err, expected)
+	}
+	require.NotContains(t, err.Error(), `"GetUsers"`)
+}
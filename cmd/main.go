@@ -6,30 +6,36 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"net"
+	"net/http"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/grpcserver"
 	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/api/http/gateway"
 	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/health"
+	"github.com/Baraulia/X-Labs_Test/internal/metrics"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/internal/outbox"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
 	memorystorage "github.com/Baraulia/X-Labs_Test/internal/storage/inMemory"
+	"github.com/Baraulia/X-Labs_Test/internal/storage/instrumented"
+	"github.com/Baraulia/X-Labs_Test/internal/tracing"
+	"github.com/Baraulia/X-Labs_Test/internal/webhook"
 	"github.com/Baraulia/X-Labs_Test/pkg/logger"
 	"github.com/Baraulia/X-Labs_Test/pkg/validation"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
-var (
-	initAdminName     string
-	initAdminPassword string
-	secretKey         string
-	configPath        string
-)
+var configPath string
 
 func init() {
-	flag.StringVar(&initAdminName, "admin_name", "admin", "Name for initialization the first admin in database")
-	flag.StringVar(&initAdminPassword, "admin_password", "admin", "Password for initialization the first admin in database")
-	flag.StringVar(&secretKey, "secret_key", "secret", "Secret key for password hashing")
 	flag.StringVar(&configPath, "config", "./configs/config.yaml", "Path to config file")
 }
 
@@ -41,45 +47,239 @@ func main() {
 		log.Fatal(err)
 	}
 
-	logg, err := logger.GetLogger(config.Logger.Level)
+	if err = config.Validate(); err != nil {
+		log.Fatalf("invalid config:\n%v", err)
+	}
+
+	tlsConfig, err := config.TLS.TLSConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logg, err := logger.New(config.Logger.LoggerConfig())
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// components are stopped in the reverse order: servers first, then workers, the storage snapshot,
+	// traces and logs last
+	lifecycle := app.NewLifecycle(logg, config.Shutdown.Timeout)
+	lifecycle.Add(app.Component{
+		Name: "logger",
+		Stop: func(context.Context) error {
+			_ = logg.Sync()
+			return nil
+		},
+	})
+
+	// SIGHUP reloads the config, see Reloader.Watch
 	ctx, cancel := signal.NotifyContext(context.Background(),
-		syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	exporter, err := config.Tracing.NewExporter(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if exporter != nil {
+		provider := tracing.Setup(exporter, config.Tracing.ServiceName, config.Tracing.SampleRatio)
+		lifecycle.Add(app.Component{Name: "tracing", Stop: provider.Shutdown})
+	}
+
+	checker := health.NewChecker(logg, config.Health.Interval, config.Health.Timeout, pb.UserService_ServiceDesc.ServiceName)
+	lifecycle.SetStateReporter(checker)
+
 	storage := memorystorage.NewUserStorage(logg)
+	storage.SetEventsCapacity(config.Events.Capacity)
+	checker.AddProbe("storage", storage.Ping)
+
+	var webhookStorage *memorystorage.WebhookStorage
+	if config.Webhooks.Enabled {
+		webhookStorage = memorystorage.NewWebhookStorage(logg)
+		storage.SetWebhookStorage(webhookStorage)
+	}
+
+	if config.Storage.Snapshot.Path != "" {
+		if _, err = storage.LoadSnapshot(config.Storage.Snapshot.Path); err != nil {
+			log.Fatal(err)
+		}
+
+		lifecycle.Add(snapshotComponent(logg, storage, config.Storage.Snapshot.Path, config.Storage.Snapshot.Interval))
+	}
+
+	publisher, err := config.Outbox.NewPublisher()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if err = storage.InitAdmin(initAdminName, initAdminPassword, secretKey); err != nil {
-		logg.Fatal(err.Error(), map[string]interface{}{"initAdminName": initAdminName, "initAdminPassword": initAdminPassword, "secretKey": secretKey})
+	if publisher != nil {
+		storage.SetOutboxEnabled(true)
+		lifecycle.Add(workerComponent("worker.outbox", outbox.NewRelay(logg, storage, publisher, config.Outbox.BatchSize, config.Outbox.Interval).Run))
+	}
+
+	if err = storage.InitAdmin(config.Auth.AdminName, config.Auth.AdminPassword, config.Auth.Pepper); err != nil {
+		logg.Fatal(err.Error(), map[string]interface{}{"initAdminName": config.Auth.AdminName})
 	}
 
 	validator := validation.New()
 
-	service := app.NewApp(logg, storage, validator, secretKey)
+	collector := metrics.New()
+	collector.RegisterUserCount(func(ctx context.Context) (int, error) {
+		_, total, err := storage.GetUsers(ctx, models.AllTenants, models.UsersFilter{})
+		return total, err
+	})
+
+	instrumentedStorage := instrumented.New(storage, tracing.StorageObserver("memory"), collector.StorageObserver("memory"))
+
+	service := app.NewApp(logg, instrumentedStorage, validator, config.Auth.Pepper)
+	service.SetMetrics(collector)
+	service.SetAttributeSchema(config.Attributes.AttributeSchema())
+	service.SetTenantAttributeSchemas(config.Attributes.TenantAttributeSchemas())
+	service.SetHashWorkers(config.Batch.HashWorkers)
+	service.SetMaxFailedLogins(config.Auth.MaxFailedLogins)
+	service.SetInvitationTTL(config.Invitations.TTL)
+	service.SetPasswordPolicy(config.Password.PasswordPolicy())
+
+	if config.Purger.Interval > 0 {
+		lifecycle.Add(workerComponent("worker.purger", func(ctx context.Context) {
+			service.RunPurger(ctx, config.Purger.Retention, config.Purger.Interval)
+		}))
+	}
+
+	if webhookStorage != nil {
+		service.SetWebhookStorage(webhookStorage)
+
+		dispatcher := webhook.NewDispatcher(logg, webhookStorage, service, config.Webhooks.DispatcherConfig())
+		lifecycle.Add(workerComponent("worker.webhooks", dispatcher.Run))
+	}
+
 	grpcService := grpcserver.NewServer(service, logg)
+	grpcService.SetMetrics(collector)
+	grpcService.SetLogLevels(logg)
+	if config.Auth.TokenKey != "" {
+		grpcService.SetTokenKey([]byte(config.Auth.TokenKey))
+	}
 
-	server := grpc.NewServer(grpc.UnaryInterceptor(
+	requestLogger := grpcserver.NewRequestLogger(logg)
+	rateLimiter := grpcserver.NewRateLimiter(logg, ratelimit.NewMemoryBackend(), config.RateLimit.Limits())
+	accessPolicy := grpcserver.NewAccessPolicy(config.Access.AdminOnly)
+
+	recovery := grpcserver.NewRecovery(logg)
+
+	// the first interceptor is the outermost: a panic is recovered inside the metrics and the request
+	// logger, a peer over its rate limit is rejected before a password is checked and a principal once it
+	// is authenticated, requests are validated only once the client is authenticated
+	unary := grpcserver.ChainUnaryInterceptors(
+		tracing.UnaryInterceptor,
+		collector.UnaryInterceptor,
+		requestLogger.UnaryInterceptor,
+		recovery.UnaryInterceptor,
+		rateLimiter.UnaryInterceptor,
 		grpcService.BasicAuthInterceptor,
-	))
+		rateLimiter.PrincipalUnaryInterceptor,
+		accessPolicy.UnaryInterceptor,
+		grpcserver.ValidationUnaryInterceptor,
+	)
+	stream := grpcserver.ChainStreamInterceptors(
+		tracing.StreamInterceptor,
+		collector.StreamInterceptor,
+		requestLogger.StreamInterceptor,
+		recovery.StreamInterceptor,
+		rateLimiter.StreamInterceptor,
+		grpcService.BasicAuthStreamInterceptor,
+		rateLimiter.PrincipalStreamInterceptor,
+		accessPolicy.StreamInterceptor,
+		grpcserver.ValidationStreamInterceptor,
+	)
+
+	serverOptions := []grpc.ServerOption{
+		grpc.UnaryInterceptor(unary),
+		grpc.StreamInterceptor(stream),
+	}
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(serverOptions...)
 
 	pb.RegisterUserServiceServer(server, grpcService)
+	healthpb.RegisterHealthServer(server, checker.Server())
 
-	go func() {
-		<-ctx.Done()
-		logg.Info("stopping grpc server...", nil)
-		server.GracefulStop()
-	}()
+	if config.GRPC.Reflection {
+		reflection.Register(server)
+	}
 
-	lsn, err := net.Listen("tcp", fmt.Sprintf(":%s", config.GRPC.Port))
-	if err != nil {
-		logg.Fatal(err.Error(), nil)
+	go checker.Run(ctx)
+
+	reloader := NewReloader(configPath, config, logg)
+	reloader.Reloadable("logger.level", config,
+		func(c Config) interface{} { return c.Logger.Level },
+		func(c Config) error {
+			_, err := logg.SetLevel(c.Logger.Level, 0)
+			return err
+		},
+	)
+	reloader.Reloadable("password", config,
+		func(c Config) interface{} { return c.Password },
+		func(c Config) error {
+			service.SetPasswordPolicy(c.Password.PasswordPolicy())
+			return nil
+		},
+	)
+	reloader.Reloadable("rate_limit", config,
+		func(c Config) interface{} { return c.RateLimit },
+		func(c Config) error {
+			rateLimiter.SetLimits(c.RateLimit.Limits())
+			return nil
+		},
+	)
+	reloader.Reloadable("access", config,
+		func(c Config) interface{} { return c.Access },
+		func(c Config) error {
+			accessPolicy.SetAdminOnly(c.Access.AdminOnly)
+			return nil
+		},
+	)
+	reloader.Watch(ctx)
+
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%s", config.HTTP.Port),
+		Handler:           gateway.NewGateway(logg, grpcService, unary, stream),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	logg.Info("starting server on "+lsn.Addr().String(), nil)
-	if err := server.Serve(lsn); err != nil {
-		logg.Fatal("failed to start grpc server", map[string]interface{}{"error": err})
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", collector.Handler())
+	adminMux.Handle("/loglevel", rateLimited(logg, rateLimiter, adminOnly(logg, service, logg.LevelHandler())))
+
+	adminServer := &http.Server{
+		Addr:              fmt.Sprintf(":%s", config.Admin.Port),
+		Handler:           adminMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if config.Admin.Port != "" {
+		lifecycle.Add(httpComponent(logg, "server.admin", adminServer))
+	}
+
+	lifecycle.Add(grpcComponent(logg, server, fmt.Sprintf(":%s", config.GRPC.Port)))
+
+	if config.HTTP.Port != "" {
+		lifecycle.Add(httpComponent(logg, "server.http", httpServer))
+	}
+
+	// stopped first, so load balancers stop routing new calls while the servers drain the running ones
+	lifecycle.Add(app.Component{
+		Name: "health",
+		Stop: func(context.Context) error {
+			checker.Shutdown()
+			return nil
+		},
+	})
+
+	if err = lifecycle.Run(ctx); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,159 @@
+package main
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// setting is a part of Config compared on every reload. A setting without apply is only read at
+// startup, a change of it is logged as requiring a restart.
+type setting struct {
+	name    string
+	value   func(Config) interface{}
+	apply   func(Config) error
+	current interface{}
+}
+
+// Reloader re-reads the config file on change or on SIGHUP, validates it and applies the settings
+// that can change at runtime. An invalid file is rejected as a whole and nothing is applied.
+type Reloader struct {
+	mu       sync.Mutex
+	path     string
+	logger   app.Logger
+	settings []*setting
+}
+
+// NewReloader starts from the config the process was started with, every setting of it needs a
+// restart until it is registered with Reloadable.
+func NewReloader(path string, config Config, logger app.Logger) *Reloader {
+	r := &Reloader{path: path, logger: logger}
+	for name, value := range restartSettings() {
+		r.settings = append(r.settings, &setting{name: name, value: value, current: value(config)})
+	}
+
+	return r
+}
+
+// restartSettings lists every section of Config. Logger level, password policy, rate limits and access
+// policy are registered as reloadable in main, so they are left out here.
+func restartSettings() map[string]func(Config) interface{} {
+	return map[string]func(Config) interface{}{
+		"logger": func(c Config) interface{} {
+			c.Logger.Level = ""
+			return c.Logger
+		},
+		"grpc":        func(c Config) interface{} { return c.GRPC },
+		"http":        func(c Config) interface{} { return c.HTTP },
+		"admin":       func(c Config) interface{} { return c.Admin },
+		"health":      func(c Config) interface{} { return c.Health },
+		"purger":      func(c Config) interface{} { return c.Purger },
+		"attributes":  func(c Config) interface{} { return c.Attributes },
+		"batch":       func(c Config) interface{} { return c.Batch },
+		"events":      func(c Config) interface{} { return c.Events },
+		"webhooks":    func(c Config) interface{} { return c.Webhooks },
+		"invitations": func(c Config) interface{} { return c.Invitations },
+		"outbox":      func(c Config) interface{} { return c.Outbox },
+		"tracing":     func(c Config) interface{} { return c.Tracing },
+		"auth":        func(c Config) interface{} { return c.Auth },
+		"tls":         func(c Config) interface{} { return c.TLS },
+		"shutdown":    func(c Config) interface{} { return c.Shutdown },
+		"storage":     func(c Config) interface{} { return c.Storage },
+	}
+}
+
+// Reloadable registers a setting applied to the running process when value changes.
+func (r *Reloader) Reloadable(name string, config Config, value func(Config) interface{}, apply func(Config) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.settings = append(r.settings, &setting{name: name, value: value, apply: apply, current: value(config)})
+}
+
+// Reload reads and validates the config file and applies the changed reloadable settings. A setting
+// that fails to apply keeps its old value and is retried on the next reload.
+func (r *Reloader) Reload(source string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config, err := NewConfig(r.path)
+	if err == nil {
+		err = config.Validate()
+	}
+
+	if err != nil {
+		r.logger.Error("config was not reloaded", map[string]interface{}{"source": source, "error": err})
+		return err
+	}
+
+	applied := make([]string, 0)
+	restart := make([]string, 0)
+	var applyErr error
+	for _, s := range r.settings {
+		value := s.value(config)
+		if reflect.DeepEqual(value, s.current) {
+			continue
+		}
+
+		if s.apply == nil {
+			restart = append(restart, s.name)
+			continue
+		}
+
+		if err := s.apply(config); err != nil {
+			r.logger.Error("failed to apply setting", map[string]interface{}{"setting": s.name, "error": err})
+			applyErr = fmt.Errorf("failed to apply %s: %w", s.name, err)
+
+			continue
+		}
+
+		s.current = value
+		applied = append(applied, s.name)
+	}
+
+	if len(restart) > 0 {
+		sort.Strings(restart)
+		r.logger.Warn("changed settings require a restart", map[string]interface{}{"source": source, "settings": restart})
+	}
+
+	r.logger.Info("config was reloaded", map[string]interface{}{"source": source, "applied": applied})
+
+	return applyErr
+}
+
+// Watch starts reloading on every change of the config file and on SIGHUP until ctx is done.
+// SIGHUP is subscribed to before Watch returns, so it never falls back to terminating the process.
+func (r *Reloader) Watch(ctx context.Context) {
+	watcher := viper.New()
+	watcher.SetConfigFile(r.path)
+	watcher.OnConfigChange(func(fsnotify.Event) {
+		_ = r.Reload("file")
+	})
+	watcher.WatchConfig()
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hangup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				_ = r.Reload("SIGHUP")
+			}
+		}
+	}()
+}
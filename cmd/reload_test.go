@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+logger:
+  level: INFO
+grpc:
+  port: 50051
+password:
+  min_length: 8
+`
+
+func TestReload(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0o600))
+
+	config, err := NewConfig(path)
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	var applied []int
+	reloader := NewReloader(path, config, logg)
+	reloader.Reloadable("password", config,
+		func(c Config) interface{} { return c.Password },
+		func(c Config) error {
+			applied = append(applied, c.Password.MinLength)
+			return nil
+		},
+	)
+
+	// unchanged file
+	require.NoError(t, reloader.Reload("test"))
+	require.Empty(t, applied)
+
+	// reloadable setting is applied, a port change only needs a restart
+	changed := `
+logger:
+  level: INFO
+grpc:
+  port: 50052
+password:
+  min_length: 12
+`
+	require.NoError(t, os.WriteFile(path, []byte(changed), 0o600))
+	require.NoError(t, reloader.Reload("test"))
+	require.Equal(t, []int{12}, applied)
+
+	// invalid file is rejected as a whole
+	invalid := `
+logger:
+  level: LOUD
+password:
+  min_length: 16
+`
+	require.NoError(t, os.WriteFile(path, []byte(invalid), 0o600))
+	require.Error(t, reloader.Reload("test"))
+	require.Equal(t, []int{12}, applied)
+
+	require.NoError(t, os.Remove(path))
+	require.Error(t, reloader.Reload("test"))
+}
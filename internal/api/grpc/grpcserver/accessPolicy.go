@@ -0,0 +1,67 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AccessPolicy restricts methods open to anonymous callers and non-admin users, e.g. GetUsers, to admins.
+// Methods are listed by their short name, case-insensitive. It must run after the auth interceptors.
+type AccessPolicy struct {
+	adminOnly atomic.Pointer[map[string]bool]
+}
+
+func NewAccessPolicy(adminOnly []string) *AccessPolicy {
+	p := &AccessPolicy{}
+	p.SetAdminOnly(adminOnly)
+
+	return p
+}
+
+// SetAdminOnly replaces the admin only methods, it is safe to call while requests are served.
+func (p *AccessPolicy) SetAdminOnly(methods []string) {
+	adminOnly := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		adminOnly[strings.ToLower(method)] = true
+	}
+
+	p.adminOnly.Store(&adminOnly)
+}
+
+func (p *AccessPolicy) UnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := p.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (p *AccessPolicy) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := p.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+func (p *AccessPolicy) check(ctx context.Context, fullMethod string) error {
+	name := strings.ToLower(fullMethod[strings.LastIndex(fullMethod, "/")+1:])
+	if !(*p.adminOnly.Load())[name] {
+		return nil
+	}
+
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAccessPolicy(t *testing.T) {
+	policy := NewAccessPolicy([]string{"getusers"})
+	anonymousCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	adminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	call := func(ctx context.Context, method string) error {
+		_, err := policy.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/" + method}, handler)
+		return err
+	}
+
+	require.Equal(t, codes.PermissionDenied, status.Code(call(anonymousCtx, "GetUsers")))
+	require.NoError(t, call(adminCtx, "GetUsers"))
+	require.NoError(t, call(anonymousCtx, "GetOneUserByID"))
+
+	err := policy.StreamInterceptor(nil, &watchStreamMock{ctx: anonymousCtx}, &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+	require.NoError(t, err)
+
+	policy.SetAdminOnly([]string{"GetOneUserByID", "WatchUsers"})
+	require.NoError(t, call(anonymousCtx, "GetUsers"), "the policy is replaced on reload")
+	require.Equal(t, codes.PermissionDenied, status.Code(call(anonymousCtx, "GetOneUserByID")))
+
+	err = policy.StreamInterceptor(nil, &watchStreamMock{ctx: anonymousCtx}, &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"},
+		func(srv interface{}, ss grpc.ServerStream) error { return nil })
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -1,57 +1,225 @@
 package grpcserver
 
+//nolint:depguard
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"strings"
+	"time"
 
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type contextValue string
 
-func (s Server) BasicAuthInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	isAdmin := false
+// adminOnlyServices are registered next to UserService but must not be exposed to anonymous callers.
+var adminOnlyServices = []string{
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
 
-	md, exist := metadata.FromIncomingContext(ctx)
-	switch exist {
-	case true:
-		authHeader, ok := md["authorization"]
-		if !ok || len(authHeader) == 0 {
-			break
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s Server) BasicAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkAdminOnly(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s Server) BasicAuthStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	if err = checkAdminOnly(ctx, info.FullMethod); err != nil {
+		return err
+	}
+
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate accepts Basic credentials or a bearer token, failed Basic credentials leave the request
+// anonymous while a bad token is rejected.
+func (s Server) authenticate(ctx context.Context) (context.Context, error) {
+	headerTenant, err := tenantFromMetadata(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	tenantID := headerTenant
+	if tenantID == "" {
+		tenantID = models.DefaultTenant
+	}
+
+	var (
+		username string
+		isAdmin  bool
+		checkErr error
+		checked  bool
+		reason   = "anonymous"
+	)
+
+	authorization := metadata.ValueFromIncomingContext(ctx, "authorization")
+	switch {
+	case len(authorization) == 0 || authorization[0] == "":
+	case strings.HasPrefix(authorization[0], "Bearer "):
+		claims, tokenErr := parseToken(s.tokenKey, strings.TrimPrefix(authorization[0], "Bearer "), time.Now())
+		if tokenErr != nil {
+			s.observeAuth(false, "invalid_token")
+			return ctx, status.Error(codes.Unauthenticated, tokenErr.Error())
 		}
 
-		header, found := strings.CutPrefix(authHeader[0], "Basic ")
-		if !found {
-			break
+		if headerTenant == "" {
+			tenantID = claims.Tenant
 		}
 
-		credentials, err := base64.StdEncoding.DecodeString(header)
-		if err != nil {
+		username, checked = claims.Subject, true
+		isAdmin, checkErr = s.service.AuthorizeToken(app.WithTenant(ctx, tenantID), claims.Tenant, claims.Subject)
+	default:
+		reason = "malformed_header"
+		user, password, valid := parseBasic(authorization[0])
+		if !valid {
 			break
 		}
 
-		parts := strings.SplitN(string(credentials), ":", 2)
-		if len(parts) != 2 {
-			break
+		username, checked = user, true
+		isAdmin, checkErr = s.service.CheckPassword(app.WithTenant(ctx, tenantID), user, password)
+	}
+
+	ctx = app.WithTenant(ctx, tenantID)
+	authenticated := false
+	if checked {
+		reason = authFailureReason(checkErr)
+		authenticated = checkErr == nil || errors.Is(checkErr, app.ErrNotAdmin)
+		if authenticated {
+			ctx = withPrincipal(ctx, username)
 		}
 
-		username := parts[0]
-		password := parts[1]
+		if statusErr := accountStatusError(checkErr); statusErr != nil {
+			s.observeAuth(false, reason)
+			return ctx, statusErr
+		}
 
-		result, err := s.service.CheckPassword(ctx, username, password)
-		if err != nil {
-			break
-		} else if !result {
-			break
+		if checkErr == nil && isAdmin {
+			reason = "admin"
+		} else {
+			isAdmin = false
 		}
+	}
+
+	s.observeAuth(isAdmin, reason)
+
+	// only a super-admin authenticates as an admin with every tenant selected
+	if tenantID == models.AllTenants && !isAdmin {
+		return ctx, status.Error(codes.PermissionDenied, "only a super-admin has access to every tenant")
+	}
+
+	// the header alone must not open the data of a tenant to an anonymous caller
+	if !authenticated && tenantID != models.DefaultTenant {
+		return ctx, status.Errorf(codes.PermissionDenied, "anonymous requests are served in the %s tenant only", models.DefaultTenant)
+	}
+
+	return context.WithValue(ctx, contextValue("isAdmin"), isAdmin), nil
+}
 
-		isAdmin = true
+// parseBasic returns the credentials of a Basic authorization header, ok is false when it is malformed.
+func parseBasic(header string) (username, password string, ok bool) {
+	encoded, found := strings.CutPrefix(header, "Basic ")
+	if !found {
+		return "", "", false
+	}
+
+	credentials, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+
+	return strings.Cut(string(credentials), ":")
+}
+
+func (s Server) observeAuth(success bool, reason string) {
+	if s.metrics != nil {
+		s.metrics.ObserveAuth(success, reason)
+	}
+}
+
+func authFailureReason(err error) string {
+	switch {
+	case errors.Is(err, app.ErrNotAdmin):
+		return "not_admin"
+	case errors.Is(err, app.ErrUserPending):
+		return "user_pending"
+	case errors.Is(err, app.ErrUserSuspended):
+		return "user_suspended"
+	case errors.Is(err, app.ErrUserLocked):
+		return "user_locked"
+	case errors.Is(err, app.ErrCrossTenant):
+		return "cross_tenant"
 	default:
+		return "invalid_credentials"
 	}
+}
 
-	ctx = context.WithValue(ctx, contextValue("isAdmin"), isAdmin)
+func checkAdminOnly(ctx context.Context, fullMethod string) error {
+	for _, prefix := range adminOnlyServices {
+		if !strings.HasPrefix(fullMethod, prefix) {
+			continue
+		}
 
-	return handler(ctx, req)
+		isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+		if !isAdmin || !ok {
+			return status.Error(codes.PermissionDenied, "only admin has access to call this method")
+		}
+	}
+
+	return nil
+}
+
+func accountStatusError(err error) error {
+	var (
+		code   codes.Code
+		reason string
+	)
+
+	switch {
+	case errors.Is(err, app.ErrUserPending):
+		code, reason = codes.FailedPrecondition, "USER_PENDING"
+	case errors.Is(err, app.ErrUserSuspended):
+		code, reason = codes.PermissionDenied, "USER_SUSPENDED"
+	case errors.Is(err, app.ErrUserLocked):
+		code, reason = codes.PermissionDenied, "USER_LOCKED"
+	case errors.Is(err, app.ErrCrossTenant):
+		code, reason = codes.PermissionDenied, "CROSS_TENANT"
+	default:
+		return nil
+	}
+
+	st, detailsErr := status.New(code, err.Error()).WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: "user"})
+	if detailsErr != nil {
+		return status.Error(code, err.Error())
+	}
+
+	return st.Err()
 }
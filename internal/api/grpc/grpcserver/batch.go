@@ -0,0 +1,82 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s Server) BatchCreateUsers(ctx context.Context, req *pb.BatchCreateUsersRequest) (*pb.BatchUsersResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	users := make([]*models.User, 0, len(req.Users))
+	for _, user := range req.Users {
+		users = append(users, convertUserFromPb(user))
+	}
+
+	results, err := s.service.BatchCreateUsers(ctx, users, req.Mode == pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING)
+
+	return convertBatchResults(results, err)
+}
+
+func (s Server) BatchUpdateUsers(ctx context.Context, req *pb.BatchUpdateUsersRequest) (*pb.BatchUsersResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	updates := make([]models.UserUpdate, 0, len(req.Users))
+	for _, user := range req.Users {
+		updates = append(updates, models.UserUpdate{ID: user.Id, DTO: convertUpdateFromPb(user)})
+	}
+
+	results, err := s.service.BatchUpdateUsers(ctx, updates, req.Mode == pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING)
+
+	return convertBatchResults(results, err)
+}
+
+func (s Server) BatchDeleteUsers(ctx context.Context, req *pb.BatchDeleteUsersRequest) (*pb.BatchUsersResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	results, err := s.service.BatchDeleteUsers(ctx, req.Ids, req.Mode == pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING)
+
+	return convertBatchResults(results, err)
+}
+
+func convertBatchResults(results []models.BatchResult, err error) (*pb.BatchUsersResponse, error) {
+	if err != nil && !errors.Is(err, app.ErrBatchAborted) {
+		return nil, err
+	}
+
+	response := &pb.BatchUsersResponse{
+		Results:   make([]*pb.BatchItemResult, 0, len(results)),
+		Committed: err == nil,
+	}
+
+	for i, result := range results {
+		item := &pb.BatchItemResult{Index: uint32(i), Success: result.Err == nil}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+		}
+
+		if result.User != nil {
+			item.User = convertWithoutPassword(*result.User)
+		}
+
+		response.Results = append(response.Results, item)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,51 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestChainUnaryInterceptors(t *testing.T) {
+	var calls []string
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name+":before")
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+":after")
+
+			return resp, err
+		}
+	}
+
+	chain := ChainUnaryInterceptors(record("outer"), record("inner"))
+	resp, err := chain(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, "req", resp)
+	require.Equal(t, []string{"outer:before", "inner:before", "handler", "inner:after", "outer:after"}, calls)
+}
+
+func TestChainStreamInterceptors(t *testing.T) {
+	var calls []string
+	record := func(name string) grpc.StreamServerInterceptor {
+		return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			calls = append(calls, name)
+			return handler(srv, ss)
+		}
+	}
+
+	chain := ChainStreamInterceptors(record("outer"), record("inner"))
+	err := chain(nil, nil, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"outer", "inner", "handler"}, calls)
+}
@@ -0,0 +1,180 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s Server) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.GroupResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	group, err := s.service.CreateGroup(ctx, &models.Group{Name: req.Name, Description: req.Description})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GroupResponse{Group: convertGroup(*group)}, nil
+}
+
+func (s Server) UpdateGroup(ctx context.Context, req *pb.UpdateGroupRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	var groupDTO models.UpdateGroupDTO
+	if req.Name != "" {
+		groupDTO.Name = &req.Name
+	}
+
+	if req.Description != "" {
+		groupDTO.Description = &req.Description
+	}
+
+	if err := s.service.UpdateGroup(ctx, groupDTO, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) DeleteGroup(ctx context.Context, req *pb.DeleteGroupRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if err := s.service.DeleteGroup(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	member, err := convertMemberFromPb(req.UserId, req.MemberGroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.service.AddGroupMember(ctx, req.GroupId, member); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	member, err := convertMemberFromPb(req.UserId, req.MemberGroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.service.RemoveGroupMember(ctx, req.GroupId, member); err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) GetGroup(ctx context.Context, req *pb.GetGroupRequest) (*pb.GroupResponse, error) {
+	group, err := s.service.GetGroup(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GroupResponse{Group: convertGroup(*group)}, nil
+}
+
+func (s Server) GetGroups(ctx context.Context, req *pb.GetGroupsRequest) (*pb.GetGroupsResponse, error) {
+	groups, count, err := s.service.GetGroups(ctx, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetGroupsResponse{Groups: convertGroups(groups), TotalGroups: int32(count)}, nil
+}
+
+func (s Server) ListMembers(ctx context.Context, req *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	members, err := s.service.GetGroupMembers(ctx, req.GroupId, req.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	pbUsers := make([]*pb.User, 0, len(members.Users))
+	for _, user := range members.Users {
+		pbUsers = append(pbUsers, convert(user))
+	}
+
+	return &pb.ListMembersResponse{Users: pbUsers, Groups: convertGroups(members.Groups)}, nil
+}
+
+func (s Server) ListUserGroups(ctx context.Context, req *pb.ListUserGroupsRequest) (*pb.GetGroupsResponse, error) {
+	groups, err := s.service.GetUserGroups(ctx, req.UserId, req.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetGroupsResponse{Groups: convertGroups(groups), TotalGroups: int32(len(groups))}, nil
+}
+
+func groupError(err error) error {
+	if errors.Is(err, app.ErrGroupCycle) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return err
+}
+
+func convertMemberFromPb(userID, groupID string) (models.GroupMember, error) {
+	switch {
+	case userID != "" && groupID == "":
+		return models.GroupMember{Type: models.MemberUser, ID: userID}, nil
+	case groupID != "" && userID == "":
+		return models.GroupMember{Type: models.MemberGroup, ID: groupID}, nil
+	default:
+		return models.GroupMember{}, status.Error(codes.InvalidArgument, "exactly one of user_id and member_group_id must be set")
+	}
+}
+
+func convertGroup(group models.Group) *pb.Group {
+	return &pb.Group{
+		Id:          group.ID,
+		TenantId:    group.TenantID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   timestamppb.New(group.CreatedAt),
+		UpdatedAt:   timestamppb.New(group.UpdatedAt),
+	}
+}
+
+func convertGroups(groups []models.Group) []*pb.Group {
+	pbGroups := make([]*pb.Group, 0, len(groups))
+	for _, group := range groups {
+		pbGroups = append(pbGroups, convertGroup(group))
+	}
+
+	return pbGroups
+}
@@ -0,0 +1,96 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s Server) InviteUser(ctx context.Context, req *pb.InviteUserRequest) (*pb.InviteUserResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	invitation, token, err := s.service.InviteUser(ctx, &models.User{Email: req.Email, Admin: req.Admin})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.InviteUserResponse{Invitation: convertInvitation(*invitation), Token: token}, nil
+}
+
+func (s Server) GetInvitations(ctx context.Context, req *pb.GetInvitationsRequest) (*pb.GetInvitationsResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	invitations, count, err := s.service.GetInvitations(ctx, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	pbInvitations := make([]*pb.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		pbInvitations = append(pbInvitations, convertInvitation(invitation))
+	}
+
+	return &pb.GetInvitationsResponse{
+		Invitations:      pbInvitations,
+		TotalInvitations: int32(count),
+	}, nil
+}
+
+func (s Server) RevokeInvitation(ctx context.Context, req *pb.RevokeInvitationRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if err := s.service.RevokeInvitation(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+// AcceptInvitation is called by the invitee without credentials, the token authorizes it.
+func (s Server) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.UserResponse, error) {
+	user, err := s.service.AcceptInvitation(ctx, req.Token, req.Username, req.Password)
+	if err != nil {
+		return nil, invitationError(err)
+	}
+
+	return &pb.UserResponse{User: convertWithoutPassword(*user)}, nil
+}
+
+func invitationError(err error) error {
+	switch {
+	case errors.Is(err, app.ErrInvitationInvalid):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, app.ErrInvitationExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return err
+	}
+}
+
+func convertInvitation(invitation models.Invitation) *pb.Invitation {
+	return &pb.Invitation{
+		Id:        invitation.ID,
+		TenantId:  invitation.TenantID,
+		UserId:    invitation.UserID,
+		Email:     invitation.Email,
+		CreatedAt: timestamppb.New(invitation.CreatedAt),
+		ExpiresAt: timestamppb.New(invitation.ExpiresAt),
+	}
+}
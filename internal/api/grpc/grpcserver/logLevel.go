@@ -0,0 +1,75 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// LogLevels changes the level of the process logger at runtime, it is implemented by *logger.ZapLogger.
+type LogLevels interface {
+	SetLevel(level string, ttl time.Duration) (logger.LevelState, error)
+	LevelState() logger.LevelState
+}
+
+func (s *Server) SetLogLevels(levels LogLevels) {
+	s.levels = levels
+}
+
+func (s Server) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.LogLevel, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if s.levels == nil {
+		return nil, status.Error(codes.Unimplemented, "log level is not configurable")
+	}
+
+	var ttl time.Duration
+	if req.Ttl != nil {
+		if err := req.Ttl.CheckValid(); err != nil || req.Ttl.AsDuration() < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid ttl: %v", req.Ttl)
+		}
+
+		ttl = req.Ttl.AsDuration()
+	}
+
+	state, err := s.levels.SetLevel(req.Level, ttl)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.logger.Warn("log level was changed", map[string]interface{}{"level": state.Level, "ttl": ttl.String()})
+
+	return convertLogLevel(state), nil
+}
+
+func (s Server) GetLogLevel(ctx context.Context, _ *empty.Empty) (*pb.LogLevel, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if s.levels == nil {
+		return nil, status.Error(codes.Unimplemented, "log level is not configurable")
+	}
+
+	return convertLogLevel(s.levels.LevelState()), nil
+}
+
+func convertLogLevel(state logger.LevelState) *pb.LogLevel {
+	level := &pb.LogLevel{Level: state.Level, DefaultLevel: state.DefaultLevel}
+	if !state.ExpiresAt.IsZero() {
+		level.OverrideExpiresAt = timestamppb.New(state.ExpiresAt)
+	}
+
+	return level
+}
@@ -0,0 +1,199 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// APIKeyHeader identifies a client to the rate limiter when the request is anonymous.
+	APIKeyHeader = "x-api-key"
+	// RetryAfterHeader carries the whole number of seconds to wait after a rejected request.
+	RetryAfterHeader = "retry-after"
+)
+
+// RateLimiter rejects requests of a client over its limit with codes.ResourceExhausted. It limits in two
+// steps: UnaryInterceptor runs before the auth interceptors and limits every request by the peer IP, so
+// a password is never checked over the limit whatever username is tried, PrincipalUnaryInterceptor runs
+// after them and limits an authenticated principal, else the API key. Requests are let through when the
+// backend fails.
+type RateLimiter struct {
+	logger  app.Logger
+	backend ratelimit.Backend
+	limits  atomic.Pointer[ratelimit.Limits]
+}
+
+func NewRateLimiter(logger app.Logger, backend ratelimit.Backend, limits ratelimit.Limits) *RateLimiter {
+	rl := &RateLimiter{logger: logger, backend: backend}
+	rl.limits.Store(&limits)
+
+	return rl
+}
+
+// SetLimits replaces the limits, it is safe to call while requests are served.
+func (rl *RateLimiter) SetLimits(limits ratelimit.Limits) {
+	rl.limits.Store(&limits)
+}
+
+func (rl *RateLimiter) UnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	return rl.limitUnary(ctx, req, info, handler, peerKey(ctx))
+}
+
+func (rl *RateLimiter) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return rl.limitStream(srv, ss, info, handler, peerKey(ss.Context()))
+}
+
+// PrincipalUnaryInterceptor must run after the auth interceptors, an anonymous request without an API key
+// is limited by the peer only.
+func (rl *RateLimiter) PrincipalUnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	client, ok := principalKey(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	return rl.limitUnary(ctx, req, info, handler, client)
+}
+
+func (rl *RateLimiter) PrincipalStreamInterceptor(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	client, ok := principalKey(ss.Context())
+	if !ok {
+		return handler(srv, ss)
+	}
+
+	return rl.limitStream(srv, ss, info, handler, client)
+}
+
+func (rl *RateLimiter) limitUnary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler, client string,
+) (interface{}, error) {
+	retryAfter, limited := rl.take(ctx, info.FullMethod, client)
+	if limited {
+		if err := grpc.SetHeader(ctx, retryAfterHeader(retryAfter)); err != nil {
+			rl.logger.Debug("error while setting retry-after header", map[string]interface{}{"error": err})
+		}
+
+		return nil, rateLimitError(retryAfter)
+	}
+
+	return handler(ctx, req)
+}
+
+func (rl *RateLimiter) limitStream(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler, client string,
+) error {
+	retryAfter, limited := rl.take(ss.Context(), info.FullMethod, client)
+	if limited {
+		if err := ss.SetHeader(retryAfterHeader(retryAfter)); err != nil {
+			rl.logger.Debug("error while setting retry-after header", map[string]interface{}{"error": err})
+		}
+
+		return rateLimitError(retryAfter)
+	}
+
+	return handler(srv, ss)
+}
+
+// LimitAddr checks the limit of the method for a client of another server, e.g. the admin HTTP server,
+// identified by its remote "host:port" address. retryAfter is in whole seconds, as in the retry-after header.
+func (rl *RateLimiter) LimitAddr(ctx context.Context, fullMethod, remoteAddr string) (retryAfter int, limited bool) {
+	wait, limited := rl.take(ctx, fullMethod, addrKey(remoteAddr))
+
+	return retryAfterSeconds(wait), limited
+}
+
+func (rl *RateLimiter) take(ctx context.Context, fullMethod, client string) (time.Duration, bool) {
+	limit, bucket := rl.limits.Load().For(fullMethod)
+	if limit.Unlimited() {
+		return 0, false
+	}
+
+	allowed, retryAfter, err := rl.backend.Take(ctx, client+"|"+bucket, limit)
+	if err != nil {
+		rl.logger.Warn("rate limiter backend failed, request is allowed", map[string]interface{}{"error": err})
+		return 0, false
+	}
+
+	if !allowed {
+		rl.logger.Warn("rate limit exceeded", map[string]interface{}{"client": client, "method": fullMethod})
+	}
+
+	return retryAfter, !allowed
+}
+
+// peerKey identifies the connection of an unauthenticated caller, nothing the caller sends is trusted yet.
+func peerKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return addrKey(p.Addr.String())
+	}
+
+	return "unknown"
+}
+
+// addrKey is the client key of a remote "host:port" address, the port is dropped since every connection
+// of a client has its own.
+func addrKey(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return "ip:" + host
+}
+
+// principalKey identifies an authenticated caller by its tenant and username, usernames are unique within
+// a tenant only. API keys are hashed so that they never reach the backend or the logs.
+func principalKey(ctx context.Context) (string, bool) {
+	if username, ok := ctx.Value(contextValue("principal")).(string); ok && username != "" {
+		return "principal:" + app.TenantFromContext(ctx) + "/" + username, true
+	}
+
+	if values := metadata.ValueFromIncomingContext(ctx, APIKeyHeader); len(values) > 0 && values[0] != "" {
+		sum := sha256.Sum256([]byte(values[0]))
+		return "apikey:" + hex.EncodeToString(sum[:8]), true
+	}
+
+	return "", false
+}
+
+func retryAfterHeader(retryAfter time.Duration) metadata.MD {
+	return metadata.Pairs(RetryAfterHeader, strconv.Itoa(retryAfterSeconds(retryAfter)))
+}
+
+// retryAfterSeconds rounds up, a client retrying after a shorter time would be rejected again.
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+}
+
+func rateLimitError(retryAfter time.Duration) error {
+	message := fmt.Sprintf("rate limit exceeded, retry after %ds", retryAfterSeconds(retryAfter))
+	st, err := status.New(codes.ResourceExhausted, message).WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,195 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type backendMock struct {
+	keys    []string
+	allowed bool
+	err     error
+}
+
+func (b *backendMock) Take(_ context.Context, key string, _ ratelimit.Limit) (bool, time.Duration, error) {
+	b.keys = append(b.keys, key)
+	return b.allowed, 1500 * time.Millisecond, b.err
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 5000}})
+	limits := ratelimit.Limits{
+		Default: ratelimit.Limit{Rate: 10, Burst: 10},
+		Methods: map[string]ratelimit.Limit{"CreateUser": {Rate: 1, Burst: 1}, "GetUsers": {}},
+	}
+
+	testTable := []struct {
+		name         string
+		ctx          context.Context
+		method       string
+		expectedKeys []string
+	}{
+		{
+			name: "principal",
+			ctx: withPrincipal(metadata.NewIncomingContext(peerCtx,
+				metadata.Pairs("authorization", basicHeader("admin", "secret"))), "admin"),
+			method:       "/user.UserService/CreateUser",
+			expectedKeys: []string{"ip:10.0.0.7|createuser", "principal:default/admin|createuser"},
+		},
+		{
+			name: "principal of a tenant",
+			ctx: withPrincipal(app.WithTenant(metadata.NewIncomingContext(peerCtx,
+				metadata.Pairs("authorization", basicHeader("admin", "secret"), APIKeyHeader, "key-1")), "acme"), "admin"),
+			method:       "/user.UserService/CreateUser",
+			expectedKeys: []string{"ip:10.0.0.7|createuser", "principal:acme/admin|createuser"},
+		},
+		{
+			name:         "unverified credentials",
+			ctx:          metadata.NewIncomingContext(peerCtx, metadata.Pairs("authorization", basicHeader("admin", "guess"))),
+			method:       "/user.UserService/CreateUser",
+			expectedKeys: []string{"ip:10.0.0.7|createuser"},
+		},
+		{
+			name:         "api key",
+			ctx:          metadata.NewIncomingContext(peerCtx, metadata.Pairs(APIKeyHeader, "key-1")),
+			method:       "/user.UserService/GetOneUserByID",
+			expectedKeys: []string{"ip:10.0.0.7|*", "apikey:be2974546978e373|*"},
+		},
+		{
+			name:         "peer ip",
+			ctx:          peerCtx,
+			method:       "/user.UserService/GetOneUserByID",
+			expectedKeys: []string{"ip:10.0.0.7|*"},
+		},
+		{
+			name:   "unlimited method",
+			ctx:    peerCtx,
+			method: "/user.UserService/GetUsers",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			backend := &backendMock{allowed: true}
+			limiter := NewRateLimiter(logg, backend, limits)
+			interceptor := ChainUnaryInterceptors(limiter.UnaryInterceptor, limiter.PrincipalUnaryInterceptor)
+
+			_, err := interceptor(testCase.ctx, nil, &grpc.UnaryServerInfo{FullMethod: testCase.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedKeys, backend.keys)
+		})
+	}
+}
+
+func TestRateLimiterRejects(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	limiter := NewRateLimiter(logg, &backendMock{}, ratelimit.Limits{Default: ratelimit.Limit{Rate: 1, Burst: 1}})
+	info := &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"}
+	stream := &headerStreamMock{watchStreamMock: watchStreamMock{ctx: context.Background()}}
+
+	err = limiter.StreamInterceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		t.Fatal("handler must not be called")
+		return nil
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, []string{"2"}, stream.header.Get(RetryAfterHeader))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	require.Equal(t, 1500*time.Millisecond, details[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+}
+
+func TestRateLimiterBackendFailure(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	limiter := NewRateLimiter(logg, &backendMock{err: errors.New("backend is down")}, ratelimit.Limits{Default: ratelimit.Limit{Rate: 1}})
+
+	called := false
+	_, err = limiter.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestRateLimiterMemoryBackend(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	limiter := NewRateLimiter(logg, ratelimit.NewMemoryBackend(), ratelimit.Limits{Default: ratelimit.Limit{Rate: 0.1, Burst: 2}})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", basicHeader("viewer", "secret")))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	for i := 0; i < 2; i++ {
+		_, err = limiter.UnaryInterceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+	}
+
+	_, err = limiter.UnaryInterceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	limiter.SetLimits(ratelimit.Limits{})
+	_, err = limiter.UnaryInterceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+}
+
+func TestRateLimiterBeforeAuth(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CheckPassword(gomock.Any(), gomock.Any(), "guess").Return(false, app.ErrPasswordMismatch).Times(2)
+	service.EXPECT().CheckPassword(gomock.Any(), "admin", "secret").Return(true, nil)
+
+	server := NewServer(service, logg)
+	limiter := NewRateLimiter(logg, ratelimit.NewMemoryBackend(), ratelimit.Limits{Default: ratelimit.Limit{Rate: 0.01, Burst: 2}})
+	interceptor := ChainUnaryInterceptors(limiter.UnaryInterceptor, server.BasicAuthInterceptor, limiter.PrincipalUnaryInterceptor)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/CreateUser"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	attacker := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 66), Port: 5000}})
+
+	// a wrong password leaves the call anonymous, the handler decides whether it is allowed
+	for _, username := range []string{"admin", "root", "guest"} {
+		ctx := metadata.NewIncomingContext(attacker, metadata.Pairs("authorization", basicHeader(username, "guess")))
+		_, err = interceptor(ctx, nil, info, handler)
+		if username == "guest" {
+			require.Equal(t, codes.ResourceExhausted, status.Code(err), "another username does not reset the limit of the peer")
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	admin := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 5000}})
+	ctx := metadata.NewIncomingContext(admin, metadata.Pairs("authorization", basicHeader("admin", "secret")))
+	_, err = interceptor(ctx, nil, info, handler)
+	require.NoError(t, err, "failed guesses do not take the tokens of the account")
+}
+
+func basicHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
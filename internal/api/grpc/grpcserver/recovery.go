@@ -0,0 +1,56 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery turns a panic in an interceptor or handler running inside it into codes.Internal, the panic
+// value and the stack are logged but never returned to the client. It must run after the RequestLogger
+// so that the record carries the request id.
+type Recovery struct {
+	logger app.Logger
+}
+
+func NewRecovery(logger app.Logger) *Recovery {
+	return &Recovery{logger: logger}
+}
+
+func (r *Recovery) UnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			resp, err = nil, r.recovered(ctx, info.FullMethod, value)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func (r *Recovery) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = r.recovered(ss.Context(), info.FullMethod, value)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func (r *Recovery) recovered(ctx context.Context, method string, value interface{}) error {
+	app.ContextLogger(ctx, r.logger).Error("panic while handling request", map[string]interface{}{
+		"method": method,
+		"panic":  fmt.Sprint(value),
+		"stack":  string(debug.Stack()),
+	})
+
+	return status.Error(codes.Internal, "internal error")
+}
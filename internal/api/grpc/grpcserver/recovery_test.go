@@ -0,0 +1,66 @@
+package grpcserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecovery(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+	recovery := NewRecovery(logg)
+
+	resp, err := recovery.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetOneUserByID"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			var user *models.User
+			return convert(*user), nil
+		})
+	require.Nil(t, resp)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, "internal error", status.Convert(err).Message())
+
+	stream := &watchStreamMock{ctx: context.Background()}
+	err = recovery.StreamInterceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			panic("stream handler failed")
+		})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = recovery.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRecoveryLogsStack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	logg, err := logger.New(logger.Config{
+		Level:  "ERROR",
+		Format: logger.FormatJSON,
+		Sinks:  []logger.SinkConfig{{Type: logger.SinkFile, File: logger.FileConfig{Path: path}}},
+	})
+	require.NoError(t, err)
+
+	_, err = NewRecovery(logg).UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.NoError(t, logg.Sync())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "panic while handling request")
+	require.Contains(t, string(data), `"panic":"boom"`)
+	require.Contains(t, string(data), "TestRecoveryLogsStack")
+}
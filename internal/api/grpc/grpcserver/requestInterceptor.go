@@ -0,0 +1,90 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RequestIDHeader is read from the incoming metadata, generated when missing and echoed in the response header.
+const RequestIDHeader = "x-request-id"
+
+const maxRequestIDLength = 128
+
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestStream) Context() context.Context {
+	return s.ctx
+}
+
+// RequestLogger puts a child logger carrying the request id, method and peer into the request context.
+// It must run before the auth interceptors, which add the principal to that logger.
+type RequestLogger struct {
+	logger *logger.ZapLogger
+}
+
+func NewRequestLogger(logger *logger.ZapLogger) *RequestLogger {
+	return &RequestLogger{logger: logger}
+}
+
+func (rl *RequestLogger) UnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, requestID := rl.scope(ctx, info.FullMethod)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID)); err != nil {
+		rl.logger.Debug("error while setting request id header", map[string]interface{}{"error": err})
+	}
+
+	return handler(ctx, req)
+}
+
+func (rl *RequestLogger) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, requestID := rl.scope(ss.Context(), info.FullMethod)
+	if err := ss.SetHeader(metadata.Pairs(RequestIDHeader, requestID)); err != nil {
+		rl.logger.Debug("error while setting request id header", map[string]interface{}{"error": err})
+	}
+
+	return handler(srv, &requestStream{ServerStream: ss, ctx: ctx})
+}
+
+func (rl *RequestLogger) scope(ctx context.Context, fullMethod string) (context.Context, string) {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, RequestIDHeader); len(values) > 0 && len(values[0]) <= maxRequestIDLength {
+		requestID = values[0]
+	}
+
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	fields := map[string]interface{}{"request_id": requestID, "method": fullMethod}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer"] = p.Addr.String()
+	}
+
+	return logger.ContextWithLogger(ctx, rl.logger.With(fields)), requestID
+}
+
+// withPrincipal adds the authenticated username and its tenant to the request-scoped logger, if any.
+func withPrincipal(ctx context.Context, username string) context.Context {
+	ctx = context.WithValue(ctx, contextValue("principal"), username)
+
+	requestLogger, ok := logger.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return logger.ContextWithLogger(ctx, requestLogger.With(map[string]interface{}{
+		"principal": username,
+		"tenant":    app.TenantFromContext(ctx),
+	}))
+}
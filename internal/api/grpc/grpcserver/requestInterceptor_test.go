@@ -0,0 +1,91 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRequestLogger(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("viewer:password"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Basic "+credentials,
+		RequestIDHeader, "client-request-1",
+	))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 5000}})
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CheckPassword(gomock.Any(), "viewer", "password").Return(false, app.ErrNotAdmin)
+	server := NewServer(service, logg)
+
+	chain := ChainUnaryInterceptors(NewRequestLogger(logg).UnaryInterceptor, server.BasicAuthInterceptor)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"}
+	_, err = chain(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		app.ContextLogger(ctx, logg).Info("handled", nil)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Contains(t, string(got), "handled")
+	require.Contains(t, string(got), `"request_id": "client-request-1"`)
+	require.Contains(t, string(got), `"method": "/user.UserService/GetUsers"`)
+	require.Contains(t, string(got), `"peer": "10.0.0.7:5000"`)
+	require.Contains(t, string(got), `"principal": "viewer"`)
+}
+
+func TestRequestLoggerGeneratesID(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	info := &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"}
+	stream := &headerStreamMock{watchStreamMock: watchStreamMock{ctx: context.Background()}}
+
+	err = NewRequestLogger(logg).StreamInterceptor(nil, stream, info, func(srv interface{}, ss grpc.ServerStream) error {
+		_, ok := logger.FromContext(ss.Context())
+		require.True(t, ok)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = uuid.Parse(stream.header.Get(RequestIDHeader)[0])
+	require.NoError(t, err)
+}
+
+type headerStreamMock struct {
+	watchStreamMock
+	header metadata.MD
+}
+
+func (s *headerStreamMock) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
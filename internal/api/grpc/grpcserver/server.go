@@ -3,6 +3,7 @@ package grpcserver
 //nolint:depguard
 import (
 	"context"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/api"
 	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
@@ -11,14 +12,23 @@ import (
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Server struct {
-	service api.ServiceInterface
-	logger  app.Logger
+	service  api.ServiceInterface
+	logger   app.Logger
+	metrics  AuthMetrics
+	levels   LogLevels
+	tokenKey []byte
 	pb.UnimplementedUserServiceServer
 }
 
+// AuthMetrics records the outcome of every authentication attempt.
+type AuthMetrics interface {
+	ObserveAuth(success bool, reason string)
+}
+
 func NewServer(service api.ServiceInterface, logger app.Logger) *Server {
 	return &Server{
 		service: service,
@@ -26,18 +36,17 @@ func NewServer(service api.ServiceInterface, logger app.Logger) *Server {
 	}
 }
 
+func (s *Server) SetMetrics(metrics AuthMetrics) {
+	s.metrics = metrics
+}
+
 func (s Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.UserResponse, error) {
 	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
 	if !isAdmin || !ok {
 		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
 	}
 
-	result, err := s.service.CreateUser(ctx, &models.User{
-		Email:    req.Email,
-		UserName: req.Username,
-		Password: req.Password,
-		Admin:    req.Admin,
-	})
+	result, err := s.service.CreateUser(ctx, convertUserFromPb(req))
 	if err != nil {
 		return nil, err
 	}
@@ -51,21 +60,35 @@ func (s Server) UpdateUser(ctx context.Context, req *pb.ChangeUserRequest) (*emp
 		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
 	}
 
-	var updateUserDTO models.UpdateUserDTO
+	err := s.service.UpdateUser(ctx, convertUpdateFromPb(req), req.Id)
+	if err != nil {
+		return nil, err
+	}
 
-	if req.Email != "" {
-		updateUserDTO.Email = &req.Email
+	return &empty.Empty{}, nil
+}
+
+func (s Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
 	}
 
-	if req.Username != "" {
-		updateUserDTO.UserName = &req.Username
+	err := s.service.DeleteUser(ctx, req.Id)
+	if err != nil {
+		return &pb.DeleteUserResponse{Success: false}, err
 	}
 
-	if req.Password != "" {
-		updateUserDTO.Password = &req.Password
+	return &pb.DeleteUserResponse{Success: true}, nil
+}
+
+func (s Server) UndeleteUser(ctx context.Context, req *pb.UndeleteUserRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
 	}
 
-	err := s.service.UpdateUser(ctx, updateUserDTO, req.Id)
+	err := s.service.UndeleteUser(ctx, req.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -73,22 +96,65 @@ func (s Server) UpdateUser(ctx context.Context, req *pb.ChangeUserRequest) (*emp
 	return &empty.Empty{}, nil
 }
 
-func (s Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+func (s Server) PurgeUser(ctx context.Context, req *pb.PurgeUserRequest) (*empty.Empty, error) {
 	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
 	if !isAdmin || !ok {
 		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
 	}
 
-	err := s.service.DeleteUser(ctx, req.Id)
+	err := s.service.PurgeUser(ctx, req.Id)
 	if err != nil {
-		return &pb.DeleteUserResponse{Success: false}, err
+		return nil, err
 	}
 
-	return &pb.DeleteUserResponse{Success: true}, nil
+	return &empty.Empty{}, nil
+}
+
+func (s Server) SuspendUser(ctx context.Context, req *pb.SuspendUserRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	err := s.service.SuspendUser(ctx, req.Id, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) ReactivateUser(ctx context.Context, req *pb.ReactivateUserRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	err := s.service.ReactivateUser(ctx, req.Id, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &empty.Empty{}, nil
 }
 
 func (s Server) GetUsers(ctx context.Context, req *pb.GetUsersRequest) (*pb.GetUsersResponse, error) {
-	users, count, err := s.service.GetUsers(ctx, int(req.Offset), int(req.Limit))
+	if err := checkShowDeleted(ctx, req.ShowDeleted); err != nil {
+		return nil, err
+	}
+
+	users, count, err := s.service.GetUsers(ctx, models.UsersFilter{
+		Offset:          int(req.Offset),
+		Limit:           int(req.Limit),
+		ShowDeleted:     req.ShowDeleted,
+		CreatedAfter:    convertTimestamp(req.CreatedAfter),
+		CreatedBefore:   convertTimestamp(req.CreatedBefore),
+		UpdatedAfter:    convertTimestamp(req.UpdatedAfter),
+		UpdatedBefore:   convertTimestamp(req.UpdatedBefore),
+		LastLoginAfter:  convertTimestamp(req.LastLoginAfter),
+		LastLoginBefore: convertTimestamp(req.LastLoginBefore),
+		Attributes:      req.Attributes,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +172,11 @@ func (s Server) GetUsers(ctx context.Context, req *pb.GetUsersRequest) (*pb.GetU
 }
 
 func (s Server) GetOneUserByID(ctx context.Context, req *pb.GetUserByIdRequest) (*pb.UserResponse, error) {
-	user, err := s.service.GetOneUserByID(ctx, req.Id)
+	if err := checkShowDeleted(ctx, req.ShowDeleted); err != nil {
+		return nil, err
+	}
+
+	user, err := s.service.GetOneUserByID(ctx, req.Id, req.ShowDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +185,11 @@ func (s Server) GetOneUserByID(ctx context.Context, req *pb.GetUserByIdRequest)
 }
 
 func (s Server) GetOneUserByUsername(ctx context.Context, req *pb.GetUserByUsernameRequest) (*pb.UserResponse, error) {
-	user, err := s.service.GetOneUserByUsername(ctx, req.Username)
+	if err := checkShowDeleted(ctx, req.ShowDeleted); err != nil {
+		return nil, err
+	}
+
+	user, err := s.service.GetOneUserByUsername(ctx, req.Username, req.ShowDeleted)
 	if err != nil {
 		return nil, err
 	}
@@ -123,12 +197,147 @@ func (s Server) GetOneUserByUsername(ctx context.Context, req *pb.GetUserByUsern
 	return &pb.UserResponse{User: convert(*user)}, nil
 }
 
+func checkShowDeleted(ctx context.Context, showDeleted bool) error {
+	if !showDeleted {
+		return nil
+	}
+
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return status.Error(codes.PermissionDenied, "only admin can see deleted users")
+	}
+
+	return nil
+}
+
 func convert(user models.User) *pb.User {
-	return &pb.User{
-		Id:       user.ID,
-		Email:    user.Email,
-		Username: user.UserName,
-		Password: user.Password,
-		Admin:    user.Admin,
+	pbUser := &pb.User{
+		Id:         user.ID,
+		TenantId:   user.TenantID,
+		Email:      user.Email,
+		Username:   user.UserName,
+		Password:   user.Password,
+		Admin:      user.Admin,
+		SuperAdmin: user.SuperAdmin,
+	}
+
+	if user.Profile != (models.Profile{}) {
+		pbUser.Profile = &pb.UserProfile{
+			DisplayName: user.Profile.DisplayName,
+			Locale:      user.Profile.Locale,
+			TimeZone:    user.Profile.TimeZone,
+			PhoneNumber: user.Profile.PhoneNumber,
+		}
+	}
+
+	if len(user.Attributes) > 0 {
+		pbUser.Attributes = user.Attributes
+	}
+
+	if user.Status != "" {
+		pbUser.Status = convertStatus(user.Status)
+		pbUser.StatusReason = user.StatusReason
+	}
+
+	if !user.CreatedAt.IsZero() {
+		pbUser.CreatedAt = timestamppb.New(user.CreatedAt)
+	}
+
+	if !user.UpdatedAt.IsZero() {
+		pbUser.UpdatedAt = timestamppb.New(user.UpdatedAt)
+	}
+
+	if user.LastLoginAt != nil {
+		pbUser.LastLoginAt = timestamppb.New(*user.LastLoginAt)
+	}
+
+	if user.DeletedAt != nil {
+		pbUser.DeletedAt = timestamppb.New(*user.DeletedAt)
+	}
+
+	return pbUser
+}
+
+// convertWithoutPassword is used where the password hash must not leave the service, e.g. events sent to
+// watchers, the users created by a batch or by an accepted invitation.
+func convertWithoutPassword(user models.User) *pb.User {
+	user.Password = ""
+
+	return convert(user)
+}
+
+func convertStatus(userStatus models.UserStatus) pb.UserStatus {
+	switch userStatus {
+	case models.StatusPending:
+		return pb.UserStatus_USER_STATUS_PENDING
+	case models.StatusActive:
+		return pb.UserStatus_USER_STATUS_ACTIVE
+	case models.StatusSuspended:
+		return pb.UserStatus_USER_STATUS_SUSPENDED
+	case models.StatusLocked:
+		return pb.UserStatus_USER_STATUS_LOCKED
+	default:
+		return pb.UserStatus_USER_STATUS_UNSPECIFIED
+	}
+}
+
+func convertTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+
+	return &t
+}
+
+func convertUserFromPb(req *pb.CreateUserRequest) *models.User {
+	return &models.User{
+		Email:      req.Email,
+		UserName:   req.Username,
+		Password:   req.Password,
+		Admin:      req.Admin,
+		Profile:    convertProfileFromPb(req.Profile),
+		Attributes: req.Attributes,
+	}
+}
+
+func convertUpdateFromPb(req *pb.ChangeUserRequest) models.UpdateUserDTO {
+	var updateUserDTO models.UpdateUserDTO
+
+	if req.Email != "" {
+		updateUserDTO.Email = &req.Email
+	}
+
+	if req.Username != "" {
+		updateUserDTO.UserName = &req.Username
+	}
+
+	if req.Password != "" {
+		updateUserDTO.Password = &req.Password
+	}
+
+	if req.Profile != nil {
+		profile := convertProfileFromPb(req.Profile)
+		updateUserDTO.Profile = &profile
+	}
+
+	if len(req.Attributes) > 0 {
+		updateUserDTO.Attributes = req.Attributes
+	}
+
+	return updateUserDTO
+}
+
+func convertProfileFromPb(profile *pb.UserProfile) models.Profile {
+	if profile == nil {
+		return models.Profile{}
+	}
+
+	return models.Profile{
+		DisplayName: profile.DisplayName,
+		Locale:      profile.Locale,
+		TimeZone:    profile.TimeZone,
+		PhoneNumber: profile.PhoneNumber,
 	}
 }
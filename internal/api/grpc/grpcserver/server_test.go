@@ -2,16 +2,26 @@ package grpcserver
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
 	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
 	"github.com/Baraulia/X-Labs_Test/internal/models"
 	"github.com/Baraulia/X-Labs_Test/pkg/logger"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -207,7 +217,7 @@ func TestGetOneUserByID(t *testing.T) {
 			},
 			id: newUUID,
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, id string) {
-				s.EXPECT().GetOneUserByID(ctx, id).Return(&models.User{
+				s.EXPECT().GetOneUserByID(ctx, id, false).Return(&models.User{
 					ID:       newUUID,
 					Email:    "test@gmail.com",
 					UserName: "test username",
@@ -231,7 +241,7 @@ func TestGetOneUserByID(t *testing.T) {
 			},
 			id: newUUID,
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, id string) {
-				s.EXPECT().GetOneUserByID(ctx, id).Return(nil, errors.New("service error"))
+				s.EXPECT().GetOneUserByID(ctx, id, false).Return(nil, errors.New("service error"))
 			},
 			expectedError: true,
 		},
@@ -278,7 +288,7 @@ func TestGetOneUserByUsername(t *testing.T) {
 			},
 			username: "username",
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, username string) {
-				s.EXPECT().GetOneUserByUsername(ctx, username).Return(&models.User{
+				s.EXPECT().GetOneUserByUsername(ctx, username, false).Return(&models.User{
 					ID:       newUUID,
 					Email:    "test@gmail.com",
 					UserName: "username",
@@ -302,7 +312,7 @@ func TestGetOneUserByUsername(t *testing.T) {
 			},
 			username: "username",
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, username string) {
-				s.EXPECT().GetOneUserByUsername(ctx, username).Return(nil, errors.New("service error"))
+				s.EXPECT().GetOneUserByUsername(ctx, username, false).Return(nil, errors.New("service error"))
 			},
 			expectedError: true,
 		},
@@ -434,7 +444,7 @@ func TestGetUsers(t *testing.T) {
 				TotalUsers: int32(totalUsers),
 			},
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, offset, limit int) {
-				s.EXPECT().GetUsers(ctx, offset, limit).Return([]models.User{
+				s.EXPECT().GetUsers(ctx, models.UsersFilter{Offset: offset, Limit: limit}).Return([]models.User{
 					{
 						ID:       newUUID1,
 						Email:    "test@gmail.com",
@@ -462,7 +472,7 @@ func TestGetUsers(t *testing.T) {
 			offset: 10,
 			limit:  2,
 			mockBehavior: func(s *serviceMocks.MockServiceInterface, offset, limit int) {
-				s.EXPECT().GetUsers(ctx, offset, limit).Return(nil, 0, errors.New("service error"))
+				s.EXPECT().GetUsers(ctx, models.UsersFilter{Offset: offset, Limit: limit}).Return(nil, 0, errors.New("service error"))
 			},
 			expectedError: true,
 		},
@@ -486,3 +496,786 @@ func TestGetUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUndeleteUser(t *testing.T) {
+	type mockBehavior func(s *serviceMocks.MockServiceInterface, id string)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	newUUID := uuid.New().String()
+
+	testTable := []struct {
+		name           string
+		inputData      *pb.UndeleteUserRequest
+		requestContext context.Context
+		mockBehavior   mockBehavior
+		expectedError  bool
+	}{
+		{
+			name:           "successful",
+			inputData:      &pb.UndeleteUserRequest{Id: newUUID},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface, id string) {
+				s.EXPECT().UndeleteUser(ctx, id).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:           "error from service",
+			inputData:      &pb.UndeleteUserRequest{Id: newUUID},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface, id string) {
+				s.EXPECT().UndeleteUser(ctx, id).Return(errors.New("service error"))
+			},
+			expectedError: true,
+		},
+		{
+			name:           "not admin request",
+			inputData:      &pb.UndeleteUserRequest{Id: newUUID},
+			requestContext: notAdminCtx,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface, id string) {},
+			expectedError:  true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service, testCase.inputData.Id)
+			server := NewServer(service, logg)
+
+			_, err := server.UndeleteUser(testCase.requestContext, testCase.inputData)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPurgeUser(t *testing.T) {
+	type mockBehavior func(s *serviceMocks.MockServiceInterface, id string)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	newUUID := uuid.New().String()
+
+	testTable := []struct {
+		name           string
+		inputData      *pb.PurgeUserRequest
+		requestContext context.Context
+		mockBehavior   mockBehavior
+		expectedError  bool
+	}{
+		{
+			name:           "successful",
+			inputData:      &pb.PurgeUserRequest{Id: newUUID},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface, id string) {
+				s.EXPECT().PurgeUser(ctx, id).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:           "not admin request",
+			inputData:      &pb.PurgeUserRequest{Id: newUUID},
+			requestContext: notAdminCtx,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface, id string) {},
+			expectedError:  true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service, testCase.inputData.Id)
+			server := NewServer(service, logg)
+
+			_, err := server.PurgeUser(testCase.requestContext, testCase.inputData)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetUsersShowDeleted(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().GetUsers(ctx, models.UsersFilter{Limit: 10, ShowDeleted: true}).Return([]models.User{}, 0, nil)
+	server := NewServer(service, logg)
+
+	_, err = server.GetUsers(ctx, &pb.GetUsersRequest{Limit: 10, ShowDeleted: true})
+	require.NoError(t, err)
+
+	_, err = server.GetUsers(notAdminCtx, &pb.GetUsersRequest{Limit: 10, ShowDeleted: true})
+	require.Error(t, err)
+}
+
+func TestSuspendUser(t *testing.T) {
+	type mockBehavior func(s *serviceMocks.MockServiceInterface, req *pb.SuspendUserRequest)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	newUUID := uuid.New().String()
+
+	testTable := []struct {
+		name           string
+		inputData      *pb.SuspendUserRequest
+		requestContext context.Context
+		mockBehavior   mockBehavior
+		expectedError  bool
+	}{
+		{
+			name:           "successful",
+			inputData:      &pb.SuspendUserRequest{Id: newUUID, Reason: "fraud"},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface, req *pb.SuspendUserRequest) {
+				s.EXPECT().SuspendUser(ctx, req.Id, req.Reason).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:           "error from service",
+			inputData:      &pb.SuspendUserRequest{Id: newUUID, Reason: "fraud"},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface, req *pb.SuspendUserRequest) {
+				s.EXPECT().SuspendUser(ctx, req.Id, req.Reason).Return(errors.New("service error"))
+			},
+			expectedError: true,
+		},
+		{
+			name:           "not admin request",
+			inputData:      &pb.SuspendUserRequest{Id: newUUID, Reason: "fraud"},
+			requestContext: notAdminCtx,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface, req *pb.SuspendUserRequest) {},
+			expectedError:  true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service, testCase.inputData)
+			server := NewServer(service, logg)
+
+			_, err := server.SuspendUser(testCase.requestContext, testCase.inputData)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBasicAuthInterceptorAccountStatus(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	credentials := base64.StdEncoding.EncodeToString([]byte("admin:password"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic "+credentials))
+
+	testTable := []struct {
+		name         string
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{"pending user", app.ErrUserPending, codes.FailedPrecondition},
+		{"suspended user", app.ErrUserSuspended, codes.PermissionDenied},
+		{"locked user", app.ErrUserLocked, codes.PermissionDenied},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			service.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(false, testCase.serviceError)
+			server := NewServer(service, logg)
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				t.Error("handler must not be called for inactive account")
+				return nil, nil
+			}
+
+			_, err := server.BasicAuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestBasicAuthInterceptorTenant(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	credentials := base64.StdEncoding.EncodeToString([]byte("admin:password"))
+
+	testTable := []struct {
+		name           string
+		metadata       metadata.MD
+		mockBehavior   func(s *serviceMocks.MockServiceInterface)
+		expectedCode   codes.Code
+		expectedTenant string
+	}{
+		{
+			name:           "default tenant",
+			metadata:       metadata.Pairs("authorization", "Basic "+credentials),
+			expectedTenant: models.DefaultTenant,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(true, nil)
+			},
+		},
+		{
+			name:           "selected tenant",
+			metadata:       metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, "acme"),
+			expectedTenant: "acme",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").
+					DoAndReturn(func(ctx context.Context, _, _ string) (bool, error) {
+						require.Equal(t, "acme", app.TenantFromContext(ctx), "credentials are checked in the selected tenant")
+						return true, nil
+					})
+			},
+		},
+		{
+			name:         "invalid tenant",
+			metadata:     metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, "Acme Corp"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "every tenant without credentials",
+			metadata:     metadata.Pairs(TenantHeader, models.AllTenants),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:           "every tenant as super-admin",
+			metadata:       metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, models.AllTenants),
+			expectedTenant: models.AllTenants,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(true, nil)
+			},
+		},
+		{
+			name:         "other tenant without credentials",
+			metadata:     metadata.Pairs(TenantHeader, "acme"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:     "other tenant with a wrong password",
+			metadata: metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, "acme"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(false, app.ErrPasswordMismatch)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:           "user of the selected tenant",
+			metadata:       metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, "acme"),
+			expectedTenant: "acme",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(false, app.ErrNotAdmin)
+			},
+		},
+		{
+			name:     "other tenant without super-admin",
+			metadata: metadata.Pairs("authorization", "Basic "+credentials, TenantHeader, "acme"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(false, app.ErrCrossTenant)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service)
+			server := NewServer(service, logg)
+
+			var tenantID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				tenantID = app.TenantFromContext(ctx)
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), testCase.metadata)
+			_, err := server.BasicAuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+			require.Equal(t, testCase.expectedTenant, tenantID)
+		})
+	}
+}
+
+func TestGetUsersTimeFilters(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastLoginBefore := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().GetUsers(ctx, models.UsersFilter{Limit: 10, LastLoginBefore: &lastLoginBefore}).Return([]models.User{
+		{
+			ID:        newUUID,
+			Email:     "test@gmail.com",
+			UserName:  "testUserName",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		},
+	}, 1, nil)
+	server := NewServer(service, logg)
+
+	response, err := server.GetUsers(ctx, &pb.GetUsersRequest{Limit: 10, LastLoginBefore: timestamppb.New(lastLoginBefore)})
+	require.NoError(t, err)
+	require.Equal(t, &pb.GetUsersResponse{
+		Users: []*pb.User{
+			{
+				Id:        newUUID,
+				Email:     "test@gmail.com",
+				Username:  "testUserName",
+				CreatedAt: timestamppb.New(createdAt),
+				UpdatedAt: timestamppb.New(createdAt),
+			},
+		},
+		TotalUsers: 1,
+	}, response)
+}
+
+func TestCreateUserProfile(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	newUUID := uuid.New().String()
+	profile := models.Profile{DisplayName: "Test User", Locale: "en-US", TimeZone: "UTC", PhoneNumber: "+375291234567"}
+	attributes := map[string]string{"department": "sales"}
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CreateUser(ctx, &models.User{
+		Email:      "test@gmail.com",
+		UserName:   "testUserName",
+		Password:   "test",
+		Profile:    profile,
+		Attributes: attributes,
+	}).Return(&models.User{
+		ID:         newUUID,
+		Email:      "test@gmail.com",
+		UserName:   "testUserName",
+		Profile:    profile,
+		Attributes: attributes,
+	}, nil)
+	server := NewServer(service, logg)
+
+	pbProfile := &pb.UserProfile{DisplayName: "Test User", Locale: "en-US", TimeZone: "UTC", PhoneNumber: "+375291234567"}
+	response, err := server.CreateUser(ctx, &pb.CreateUserRequest{
+		Email:      "test@gmail.com",
+		Username:   "testUserName",
+		Password:   "test",
+		Profile:    pbProfile,
+		Attributes: attributes,
+	})
+	require.NoError(t, err)
+	require.Equal(t, pbProfile, response.User.Profile)
+	require.Equal(t, attributes, response.User.Attributes)
+}
+
+func TestBatchCreateUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().BatchCreateUsers(ctx, []*models.User{
+		{Email: "test@gmail.com", UserName: "test", Password: "test"},
+		{Email: "test@gmail.com", UserName: "test2", Password: "test"},
+	}, true).Return([]models.BatchResult{
+		{Err: app.ErrBatchAborted},
+		{Err: errors.New("user with email test@gmail.com already exists")},
+	}, app.ErrBatchAborted)
+	service.EXPECT().BatchCreateUsers(ctx, []*models.User{
+		{Email: "test@gmail.com", UserName: "test", Password: "test"},
+	}, false).Return([]models.BatchResult{
+		{User: &models.User{ID: newUUID, Email: "test@gmail.com", UserName: "test", Password: "$2a$10$hash"}},
+	}, nil)
+	server := NewServer(service, logg)
+
+	response, err := server.BatchCreateUsers(ctx, &pb.BatchCreateUsersRequest{
+		Users: []*pb.CreateUserRequest{
+			{Email: "test@gmail.com", Username: "test", Password: "test"},
+			{Email: "test@gmail.com", Username: "test2", Password: "test"},
+		},
+		Mode: pb.BatchMode_BATCH_MODE_ALL_OR_NOTHING,
+	})
+	require.NoError(t, err)
+	require.False(t, response.Committed)
+	require.Len(t, response.Results, 2)
+	require.False(t, response.Results[0].Success)
+	require.Equal(t, uint32(1), response.Results[1].Index)
+	require.NotEmpty(t, response.Results[1].Error)
+
+	response, err = server.BatchCreateUsers(ctx, &pb.BatchCreateUsersRequest{
+		Users: []*pb.CreateUserRequest{{Email: "test@gmail.com", Username: "test", Password: "test"}},
+	})
+	require.NoError(t, err)
+	require.True(t, response.Committed)
+	require.True(t, response.Results[0].Success)
+	require.Equal(t, newUUID, response.Results[0].User.Id)
+	require.Empty(t, response.Results[0].User.Password, "results do not carry password hashes")
+
+	_, err = server.BatchCreateUsers(notAdminCtx, &pb.BatchCreateUsersRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().AcceptInvitation(ctx, "token", "alice", "secret").Return(&models.User{
+		ID: newUUID, Email: "alice@gmail.com", UserName: "alice", Password: "$2a$10$hash", Status: models.StatusActive,
+	}, nil)
+	service.EXPECT().AcceptInvitation(ctx, "expired", "alice", "secret").Return(nil, app.ErrInvitationExpired)
+	server := NewServer(service, logg)
+
+	response, err := server.AcceptInvitation(ctx, &pb.AcceptInvitationRequest{Token: "token", Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+	require.Equal(t, newUUID, response.User.Id)
+	require.Equal(t, pb.UserStatus_USER_STATUS_ACTIVE, response.User.Status)
+	require.Empty(t, response.User.Password, "the response does not carry the password hash")
+
+	_, err = server.AcceptInvitation(ctx, &pb.AcceptInvitationRequest{Token: "expired", Username: "alice", Password: "secret"})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+type watchStreamMock struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events []*pb.UserEvent
+}
+
+func (m *watchStreamMock) Context() context.Context {
+	return m.ctx
+}
+
+func (m *watchStreamMock) Send(event *pb.UserEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func TestWatchUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+	occurredAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().WatchUsers(ctx, uint64(5), false, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uint64, _ bool, send func(models.UserEvent) error) error {
+			return send(models.UserEvent{
+				Sequence:   6,
+				Type:       models.EventUserCreated,
+				User:       models.User{ID: newUUID, UserName: "watch", Password: "$2a$10$hash"},
+				OccurredAt: occurredAt,
+			})
+		})
+	service.EXPECT().WatchUsers(ctx, uint64(1), false, gomock.Any()).Return(app.ErrSequenceExpired)
+	server := NewServer(service, logg)
+
+	stream := &watchStreamMock{ctx: ctx}
+	err = server.WatchUsers(&pb.WatchUsersRequest{AfterSequence: 5}, stream)
+	require.NoError(t, err)
+	require.Equal(t, []*pb.UserEvent{{
+		Sequence:   6,
+		Type:       pb.UserEventType_USER_EVENT_TYPE_CREATED,
+		User:       &pb.User{Id: newUUID, Username: "watch"},
+		OccurredAt: timestamppb.New(occurredAt),
+	}}, stream.events, "events do not carry password hashes")
+
+	err = server.WatchUsers(&pb.WatchUsersRequest{AfterSequence: 1}, &watchStreamMock{ctx: ctx})
+	require.Equal(t, codes.OutOfRange, status.Code(err))
+
+	err = server.WatchUsers(&pb.WatchUsersRequest{}, &watchStreamMock{ctx: notAdminCtx})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestBasicAuthStreamInterceptor(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	credentials := base64.StdEncoding.EncodeToString([]byte("admin:password"))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic "+credentials))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(true, nil)
+	server := NewServer(service, logg)
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		isAdmin, ok := stream.Context().Value(contextValue("isAdmin")).(bool)
+		require.True(t, ok)
+		require.True(t, isAdmin)
+		return nil
+	}
+
+	err = server.BasicAuthStreamInterceptor(nil, &watchStreamMock{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+	require.NoError(t, err)
+}
+
+func TestReflectionAdminOnly(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	info := &grpc.StreamServerInfo{FullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}
+	handler := func(srv interface{}, stream grpc.ServerStream) error { return nil }
+	credentials := base64.StdEncoding.EncodeToString([]byte("admin:password"))
+	adminCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic "+credentials))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(true, nil)
+	server := NewServer(service, logg)
+
+	err = server.BasicAuthStreamInterceptor(nil, &watchStreamMock{ctx: context.Background()}, info, handler)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = server.BasicAuthStreamInterceptor(nil, &watchStreamMock{ctx: adminCtx}, info, handler)
+	require.NoError(t, err)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	type mockBehavior func(s *serviceMocks.MockServiceInterface)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+
+	testTable := []struct {
+		name           string
+		inputData      *pb.CreateWebhookRequest
+		requestContext context.Context
+		mockBehavior   mockBehavior
+		expectedCode   codes.Code
+	}{
+		{
+			name: "successful",
+			inputData: &pb.CreateWebhookRequest{
+				Url:        "https://crm.example.com/hooks/users",
+				EventTypes: []pb.UserEventType{pb.UserEventType_USER_EVENT_TYPE_CREATED},
+			},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CreateWebhook(ctx, &models.Webhook{
+					URL:        "https://crm.example.com/hooks/users",
+					EventTypes: []models.UserEventType{models.EventUserCreated},
+				}).Return(&models.Webhook{
+					ID:         uuid.New().String(),
+					URL:        "https://crm.example.com/hooks/users",
+					EventTypes: []models.UserEventType{models.EventUserCreated},
+					Secret:     "generated",
+				}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:           "not admin request",
+			inputData:      &pb.CreateWebhookRequest{Url: "https://crm.example.com/hooks/users"},
+			requestContext: notAdminCtx,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface) {},
+			expectedCode:   codes.PermissionDenied,
+		},
+		{
+			name:           "webhooks disabled",
+			inputData:      &pb.CreateWebhookRequest{Url: "https://crm.example.com/hooks/users"},
+			requestContext: ctx,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CreateWebhook(ctx, gomock.Any()).Return(nil, app.ErrWebhooksDisabled)
+			},
+			expectedCode: codes.Unimplemented,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service)
+			server := NewServer(service, logg)
+
+			resp, err := server.CreateWebhook(testCase.requestContext, testCase.inputData)
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+			if testCase.expectedCode == codes.OK {
+				require.Equal(t, "generated", resp.Secret)
+				require.Equal(t, testCase.inputData.EventTypes, resp.Webhook.EventTypes)
+			}
+		})
+	}
+}
+
+type authMetricsMock struct {
+	observed []string
+}
+
+func (m *authMetricsMock) ObserveAuth(success bool, reason string) {
+	m.observed = append(m.observed, fmt.Sprintf("%t:%s", success, reason))
+}
+
+func TestBasicAuthInterceptorMetrics(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.UserService_GetUsers_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	withCredentials := func(credentials string) context.Context {
+		header := "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", header))
+	}
+	adminCtx := withCredentials("admin:password")
+	userCtx := withCredentials("user:password")
+	wrongCtx := withCredentials("admin:wrong")
+	suspendedCtx := withCredentials("suspended:password")
+	malformedCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Digest token"))
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().CheckPassword(gomock.Any(), "admin", "password").Return(true, nil)
+	service.EXPECT().CheckPassword(gomock.Any(), "user", "password").Return(false, app.ErrNotAdmin)
+	service.EXPECT().CheckPassword(gomock.Any(), "admin", "wrong").Return(false, app.ErrPasswordMismatch)
+	service.EXPECT().CheckPassword(gomock.Any(), "suspended", "password").Return(false, app.ErrUserSuspended)
+
+	recorder := &authMetricsMock{}
+	server := NewServer(service, logg)
+	server.SetMetrics(recorder)
+
+	for _, ctx := range []context.Context{context.Background(), malformedCtx, adminCtx, userCtx, wrongCtx, suspendedCtx} {
+		_, _ = server.BasicAuthInterceptor(ctx, nil, info, handler)
+	}
+
+	require.Equal(t, []string{
+		"false:anonymous",
+		"false:malformed_header",
+		"true:admin",
+		"false:not_admin",
+		"false:invalid_credentials",
+		"false:user_suspended",
+	}, recorder.observed)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextValue("isAdmin"), true)
+	notAdminCtx := context.WithValue(context.Background(), contextValue("isAdmin"), false)
+
+	testTable := []struct {
+		name          string
+		inputData     *pb.SetLogLevelRequest
+		ctx           context.Context
+		withLevels    bool
+		expectedLevel string
+		expectedCode  codes.Code
+	}{
+		{
+			name:          "permanent",
+			inputData:     &pb.SetLogLevelRequest{Level: "debug"},
+			ctx:           ctx,
+			withLevels:    true,
+			expectedLevel: "DEBUG",
+		},
+		{
+			name:          "temporary override",
+			inputData:     &pb.SetLogLevelRequest{Level: "debug", Ttl: durationpb.New(time.Hour)},
+			ctx:           ctx,
+			withLevels:    true,
+			expectedLevel: "DEBUG",
+		},
+		{
+			name:         "invalid level",
+			inputData:    &pb.SetLogLevelRequest{Level: "loud"},
+			ctx:          ctx,
+			withLevels:   true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "negative ttl",
+			inputData:    &pb.SetLogLevelRequest{Level: "debug", Ttl: durationpb.New(-time.Minute)},
+			ctx:          ctx,
+			withLevels:   true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "not admin",
+			inputData:    &pb.SetLogLevelRequest{Level: "debug"},
+			ctx:          notAdminCtx,
+			withLevels:   true,
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "not configured",
+			inputData:    &pb.SetLogLevelRequest{Level: "debug"},
+			ctx:          ctx,
+			expectedCode: codes.Unimplemented,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			logg, err := logger.GetLogger("INFO")
+			require.NoError(t, err)
+			server := NewServer(nil, logg)
+			if testCase.withLevels {
+				server.SetLogLevels(logg)
+			}
+
+			result, err := server.SetLogLevel(testCase.ctx, testCase.inputData)
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+			if testCase.expectedCode != codes.OK {
+				return
+			}
+
+			require.Equal(t, testCase.expectedLevel, result.Level)
+			require.Equal(t, testCase.inputData.Ttl != nil, result.OverrideExpiresAt != nil)
+
+			state, err := server.GetLogLevel(testCase.ctx, nil)
+			require.NoError(t, err)
+			require.Equal(t, result.Level, state.Level)
+			require.Equal(t, result.DefaultLevel, state.DefaultLevel)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"regexp"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TenantHeader selects the tenant of a request. When it is missing, the tenant is the one of the bearer
+// token, else the default one. The credentials are checked in the selected tenant, so a user can only act
+// in its own one, except for a super-admin, and anonymous requests are served in the default tenant only.
+// models.AllTenants ("*") selects every tenant and is reserved to a super-admin.
+const TenantHeader = "x-tenant-id"
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidTenantID reports whether a single tenant can be selected by tenantID.
+func ValidTenantID(tenantID string) bool {
+	return tenantPattern.MatchString(tenantID)
+}
+
+// tenantFromMetadata returns the tenant of the header, empty when it is missing.
+func tenantFromMetadata(ctx context.Context) (string, error) {
+	values := metadata.ValueFromIncomingContext(ctx, TenantHeader)
+	if len(values) == 0 || values[0] == "" {
+		return "", nil
+	}
+
+	tenantID := values[0]
+	if tenantID != models.AllTenants && !tenantPattern.MatchString(tenantID) {
+		return "", status.Errorf(codes.InvalidArgument,
+			"invalid %s: must be %s or match %s", TenantHeader, models.AllTenants, tenantPattern.String())
+	}
+
+	return tenantID, nil
+}
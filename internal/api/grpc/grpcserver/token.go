@@ -0,0 +1,91 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+var (
+	errTokensDisabled = errors.New("bearer tokens are not accepted")
+	errInvalidToken   = errors.New("invalid bearer token")
+	errTokenExpired   = errors.New("bearer token has expired")
+)
+
+// tokenClaims are the claims of a bearer token, an HS256 JWT issued by an identity provider that shares
+// the token key. Tenant is the home tenant of the subject, the default one when missing.
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	Tenant    string `json:"tenant"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// SetTokenKey enables bearer tokens signed with key, only Basic credentials are accepted without it.
+func (s *Server) SetTokenKey(key []byte) {
+	s.tokenKey = key
+}
+
+// parseToken verifies the signature and the expiry of the token, exp is required.
+func parseToken(key []byte, token string, now time.Time) (tokenClaims, error) {
+	if len(key) == 0 {
+		return tokenClaims{}, errTokensDisabled
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := decodeTokenSegment(parts[0], &header); err != nil || header.Algorithm != "HS256" {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	var claims tokenClaims
+	if err = decodeTokenSegment(parts[1], &claims); err != nil || claims.Subject == "" {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	if claims.Tenant == "" {
+		claims.Tenant = models.DefaultTenant
+	}
+
+	if !tenantPattern.MatchString(claims.Tenant) {
+		return tokenClaims{}, errInvalidToken
+	}
+
+	if claims.ExpiresAt == 0 || !now.Before(time.Unix(claims.ExpiresAt, 0)) {
+		return tokenClaims{}, errTokenExpired
+	}
+
+	return claims, nil
+}
+
+func decodeTokenSegment(segment string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,190 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testTokenKey = []byte("token-key")
+
+func signToken(t *testing.T, key []byte, algorithm string, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseToken(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	exp := now.Add(time.Hour).Unix()
+
+	testTable := []struct {
+		name           string
+		key            []byte
+		token          string
+		expectedClaims tokenClaims
+		expectedError  error
+	}{
+		{
+			name:           "valid",
+			key:            testTokenKey,
+			token:          signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "tenant": "acme", "exp": exp}),
+			expectedClaims: tokenClaims{Subject: "alice", Tenant: "acme", ExpiresAt: exp},
+		},
+		{
+			name:           "default tenant",
+			key:            testTokenKey,
+			token:          signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "exp": exp}),
+			expectedClaims: tokenClaims{Subject: "alice", Tenant: models.DefaultTenant, ExpiresAt: exp},
+		},
+		{
+			name:          "tokens disabled",
+			token:         signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "exp": exp}),
+			expectedError: errTokensDisabled,
+		},
+		{
+			name:          "wrong key",
+			key:           testTokenKey,
+			token:         signToken(t, []byte("other-key"), "HS256", map[string]interface{}{"sub": "alice", "exp": exp}),
+			expectedError: errInvalidToken,
+		},
+		{
+			name:          "other algorithm",
+			key:           testTokenKey,
+			token:         signToken(t, testTokenKey, "none", map[string]interface{}{"sub": "alice", "exp": exp}),
+			expectedError: errInvalidToken,
+		},
+		{
+			name:          "every tenant",
+			key:           testTokenKey,
+			token:         signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "tenant": "*", "exp": exp}),
+			expectedError: errInvalidToken,
+		},
+		{
+			name:          "expired",
+			key:           testTokenKey,
+			token:         signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "exp": now.Unix()}),
+			expectedError: errTokenExpired,
+		},
+		{
+			name:          "without expiry",
+			key:           testTokenKey,
+			token:         signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice"}),
+			expectedError: errTokenExpired,
+		},
+		{
+			name:          "malformed",
+			key:           testTokenKey,
+			token:         "token",
+			expectedError: errInvalidToken,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			claims, err := parseToken(testCase.key, testCase.token, now)
+			require.ErrorIs(t, err, testCase.expectedError)
+			require.Equal(t, testCase.expectedClaims, claims)
+		})
+	}
+}
+
+func TestBearerTokenTenant(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	exp := time.Now().Add(time.Hour).Unix()
+	acmeToken := signToken(t, testTokenKey, "HS256", map[string]interface{}{"sub": "alice", "tenant": "acme", "exp": exp})
+
+	testTable := []struct {
+		name           string
+		metadata       metadata.MD
+		mockBehavior   func(s *serviceMocks.MockServiceInterface)
+		expectedCode   codes.Code
+		expectedTenant string
+	}{
+		{
+			name:           "tenant of the token",
+			metadata:       metadata.Pairs("authorization", "Bearer "+acmeToken),
+			expectedTenant: "acme",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().AuthorizeToken(gomock.Any(), "acme", "alice").
+					DoAndReturn(func(ctx context.Context, _, _ string) (bool, error) {
+						require.Equal(t, "acme", app.TenantFromContext(ctx))
+						return false, app.ErrNotAdmin
+					})
+			},
+		},
+		{
+			name:     "other tenant without super-admin",
+			metadata: metadata.Pairs("authorization", "Bearer "+acmeToken, TenantHeader, "globex"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().AuthorizeToken(gomock.Any(), "acme", "alice").
+					DoAndReturn(func(ctx context.Context, _, _ string) (bool, error) {
+						require.Equal(t, "globex", app.TenantFromContext(ctx))
+						return false, app.ErrCrossTenant
+					})
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "invalid token",
+			metadata:     metadata.Pairs("authorization", "Bearer "+acmeToken+"x"),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unknown subject",
+			metadata: metadata.Pairs("authorization", "Bearer "+acmeToken),
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().AuthorizeToken(gomock.Any(), "acme", "alice").Return(false, app.ErrPasswordMismatch)
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			service := serviceMocks.NewMockServiceInterface(c)
+			testCase.mockBehavior(service)
+			server := NewServer(service, logg)
+			server.SetTokenKey(testTokenKey)
+
+			var tenantID string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				tenantID = app.TenantFromContext(ctx)
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), testCase.metadata)
+			_, err := server.BasicAuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.Equal(t, testCase.expectedCode, status.Code(err))
+			require.Equal(t, testCase.expectedTenant, tenantID)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// patterns caches the compiled (validate.rules).pattern expressions, they come from the compiled-in
+// descriptors so an invalid one is a programming error reported as a violation.
+var patterns sync.Map
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validateRequest(m)
+}
+
+// ValidationUnaryInterceptor rejects requests breaking the (validate.rules) options of api/validate.proto
+// with codes.InvalidArgument listing every violation, the handler is not called.
+func ValidationUnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// ValidationStreamInterceptor validates every message the handler receives from the stream.
+func ValidationStreamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &validatingStream{ServerStream: ss})
+}
+
+func validateRequest(req interface{}) error {
+	message, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	violations := validateMessage(message.ProtoReflect(), "")
+	if len(violations) == 0 {
+		return nil
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid request: "+violations[0].Description).
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request: "+violations[0].Description)
+	}
+
+	return st.Err()
+}
+
+func validateMessage(message protoreflect.Message, prefix string) []*errdetails.BadRequest_FieldViolation {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0)
+
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		path := prefix + field.JSONName()
+		value := message.Get(field)
+
+		rules, _ := proto.GetExtension(field.Options(), pb.E_Rules).(*pb.FieldRules)
+		if rules != nil {
+			violations = append(violations, checkField(field, message.Has(field), value, rules, path)...)
+		}
+
+		// nested requests, e.g. the items of a batch, carry their own rules
+		switch {
+		case field.IsList() && field.Kind() == protoreflect.MessageKind:
+			list := value.List()
+			for j := 0; j < list.Len(); j++ {
+				violations = append(violations, validateMessage(list.Get(j).Message(), fmt.Sprintf("%s[%d].", path, j))...)
+			}
+		case !field.IsMap() && !field.IsList() && field.Kind() == protoreflect.MessageKind && message.Has(field):
+			violations = append(violations, validateMessage(value.Message(), path+".")...)
+		}
+	}
+
+	return violations
+}
+
+func checkField(
+	field protoreflect.FieldDescriptor, set bool, value protoreflect.Value, rules *pb.FieldRules, path string,
+) []*errdetails.BadRequest_FieldViolation {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0)
+	violate := func(path, format string, args ...interface{}) {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       path,
+			Description: path + " " + fmt.Sprintf(format, args...),
+		})
+	}
+
+	switch {
+	case field.IsList():
+		list := value.List()
+		if rules.GetRequired() && list.Len() == 0 {
+			violate(path, "is required")
+		}
+
+		if rules.GetMaxItems() > 0 && list.Len() > int(rules.GetMaxItems()) {
+			violate(path, "must have at most %d items", rules.GetMaxItems())
+		}
+
+		if field.Kind() == protoreflect.StringKind {
+			for i := 0; i < list.Len(); i++ {
+				checkString(list.Get(i).String(), rules, fmt.Sprintf("%s[%d]", path, i), violate)
+			}
+		}
+	case field.IsMap():
+		if rules.GetRequired() && value.Map().Len() == 0 {
+			violate(path, "is required")
+		}
+
+		if rules.GetMaxItems() > 0 && value.Map().Len() > int(rules.GetMaxItems()) {
+			violate(path, "must have at most %d items", rules.GetMaxItems())
+		}
+	case field.Kind() == protoreflect.StringKind:
+		if rules.GetRequired() && value.String() == "" {
+			violate(path, "is required")
+		}
+
+		checkString(value.String(), rules, path, violate)
+	case field.Kind() == protoreflect.Uint32Kind || field.Kind() == protoreflect.Uint64Kind:
+		if rules.GetMax() > 0 && value.Uint() > rules.GetMax() {
+			violate(path, "must be at most %d", rules.GetMax())
+		}
+	case field.Kind() == protoreflect.MessageKind:
+		if rules.GetRequired() && !set {
+			violate(path, "is required")
+		}
+	}
+
+	return violations
+}
+
+// checkString applies the string rules to non-empty values, emptiness is the business of required.
+func checkString(value string, rules *pb.FieldRules, path string, violate func(path, format string, args ...interface{})) {
+	if value == "" {
+		return
+	}
+
+	length := utf8.RuneCountInString(value)
+	if rules.GetMinLen() > 0 && length < int(rules.GetMinLen()) {
+		violate(path, "must be at least %d characters", rules.GetMinLen())
+	}
+
+	if rules.GetMaxLen() > 0 && length > int(rules.GetMaxLen()) {
+		violate(path, "must be at most %d characters", rules.GetMaxLen())
+	}
+
+	if rules.GetUuid() {
+		if _, err := uuid.Parse(value); err != nil {
+			violate(path, "must be a UUID")
+		}
+	}
+
+	if rules.GetPattern() != "" {
+		re, err := compilePattern(rules.GetPattern())
+		switch {
+		case err != nil:
+			violate(path, "has an invalid pattern rule: %v", err)
+		case !re.MatchString(value):
+			violate(path, "must match %s", rules.GetPattern())
+		}
+	}
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Store(pattern, re)
+
+	return re, nil
+}
@@ -0,0 +1,131 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	testTable := []struct {
+		name               string
+		request            proto.Message
+		expectedViolations []string
+	}{
+		{
+			name:    "valid create",
+			request: &pb.CreateUserRequest{Email: "test@gmail.com", Username: "test", Password: "password"},
+		},
+		{
+			name:               "missing fields",
+			request:            &pb.CreateUserRequest{Email: "test@gmail.com"},
+			expectedViolations: []string{"username", "password"},
+		},
+		{
+			name:               "too long password",
+			request:            &pb.CreateUserRequest{Email: "test@gmail.com", Username: "test", Password: strings.Repeat("p", 73)},
+			expectedViolations: []string{"password"},
+		},
+		{
+			name:               "invalid id",
+			request:            &pb.GetUserByIdRequest{Id: "42"},
+			expectedViolations: []string{"id"},
+		},
+		{
+			name:    "empty fields of an update are unchanged",
+			request: &pb.ChangeUserRequest{Id: uuid.NewString()},
+		},
+		{
+			name:               "limit over maximum",
+			request:            &pb.GetUsersRequest{Limit: 1001},
+			expectedViolations: []string{"limit"},
+		},
+		{
+			name: "nested batch items",
+			request: &pb.BatchCreateUsersRequest{Users: []*pb.CreateUserRequest{
+				{Email: "test@gmail.com", Username: "test", Password: "password"},
+				{Email: "test@gmail.com", Password: "password"},
+			}},
+			expectedViolations: []string{"users[1].username"},
+		},
+		{
+			name:               "list items",
+			request:            &pb.BatchDeleteUsersRequest{Ids: []string{uuid.NewString(), "42"}},
+			expectedViolations: []string{"ids[1]"},
+		},
+		{
+			name:               "empty batch",
+			request:            &pb.BatchDeleteUsersRequest{},
+			expectedViolations: []string{"ids"},
+		},
+		{
+			name:               "pattern",
+			request:            &pb.CreateWebhookRequest{Url: "ftp://example.com"},
+			expectedViolations: []string{"url"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			called := false
+			_, err := ValidationUnaryInterceptor(context.Background(), testCase.request, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+
+			if len(testCase.expectedViolations) == 0 {
+				require.NoError(t, err)
+				require.True(t, called)
+
+				return
+			}
+
+			require.False(t, called)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			details := status.Convert(err).Details()
+			require.Len(t, details, 1)
+
+			fields := make([]string, 0)
+			for _, violation := range details[0].(*errdetails.BadRequest).FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+			require.Equal(t, testCase.expectedViolations, fields)
+		})
+	}
+}
+
+type recvStreamMock struct {
+	watchStreamMock
+	request proto.Message
+}
+
+func (s *recvStreamMock) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.request)
+	return nil
+}
+
+func TestValidationStreamInterceptor(t *testing.T) {
+	stream := &recvStreamMock{watchStreamMock: watchStreamMock{ctx: context.Background()}, request: &pb.WatchUsersRequest{}}
+
+	err := ValidationStreamInterceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		return ss.RecvMsg(&pb.WatchUsersRequest{})
+	})
+	require.NoError(t, err)
+
+	err = ValidationStreamInterceptor(nil, &recvStreamMock{request: &pb.BatchDeleteUsersRequest{}}, &grpc.StreamServerInfo{},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			return ss.RecvMsg(&pb.BatchDeleteUsersRequest{})
+		})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,57 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s Server) WatchUsers(req *pb.WatchUsersRequest, stream pb.UserService_WatchUsersServer) error {
+	ctx := stream.Context()
+
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	err := s.service.WatchUsers(ctx, req.AfterSequence, req.FromNow, func(event models.UserEvent) error {
+		return stream.Send(convertEvent(event))
+	})
+
+	switch {
+	case errors.Is(err, app.ErrSequenceExpired):
+		return status.Error(codes.OutOfRange, err.Error())
+	case ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	default:
+		return err
+	}
+}
+
+func convertEvent(event models.UserEvent) *pb.UserEvent {
+	return &pb.UserEvent{
+		Sequence:   event.Sequence,
+		Type:       convertEventType(event.Type),
+		User:       convertWithoutPassword(event.User),
+		OccurredAt: timestamppb.New(event.OccurredAt),
+	}
+}
+
+func convertEventType(eventType models.UserEventType) pb.UserEventType {
+	switch eventType {
+	case models.EventUserCreated:
+		return pb.UserEventType_USER_EVENT_TYPE_CREATED
+	case models.EventUserUpdated:
+		return pb.UserEventType_USER_EVENT_TYPE_UPDATED
+	case models.EventUserDeleted:
+		return pb.UserEventType_USER_EVENT_TYPE_DELETED
+	default:
+		return pb.UserEventType_USER_EVENT_TYPE_UNSPECIFIED
+	}
+}
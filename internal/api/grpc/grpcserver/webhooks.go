@@ -0,0 +1,149 @@
+package grpcserver
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s Server) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.CreateWebhookResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	eventTypes := make([]models.UserEventType, 0, len(req.EventTypes))
+	for _, eventType := range req.EventTypes {
+		eventTypes = append(eventTypes, convertEventTypeFromPb(eventType))
+	}
+
+	webhook, err := s.service.CreateWebhook(ctx, &models.Webhook{
+		URL:        req.Url,
+		EventTypes: eventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	return &pb.CreateWebhookResponse{Webhook: convertWebhook(*webhook), Secret: webhook.Secret}, nil
+}
+
+func (s Server) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if err := s.service.DeleteWebhook(ctx, req.Id); err != nil {
+		return nil, webhookError(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s Server) GetWebhooks(ctx context.Context, _ *empty.Empty) (*pb.GetWebhooksResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	webhooks, err := s.service.GetWebhooks(ctx)
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	pbWebhooks := make([]*pb.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		pbWebhooks = append(pbWebhooks, convertWebhook(webhook))
+	}
+
+	return &pb.GetWebhooksResponse{Webhooks: pbWebhooks}, nil
+}
+
+func (s Server) GetDeadLetters(ctx context.Context, req *pb.GetDeadLettersRequest) (*pb.GetDeadLettersResponse, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	deliveries, count, err := s.service.GetDeadLetters(ctx, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, webhookError(err)
+	}
+
+	pbDeliveries := make([]*pb.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		pbDeliveries = append(pbDeliveries, &pb.WebhookDelivery{
+			Id:        delivery.ID,
+			WebhookId: delivery.WebhookID,
+			Event:     convertEvent(delivery.Event),
+			Attempts:  int32(delivery.Attempts),
+			LastError: delivery.LastError,
+			CreatedAt: timestamppb.New(delivery.CreatedAt),
+			TenantId:  delivery.TenantID,
+		})
+	}
+
+	return &pb.GetDeadLettersResponse{
+		Deliveries:      pbDeliveries,
+		TotalDeliveries: int32(count),
+	}, nil
+}
+
+func (s Server) RetryDeadLetter(ctx context.Context, req *pb.RetryDeadLetterRequest) (*empty.Empty, error) {
+	isAdmin, ok := ctx.Value(contextValue("isAdmin")).(bool)
+	if !isAdmin || !ok {
+		return nil, status.Error(codes.PermissionDenied, "only admin has access to call this method")
+	}
+
+	if err := s.service.RetryDeadLetter(ctx, req.Id); err != nil {
+		return nil, webhookError(err)
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func webhookError(err error) error {
+	if errors.Is(err, app.ErrWebhooksDisabled) {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+
+	return err
+}
+
+func convertWebhook(webhook models.Webhook) *pb.Webhook {
+	eventTypes := make([]pb.UserEventType, 0, len(webhook.EventTypes))
+	for _, eventType := range webhook.EventTypes {
+		eventTypes = append(eventTypes, convertEventType(eventType))
+	}
+
+	return &pb.Webhook{
+		Id:         webhook.ID,
+		Url:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  timestamppb.New(webhook.CreatedAt),
+		TenantId:   webhook.TenantID,
+	}
+}
+
+func convertEventTypeFromPb(eventType pb.UserEventType) models.UserEventType {
+	switch eventType {
+	case pb.UserEventType_USER_EVENT_TYPE_CREATED:
+		return models.EventUserCreated
+	case pb.UserEventType_USER_EVENT_TYPE_UPDATED:
+		return models.EventUserUpdated
+	case pb.UserEventType_USER_EVENT_TYPE_DELETED:
+		return models.EventUserDeleted
+	default:
+		return models.UserEventType(eventType.String())
+	}
+}
@@ -0,0 +1,188 @@
+package gateway
+
+//nolint:depguard
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+}
+
+// HTTPStatus maps a gRPC code to the HTTP status returned by the gateway.
+func HTTPStatus(code codes.Code) int {
+	if httpStatus, ok := httpStatuses[code]; ok {
+		return httpStatus
+	}
+
+	return http.StatusInternalServerError
+}
+
+func writeMessage(w http.ResponseWriter, msg proto.Message) {
+	body, err := protojson.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "error while marshal response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// writeError renders the google.rpc.Status of err, including its details, e.g.
+// {"code":7,"message":"...","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo",...}]}.
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeStatus(w, st, HTTPStatus(st.Code()))
+}
+
+func writeStatus(w http.ResponseWriter, st *status.Status, httpStatus int) {
+	body, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		body = []byte(fmt.Sprintf(`{"code":%d,"message":%q}`, st.Code(), st.Message()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(body)
+}
+
+// bindQuery sets request fields from query parameters named after the proto or JSON field name.
+// Map entries are passed as "<field>[<key>]=<value>" or "<field>.<key>=<value>", e.g. attributes[department]=sales.
+func bindQuery(msg proto.Message, values url.Values) error {
+	for key, list := range values {
+		for _, value := range list {
+			if err := setField(msg, key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func setField(msg proto.Message, name, value string) error {
+	if strings.HasSuffix(name, "]") {
+		name = strings.Replace(strings.TrimSuffix(name, "]"), "[", ".", 1)
+	}
+
+	name, mapKey, isMapEntry := strings.Cut(name, ".")
+
+	message := msg.ProtoReflect()
+	fields := message.Descriptor().Fields()
+
+	field := fields.ByName(protoreflect.Name(name))
+	if field == nil {
+		field = fields.ByJSONName(name)
+	}
+
+	if field == nil {
+		return fmt.Errorf("unknown parameter %q", name)
+	}
+
+	switch {
+	case field.IsMap():
+		if !isMapEntry || field.MapValue().Kind() != protoreflect.StringKind {
+			return fmt.Errorf("parameter %q must be passed as %s.<key>", name, name)
+		}
+
+		message.Mutable(field).Map().Set(protoreflect.ValueOfString(mapKey).MapKey(), protoreflect.ValueOfString(value))
+
+		return nil
+	case isMapEntry:
+		return fmt.Errorf("unknown parameter %q", name+"."+mapKey)
+	}
+
+	parsed, err := parseValue(field, value)
+	if err != nil {
+		return fmt.Errorf("invalid value of parameter %q: %w", name, err)
+	}
+
+	if field.IsList() {
+		message.Mutable(field).List().Append(parsed)
+	} else {
+		message.Set(field, parsed)
+	}
+
+	return nil
+}
+
+func parseValue(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BoolKind:
+		parsed, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(parsed), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(parsed)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(parsed), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(parsed)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(parsed), err
+	case protoreflect.EnumKind:
+		enumValue := field.Enum().Values().ByName(protoreflect.Name(value))
+		if enumValue == nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown enum value %q", value)
+		}
+
+		return protoreflect.ValueOfEnum(enumValue.Number()), nil
+	case protoreflect.MessageKind:
+		if field.Message().FullName() == "google.protobuf.Timestamp" {
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+
+			return protoreflect.ValueOfMessage(timestamppb.New(parsed).ProtoReflect()), nil
+		}
+
+		if field.Message().FullName() == "google.protobuf.Duration" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+
+			return protoreflect.ValueOfMessage(durationpb.New(parsed).ProtoReflect()), nil
+		}
+	default:
+	}
+
+	return protoreflect.Value{}, fmt.Errorf("unsupported type %s", field.Kind())
+}
@@ -0,0 +1,157 @@
+package gateway
+
+//nolint:depguard
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const maxBodySize = 4 << 20
+
+// Gateway serves UserService over HTTP/JSON. Requests are handed to the gRPC implementation through
+// the same interceptors the gRPC server uses, so authentication and error codes are identical on both transports.
+type Gateway struct {
+	server pb.UserServiceServer
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+	logger app.Logger
+	routes []route
+}
+
+func NewGateway(
+	logger app.Logger, server pb.UserServiceServer, unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor,
+) *Gateway {
+	g := &Gateway{
+		server: server,
+		unary:  unary,
+		stream: stream,
+		logger: logger,
+	}
+	g.routes = g.newRoutes()
+
+	return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments, err := splitPath(r.URL.EscapedPath())
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+
+	pathMatched := false
+	for _, route := range g.routes {
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+
+		pathMatched = true
+		if route.method != r.Method {
+			continue
+		}
+
+		route.serve(w, r, params)
+
+		return
+	}
+
+	if pathMatched {
+		writeStatus(w, status.New(codes.Unimplemented, "method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeError(w, status.Errorf(codes.NotFound, "no route for %s %s", r.Method, r.URL.Path))
+}
+
+// incomingContext exposes HTTP headers as gRPC metadata and the remote address as the peer.
+func incomingContext(r *http.Request) context.Context {
+	md := make(metadata.MD, len(r.Header))
+	for key, values := range r.Header {
+		md.Append(strings.ToLower(key), values...)
+	}
+
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+
+	return ctx
+}
+
+// transportStream lets unary handlers call grpc.SetHeader: header metadata becomes HTTP response headers.
+type transportStream struct {
+	method string
+	header http.Header
+}
+
+func (s *transportStream) SetHeader(md metadata.MD) error {
+	setResponseHeaders(s.header, md)
+	return nil
+}
+
+func (s *transportStream) Method() string                  { return s.method }
+func (s *transportStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+func (s *transportStream) SetTrailer(metadata.MD) error    { return nil }
+
+func setResponseHeaders(header http.Header, md metadata.MD) {
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+}
+
+func decodeRequest(r *http.Request, req proto.Message, body bool, params map[string]string) error {
+	if body {
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "error while reading body: %v", err)
+		}
+
+		if len(data) > 0 {
+			if err = protojson.Unmarshal(data, req); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+			}
+		}
+	} else if err := bindQuery(req, r.URL.Query()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for name, value := range params {
+		if err := setField(req, name, value); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func splitPath(escapedPath string) ([]string, error) {
+	segments := strings.Split(strings.Trim(escapedPath, "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+
+		segments[i] = unescaped
+	}
+
+	return segments, nil
+}
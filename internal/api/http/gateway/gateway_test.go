@@ -0,0 +1,356 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/grpcserver"
+	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/internal/ratelimit"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBehavior func(s *serviceMocks.MockServiceInterface)
+
+func newTestGateway(t *testing.T, behavior mockBehavior) *httptest.Server {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	c := gomock.NewController(t)
+	service := serviceMocks.NewMockServiceInterface(c)
+	behavior(service)
+
+	server := grpcserver.NewServer(service, logg)
+	gateway := httptest.NewServer(NewGateway(logg, server, server.BasicAuthInterceptor, server.BasicAuthStreamInterceptor))
+	t.Cleanup(gateway.Close)
+
+	return gateway
+}
+
+func doRequest(t *testing.T, method, url, body string, admin bool) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if admin {
+		req.SetBasicAuth("admin", "admin")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+
+	return resp, decoded
+}
+
+func TestRoutes(t *testing.T) {
+	newUUID := uuid.New().String()
+	createdAfter := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	admin := func(s *serviceMocks.MockServiceInterface) {
+		s.EXPECT().CheckPassword(gomock.Any(), "admin", "admin").Return(true, nil)
+	}
+
+	testTable := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		admin          bool
+		mockBehavior   mockBehavior
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:   "create user",
+			method: http.MethodPost,
+			path:   "/v1/users",
+			body:   `{"email":"test@gmail.com","username":"testUserName","password":"test","profile":{"displayName":"Test"}}`,
+			admin:  true,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				admin(s)
+				s.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, user *models.User) (*models.User, error) {
+						require.Equal(t, "Test", user.Profile.DisplayName)
+						user.ID = newUUID
+						return user, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "create user without admin rights",
+			method:         http.MethodPost,
+			path:           "/v1/users",
+			body:           `{"email":"test@gmail.com","username":"testUserName","password":"test"}`,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   map[string]interface{}{"code": float64(7), "message": "only admin has access to call this method"},
+		},
+		{
+			name:   "get users with filters",
+			method: http.MethodGet,
+			path:   "/v1/users?limit=10&offset=5&created_after=2024-01-02T03:04:05Z&attributes.department=sales",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().GetUsers(gomock.Any(), models.UsersFilter{
+					Offset:       5,
+					Limit:        10,
+					CreatedAfter: &createdAfter,
+					Attributes:   map[string]string{"department": "sales"},
+				}).Return([]models.User{{ID: newUUID, UserName: "testUserName"}}, 1, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid query parameter",
+			method:         http.MethodGet,
+			path:           "/v1/users?limit=many",
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "update user",
+			method: http.MethodPatch,
+			path:   "/v1/users/" + newUUID,
+			body:   `{"email":"new@gmail.com"}`,
+			admin:  true,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				admin(s)
+				email := "new@gmail.com"
+				s.EXPECT().UpdateUser(gomock.Any(), models.UpdateUserDTO{Email: &email}, newUUID).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   map[string]interface{}{},
+		},
+		{
+			name:   "suspend user",
+			method: http.MethodPost,
+			path:   "/v1/users/" + newUUID + ":suspend",
+			body:   `{"reason":"fraud"}`,
+			admin:  true,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				admin(s)
+				s.EXPECT().SuspendUser(gomock.Any(), newUUID, "fraud").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get user by username",
+			method: http.MethodGet,
+			path:   "/v1/usernames/testUserName",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), "testUserName", false).
+					Return(&models.User{ID: newUUID, UserName: "testUserName"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "add nested group with a cycle",
+			method: http.MethodPost,
+			path:   "/v1/groups/" + newUUID + ":addMember",
+			body:   `{"memberGroupId":"` + newUUID + `"}`,
+			admin:  true,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				admin(s)
+				s.EXPECT().AddGroupMember(gomock.Any(), newUUID, models.GroupMember{Type: models.MemberGroup, ID: newUUID}).
+					Return(app.ErrGroupCycle)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"code": float64(9), "message": app.ErrGroupCycle.Error()},
+		},
+		{
+			name:   "list user groups",
+			method: http.MethodGet,
+			path:   "/v1/users/" + newUUID + "/groups?recursive=true",
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().GetUserGroups(gomock.Any(), newUUID, true).Return([]models.Group{{ID: newUUID, Name: "engineering"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "accept expired invitation",
+			method: http.MethodPost,
+			path:   "/v1/invitations:accept",
+			body:   `{"token":"token","username":"alice","password":"secret"}`,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().AcceptInvitation(gomock.Any(), "token", "alice", "secret").Return(nil, app.ErrInvitationExpired)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   map[string]interface{}{"code": float64(9), "message": app.ErrInvitationExpired.Error()},
+		},
+		{
+			name:   "suspended admin",
+			method: http.MethodDelete,
+			path:   "/v1/users/" + newUUID,
+			admin:  true,
+			mockBehavior: func(s *serviceMocks.MockServiceInterface) {
+				s.EXPECT().CheckPassword(gomock.Any(), "admin", "admin").Return(false, app.ErrUserSuspended)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown route",
+			method:         http.MethodGet,
+			path:           "/v1/roles",
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface) {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodPut,
+			path:           "/v1/users/" + newUUID,
+			mockBehavior:   func(s *serviceMocks.MockServiceInterface) {},
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			gateway := newTestGateway(t, testCase.mockBehavior)
+
+			resp, body := doRequest(t, testCase.method, gateway.URL+testCase.path, testCase.body, testCase.admin)
+			require.Equal(t, testCase.expectedStatus, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			if testCase.expectedBody != nil {
+				require.Equal(t, testCase.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	gateway := newTestGateway(t, func(s *serviceMocks.MockServiceInterface) {
+		s.EXPECT().CheckPassword(gomock.Any(), "admin", "admin").Return(false, app.ErrUserLocked)
+	})
+
+	resp, body := doRequest(t, http.MethodGet, gateway.URL+"/v1/users", "", true)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	details, ok := body["details"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, details, 1)
+	require.Equal(t, "USER_LOCKED", details[0].(map[string]interface{})["reason"])
+}
+
+func TestWatchUsers(t *testing.T) {
+	newUUID := uuid.New().String()
+	gateway := newTestGateway(t, func(s *serviceMocks.MockServiceInterface) {
+		s.EXPECT().CheckPassword(gomock.Any(), "admin", "admin").Return(true, nil)
+		s.EXPECT().WatchUsers(gomock.Any(), uint64(4), false, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint64, _ bool, send func(models.UserEvent) error) error {
+				for sequence := uint64(5); sequence <= 6; sequence++ {
+					event := models.UserEvent{Sequence: sequence, Type: models.EventUserCreated, User: models.User{ID: newUUID}}
+					if err := send(event); err != nil {
+						return err
+					}
+				}
+
+				return app.ErrSequenceExpired
+			})
+	})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, gateway.URL+"/v1/users:watch?after_sequence=4", nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("admin", "admin")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+
+	require.Len(t, lines, 3)
+	require.Equal(t, "5", lines[0]["sequence"])
+	require.Equal(t, "6", lines[1]["sequence"])
+	require.Equal(t, float64(11), lines[2]["error"].(map[string]interface{})["code"])
+}
+
+func TestWatchUsersForbidden(t *testing.T) {
+	gateway := newTestGateway(t, func(s *serviceMocks.MockServiceInterface) {})
+
+	resp, body := doRequest(t, http.MethodGet, gateway.URL+"/v1/users:watch", "", false)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Equal(t, float64(7), body["code"])
+}
+
+func TestRequestID(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	c := gomock.NewController(t)
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return([]models.User{}, 0, nil).Times(2)
+
+	server := grpcserver.NewServer(service, logg)
+	requestLogger := grpcserver.NewRequestLogger(logg)
+	gateway := httptest.NewServer(NewGateway(logg, server,
+		grpcserver.ChainUnaryInterceptors(requestLogger.UnaryInterceptor, server.BasicAuthInterceptor),
+		grpcserver.ChainStreamInterceptors(requestLogger.StreamInterceptor, server.BasicAuthStreamInterceptor),
+	))
+	defer gateway.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, gateway.URL+"/v1/users", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-Id", "client-request-1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "client-request-1", resp.Header.Get("X-Request-Id"))
+
+	resp, _ = doRequest(t, http.MethodGet, gateway.URL+"/v1/users", "", false)
+	_, err = uuid.Parse(resp.Header.Get("X-Request-Id"))
+	require.NoError(t, err)
+
+	resp, _ = doRequest(t, http.MethodGet, gateway.URL+"/v1/users:watch", "", false)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get("X-Request-Id"))
+}
+
+func TestRateLimit(t *testing.T) {
+	logg, err := logger.GetLogger("ERROR")
+	require.NoError(t, err)
+
+	c := gomock.NewController(t)
+	service := serviceMocks.NewMockServiceInterface(c)
+	service.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return([]models.User{}, 0, nil).Times(1)
+
+	server := grpcserver.NewServer(service, logg)
+	limiter := grpcserver.NewRateLimiter(logg, ratelimit.NewMemoryBackend(), ratelimit.Limits{Default: ratelimit.Limit{Rate: 0.01, Burst: 1}})
+	gateway := httptest.NewServer(NewGateway(logg, server,
+		grpcserver.ChainUnaryInterceptors(limiter.UnaryInterceptor, server.BasicAuthInterceptor, limiter.PrincipalUnaryInterceptor),
+		grpcserver.ChainStreamInterceptors(limiter.StreamInterceptor, server.BasicAuthStreamInterceptor, limiter.PrincipalStreamInterceptor),
+	))
+	defer gateway.Close()
+
+	resp, _ := doRequest(t, http.MethodGet, gateway.URL+"/v1/users", "", false)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodGet, gateway.URL+"/v1/users", "", false)
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "100", resp.Header.Get("Retry-After"))
+}
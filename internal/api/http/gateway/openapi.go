@@ -0,0 +1,313 @@
+package gateway
+
+//nolint:depguard
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+//go:generate go run ./openapigen openapi.json
+
+// DocumentPath is where the gateway serves its OpenAPI document.
+const DocumentPath = "/openapi.json"
+
+//go:embed openapi.json
+var document []byte
+
+// anonymousMethods can be called without credentials, every other method requires an admin.
+var anonymousMethods = map[string]bool{
+	pb.UserService_GetUsers_FullMethodName:             true,
+	pb.UserService_GetOneUserByID_FullMethodName:       true,
+	pb.UserService_GetOneUserByUsername_FullMethodName: true,
+	pb.UserService_GetGroup_FullMethodName:             true,
+	pb.UserService_GetGroups_FullMethodName:            true,
+	pb.UserService_ListMembers_FullMethodName:          true,
+	pb.UserService_ListUserGroups_FullMethodName:       true,
+	pb.UserService_AcceptInvitation_FullMethodName:     true,
+}
+
+type object = map[string]interface{}
+
+func serveDocument(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(document)
+}
+
+// OpenAPI builds the OpenAPI 3 document of the gateway from the UserService descriptor compiled from
+// api/user.proto and the route table. The result is embedded as openapi.json; run go generate after
+// changing the proto or the routes.
+func OpenAPI() ([]byte, error) {
+	service := pb.File_user_proto.Services().ByName("UserService")
+	builder := openAPIBuilder{schemas: make(object)}
+	paths := make(object)
+
+	for _, route := range NewGateway(nil, pb.UnimplementedUserServiceServer{}, nil, nil).routes {
+		if route.fullMethod == "" {
+			continue
+		}
+
+		method := service.Methods().ByName(protoreflect.Name(route.fullMethod[strings.LastIndex(route.fullMethod, "/")+1:]))
+
+		item, ok := paths[route.path].(object)
+		if !ok {
+			item = make(object)
+			paths[route.path] = item
+		}
+
+		item[strings.ToLower(route.method)] = builder.operation(route, method)
+	}
+
+	builder.schemas["Status"] = object{
+		"type":        "object",
+		"description": "google.rpc.Status returned for every error, HTTP status is derived from the gRPC code",
+		"properties": object{
+			"code":    object{"type": "integer", "format": "int32"},
+			"message": object{"type": "string"},
+			"details": object{"type": "array", "items": object{
+				"type":                 "object",
+				"properties":           object{"@type": object{"type": "string"}},
+				"additionalProperties": true,
+			}},
+		},
+	}
+
+	return json.MarshalIndent(object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   string(service.FullName()),
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": object{
+			"securitySchemes": object{
+				"basicAuth": object{"type": "http", "scheme": "basic"},
+			},
+			"schemas": builder.schemas,
+		},
+	}, "", "  ")
+}
+
+type openAPIBuilder struct {
+	schemas object
+}
+
+func (b openAPIBuilder) operation(route route, method protoreflect.MethodDescriptor) object {
+	pathParams := make(map[string]bool)
+	parameters := make([]interface{}, 0)
+
+	for _, segment := range route.segments {
+		if !strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		name := segment[1:strings.Index(segment, "}")]
+		pathParams[name] = true
+		parameters = append(parameters, object{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   b.fieldSchema(method.Input().Fields().ByName(protoreflect.Name(name))),
+		})
+	}
+
+	operation := object{
+		"operationId": string(method.Name()),
+		"tags":        []string{string(method.Parent().Name())},
+		"security":    []object{{"basicAuth": []string{}}},
+	}
+
+	if anonymousMethods[route.fullMethod] {
+		operation["security"] = []object{{"basicAuth": []string{}}, {}}
+	}
+
+	if route.method == http.MethodPost || route.method == http.MethodPatch {
+		operation["requestBody"] = object{
+			"required": true,
+			"content":  object{"application/json": object{"schema": b.messageSchema(method.Input())}},
+		}
+	} else {
+		parameters = append(parameters, b.queryParameters(method.Input(), pathParams)...)
+	}
+
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	contentType, description := "application/json", "OK"
+	if method.IsStreamingServer() {
+		contentType, description = "application/x-ndjson", "Stream of messages, one JSON document per line"
+	}
+
+	operation["responses"] = object{
+		"200": object{
+			"description": description,
+			"content":     object{contentType: object{"schema": b.messageSchema(method.Output())}},
+		},
+		"default": object{
+			"description": "Error",
+			"content":     object{"application/json": object{"schema": object{"$ref": "#/components/schemas/Status"}}},
+		},
+	}
+
+	return operation
+}
+
+func (b openAPIBuilder) queryParameters(input protoreflect.MessageDescriptor, pathParams map[string]bool) []interface{} {
+	parameters := make([]interface{}, 0)
+
+	fields := input.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if pathParams[string(field.Name())] {
+			continue
+		}
+
+		parameter := object{
+			"name":   string(field.Name()),
+			"in":     "query",
+			"schema": b.fieldSchema(field),
+		}
+
+		switch {
+		case field.IsMap():
+			parameter["style"] = "deepObject"
+			parameter["explode"] = true
+		case field.Kind() == protoreflect.MessageKind && !isTimestamp(field.Message()) && !isDuration(field.Message()):
+			continue
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
+
+func (b openAPIBuilder) fieldSchema(field protoreflect.FieldDescriptor) object {
+	rules, _ := proto.GetExtension(field.Options(), pb.E_Rules).(*pb.FieldRules)
+
+	switch {
+	case field.IsMap():
+		schema := object{"type": "object", "additionalProperties": b.kindSchema(field.MapValue())}
+		if rules.GetMaxItems() > 0 {
+			schema["maxProperties"] = rules.GetMaxItems()
+		}
+
+		return schema
+	case field.IsList():
+		schema := object{"type": "array", "items": withRules(b.kindSchema(field), rules)}
+		if rules.GetMaxItems() > 0 {
+			schema["maxItems"] = rules.GetMaxItems()
+		}
+
+		return schema
+	default:
+		return withRules(b.kindSchema(field), rules)
+	}
+}
+
+// withRules documents the (validate.rules) of a scalar field, see grpcserver.ValidationUnaryInterceptor.
+func withRules(schema object, rules *pb.FieldRules) object {
+	if rules == nil || schema["type"] == nil {
+		return schema
+	}
+
+	if rules.GetMinLen() > 0 {
+		schema["minLength"] = rules.GetMinLen()
+	}
+
+	if rules.GetMaxLen() > 0 {
+		schema["maxLength"] = rules.GetMaxLen()
+	}
+
+	if rules.GetPattern() != "" {
+		schema["pattern"] = rules.GetPattern()
+	}
+
+	if rules.GetUuid() {
+		schema["format"] = "uuid"
+	}
+
+	if rules.GetMax() > 0 {
+		schema["maximum"] = rules.GetMax()
+	}
+
+	return schema
+}
+
+// kindSchema follows the protojson mapping: 64-bit integers are strings, enums are their names.
+func (b openAPIBuilder) kindSchema(field protoreflect.FieldDescriptor) object {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return object{"type": "string"}
+	case protoreflect.BoolKind:
+		return object{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return object{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return object{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return object{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return object{"type": "string", "format": "uint64"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+
+		return object{"type": "string", "enum": names}
+	case protoreflect.MessageKind:
+		return b.messageSchema(field.Message())
+	default:
+		return object{}
+	}
+}
+
+func (b openAPIBuilder) messageSchema(message protoreflect.MessageDescriptor) object {
+	if isTimestamp(message) {
+		return object{"type": "string", "format": "date-time"}
+	}
+
+	if isDuration(message) {
+		return object{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`, "example": "600s"}
+	}
+
+	name := string(message.Name())
+	if _, exists := b.schemas[name]; !exists {
+		properties := make(object)
+		schema := object{"type": "object", "properties": properties}
+		b.schemas[name] = schema
+
+		required := make([]string, 0)
+		fields := message.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			properties[fields.Get(i).JSONName()] = b.fieldSchema(fields.Get(i))
+
+			if rules, _ := proto.GetExtension(fields.Get(i).Options(), pb.E_Rules).(*pb.FieldRules); rules.GetRequired() {
+				required = append(required, fields.Get(i).JSONName())
+			}
+		}
+
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+func isTimestamp(message protoreflect.MessageDescriptor) bool {
+	return message.FullName() == "google.protobuf.Timestamp"
+}
+
+func isDuration(message protoreflect.MessageDescriptor) bool {
+	return message.FullName() == "google.protobuf.Duration"
+}
@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"github.com/Baraulia/X-Labs_Test/internal/api/serviceMocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIUpToDate(t *testing.T) {
+	generated, err := OpenAPI()
+	require.NoError(t, err)
+
+	require.True(t, bytes.Equal(generated, bytes.TrimSuffix(document, []byte("\n"))),
+		"openapi.json is out of date with api/user.proto or the gateway routes, run go generate ./internal/api/http/gateway")
+}
+
+func TestOpenAPICoversService(t *testing.T) {
+	var parsed struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(document, &parsed))
+
+	operations := make(map[string]bool)
+	for _, item := range parsed.Paths {
+		for _, operation := range item {
+			operations[operation.OperationID] = true
+		}
+	}
+
+	methods := pb.File_user_proto.Services().ByName("UserService").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		require.True(t, operations[string(methods.Get(i).Name())], "%s has no HTTP route", methods.Get(i).Name())
+	}
+
+	require.Len(t, operations, methods.Len())
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	gateway := newTestGateway(t, func(s *serviceMocks.MockServiceInterface) {})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, gateway.URL+DocumentPath, nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, document, body)
+}
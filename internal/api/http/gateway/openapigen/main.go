@@ -0,0 +1,24 @@
+package main
+
+//nolint:depguard
+import (
+	"log"
+	"os"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/http/gateway"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: openapigen <output file>")
+	}
+
+	document, err := gateway.OpenAPI()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = os.WriteFile(os.Args[1], append(document, '\n'), 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
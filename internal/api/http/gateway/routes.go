@@ -0,0 +1,140 @@
+package gateway
+
+//nolint:depguard
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type route struct {
+	method     string
+	path       string
+	segments   []string
+	fullMethod string
+	serve      func(w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// Routes follow resource-oriented naming: custom methods are appended to the resource with a colon,
+// e.g. POST /v1/users/{id}:suspend. Request fields not bound from the path are read from the JSON body
+// for POST and PATCH, and from the query string for GET and DELETE.
+func (g *Gateway) newRoutes() []route {
+	s := g.server
+
+	return []route{
+		{method: http.MethodGet, path: DocumentPath, segments: splitTemplate(DocumentPath), serve: serveDocument},
+		unary(g, http.MethodPost, "/v1/users:batchCreate", pb.UserService_BatchCreateUsers_FullMethodName, s.BatchCreateUsers),
+		unary(g, http.MethodPost, "/v1/users:batchUpdate", pb.UserService_BatchUpdateUsers_FullMethodName, s.BatchUpdateUsers),
+		unary(g, http.MethodPost, "/v1/users:batchDelete", pb.UserService_BatchDeleteUsers_FullMethodName, s.BatchDeleteUsers),
+		g.watchUsers("/v1/users:watch"),
+		unary(g, http.MethodPost, "/v1/users/{id}:undelete", pb.UserService_UndeleteUser_FullMethodName, s.UndeleteUser),
+		unary(g, http.MethodPost, "/v1/users/{id}:purge", pb.UserService_PurgeUser_FullMethodName, s.PurgeUser),
+		unary(g, http.MethodPost, "/v1/users/{id}:suspend", pb.UserService_SuspendUser_FullMethodName, s.SuspendUser),
+		unary(g, http.MethodPost, "/v1/users/{id}:reactivate", pb.UserService_ReactivateUser_FullMethodName, s.ReactivateUser),
+		unary(g, http.MethodGet, "/v1/users", pb.UserService_GetUsers_FullMethodName, s.GetUsers),
+		unary(g, http.MethodPost, "/v1/users", pb.UserService_CreateUser_FullMethodName, s.CreateUser),
+		unary(g, http.MethodGet, "/v1/users/{id}", pb.UserService_GetOneUserByID_FullMethodName, s.GetOneUserByID),
+		unary(g, http.MethodPatch, "/v1/users/{id}", pb.UserService_UpdateUser_FullMethodName, s.UpdateUser),
+		unary(g, http.MethodDelete, "/v1/users/{id}", pb.UserService_DeleteUser_FullMethodName, s.DeleteUser),
+		unary(g, http.MethodGet, "/v1/usernames/{username}", pb.UserService_GetOneUserByUsername_FullMethodName, s.GetOneUserByUsername),
+		unary(g, http.MethodGet, "/v1/users/{user_id}/groups", pb.UserService_ListUserGroups_FullMethodName, s.ListUserGroups),
+		unary(g, http.MethodPost, "/v1/groups/{group_id}:addMember", pb.UserService_AddMember_FullMethodName, s.AddMember),
+		unary(g, http.MethodPost, "/v1/groups/{group_id}:removeMember", pb.UserService_RemoveMember_FullMethodName, s.RemoveMember),
+		unary(g, http.MethodGet, "/v1/groups/{group_id}/members", pb.UserService_ListMembers_FullMethodName, s.ListMembers),
+		unary(g, http.MethodGet, "/v1/groups", pb.UserService_GetGroups_FullMethodName, s.GetGroups),
+		unary(g, http.MethodPost, "/v1/groups", pb.UserService_CreateGroup_FullMethodName, s.CreateGroup),
+		unary(g, http.MethodGet, "/v1/groups/{id}", pb.UserService_GetGroup_FullMethodName, s.GetGroup),
+		unary(g, http.MethodPatch, "/v1/groups/{id}", pb.UserService_UpdateGroup_FullMethodName, s.UpdateGroup),
+		unary(g, http.MethodDelete, "/v1/groups/{id}", pb.UserService_DeleteGroup_FullMethodName, s.DeleteGroup),
+		unary(g, http.MethodPost, "/v1/invitations:accept", pb.UserService_AcceptInvitation_FullMethodName, s.AcceptInvitation),
+		unary(g, http.MethodGet, "/v1/invitations", pb.UserService_GetInvitations_FullMethodName, s.GetInvitations),
+		unary(g, http.MethodPost, "/v1/invitations", pb.UserService_InviteUser_FullMethodName, s.InviteUser),
+		unary(g, http.MethodDelete, "/v1/invitations/{id}", pb.UserService_RevokeInvitation_FullMethodName, s.RevokeInvitation),
+		unary(g, http.MethodGet, "/v1/webhooks", pb.UserService_GetWebhooks_FullMethodName, s.GetWebhooks),
+		unary(g, http.MethodPost, "/v1/webhooks", pb.UserService_CreateWebhook_FullMethodName, s.CreateWebhook),
+		unary(g, http.MethodDelete, "/v1/webhooks/{id}", pb.UserService_DeleteWebhook_FullMethodName, s.DeleteWebhook),
+		unary(g, http.MethodGet, "/v1/deadLetters", pb.UserService_GetDeadLetters_FullMethodName, s.GetDeadLetters),
+		unary(g, http.MethodPost, "/v1/deadLetters/{id}:retry", pb.UserService_RetryDeadLetter_FullMethodName, s.RetryDeadLetter),
+		unary(g, http.MethodGet, "/v1/logLevel", pb.UserService_GetLogLevel_FullMethodName, s.GetLogLevel),
+		unary(g, http.MethodPost, "/v1/logLevel", pb.UserService_SetLogLevel_FullMethodName, s.SetLogLevel),
+	}
+}
+
+func unary[Req, Resp proto.Message](
+	g *Gateway, method, path, fullMethod string, call func(context.Context, Req) (Resp, error),
+) route {
+	body := method == http.MethodPost || method == http.MethodPatch
+	info := &grpc.UnaryServerInfo{Server: g.server, FullMethod: fullMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return call(ctx, req.(Req))
+	}
+
+	return route{
+		method:     method,
+		path:       path,
+		segments:   splitTemplate(path),
+		fullMethod: fullMethod,
+		serve: func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			var zero Req
+			req := zero.ProtoReflect().New().Interface()
+
+			if err := decodeRequest(r, req, body, params); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			ctx := grpc.NewContextWithServerTransportStream(incomingContext(r), &transportStream{method: fullMethod, header: w.Header()})
+			resp, err := g.unary(ctx, req, info, handler)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			writeMessage(w, resp.(proto.Message))
+		},
+	}
+}
+
+func splitTemplate(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+// match compares path segments with the route template. A template segment "{name}" binds the whole
+// request segment, "{name}:verb" binds the part before the custom verb.
+func (rt route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, template := range rt.segments {
+		if !strings.HasPrefix(template, "{") {
+			if template != segments[i] {
+				return nil, false
+			}
+
+			continue
+		}
+
+		name, verb, hasVerb := strings.Cut(strings.TrimPrefix(template, "{"), "}")
+		value := segments[i]
+		if hasVerb && verb != "" {
+			var ok bool
+			if value, ok = strings.CutSuffix(value, verb); !ok {
+				return nil, false
+			}
+		}
+
+		if value == "" {
+			return nil, false
+		}
+
+		params[name] = value
+	}
+
+	return params, true
+}
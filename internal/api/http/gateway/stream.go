@@ -0,0 +1,108 @@
+package gateway
+
+//nolint:depguard
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Baraulia/X-Labs_Test/internal/api/grpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpStream adapts a chunked HTTP response to grpc.ServerStream. Every message is written as one line
+// of newline-delimited JSON; headers go out with the first message, so errors raised before it still
+// get a proper HTTP status.
+type httpStream struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func (s *httpStream) SetHeader(md metadata.MD) error {
+	if !s.started {
+		setResponseHeaders(s.w.Header(), md)
+	}
+
+	return nil
+}
+
+func (s *httpStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+func (s *httpStream) SetTrailer(metadata.MD)          {}
+func (s *httpStream) Context() context.Context        { return s.ctx }
+func (s *httpStream) RecvMsg(interface{}) error       { return io.EOF }
+
+func (s *httpStream) SendMsg(m interface{}) error {
+	body, err := protojson.Marshal(m.(proto.Message))
+	if err != nil {
+		return err
+	}
+
+	if !s.started {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err = s.w.Write(append(body, '\n')); err != nil {
+		return err
+	}
+
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+
+	return nil
+}
+
+type watchUsersStream struct {
+	grpc.ServerStream
+}
+
+func (s watchUsersStream) Send(event *pb.UserEvent) error {
+	return s.SendMsg(event)
+}
+
+func (g *Gateway) watchUsers(path string) route {
+	info := &grpc.StreamServerInfo{FullMethod: pb.UserService_WatchUsers_FullMethodName, IsServerStream: true}
+
+	return route{
+		method:     http.MethodGet,
+		path:       path,
+		segments:   splitTemplate(path),
+		fullMethod: info.FullMethod,
+		serve: func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			req := &pb.WatchUsersRequest{}
+			if err := decodeRequest(r, req, false, params); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			flusher, _ := w.(http.Flusher)
+			stream := &httpStream{ctx: incomingContext(r), w: w, flusher: flusher}
+
+			err := g.stream(g.server, stream, info, func(_ interface{}, ss grpc.ServerStream) error {
+				return g.server.WatchUsers(req, watchUsersStream{ServerStream: ss})
+			})
+			if err == nil || r.Context().Err() != nil {
+				return
+			}
+
+			if !stream.started {
+				writeError(w, err)
+				return
+			}
+
+			// the status line is already sent, so the error is reported as the last line of the stream
+			g.logger.Warn("watch stream over http was interrupted", map[string]interface{}{"error": err})
+			if body, marshalErr := protojson.Marshal(status.Convert(err).Proto()); marshalErr == nil {
+				_, _ = w.Write([]byte(`{"error":` + string(body) + "}\n"))
+			}
+		},
+	}
+}
@@ -12,8 +12,35 @@ type ServiceInterface interface {
 	CreateUser(ctx context.Context, userDTO *models.User) (*models.User, error)
 	UpdateUser(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error
 	DeleteUser(ctx context.Context, userID string) error
-	GetUsers(ctx context.Context, offset, limit int) ([]models.User, int, error)
-	GetOneUserByID(ctx context.Context, userID string) (*models.User, error)
-	GetOneUserByUsername(ctx context.Context, userName string) (*models.User, error)
+	UndeleteUser(ctx context.Context, userID string) error
+	PurgeUser(ctx context.Context, userID string) error
+	SuspendUser(ctx context.Context, userID, reason string) error
+	ReactivateUser(ctx context.Context, userID, reason string) error
+	GetUsers(ctx context.Context, filter models.UsersFilter) ([]models.User, int, error)
+	GetOneUserByID(ctx context.Context, userID string, showDeleted bool) (*models.User, error)
+	GetOneUserByUsername(ctx context.Context, userName string, showDeleted bool) (*models.User, error)
+	BatchCreateUsers(ctx context.Context, users []*models.User, allOrNothing bool) ([]models.BatchResult, error)
+	BatchUpdateUsers(ctx context.Context, updates []models.UserUpdate, allOrNothing bool) ([]models.BatchResult, error)
+	BatchDeleteUsers(ctx context.Context, userIDs []string, allOrNothing bool) ([]models.BatchResult, error)
+	WatchUsers(ctx context.Context, afterSequence uint64, fromNow bool, send func(models.UserEvent) error) error
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetDeadLetters(ctx context.Context, offset, limit int) ([]models.WebhookDelivery, int, error)
+	RetryDeadLetter(ctx context.Context, deliveryID string) error
+	CreateGroup(ctx context.Context, group *models.Group) (*models.Group, error)
+	UpdateGroup(ctx context.Context, groupDTO models.UpdateGroupDTO, groupID string) error
+	DeleteGroup(ctx context.Context, groupID string) error
+	GetGroup(ctx context.Context, groupID string) (*models.Group, error)
+	GetGroups(ctx context.Context, offset, limit int) ([]models.Group, int, error)
+	AddGroupMember(ctx context.Context, groupID string, member models.GroupMember) error
+	RemoveGroupMember(ctx context.Context, groupID string, member models.GroupMember) error
+	GetGroupMembers(ctx context.Context, groupID string, recursive bool) (*models.GroupMembers, error)
+	GetUserGroups(ctx context.Context, userID string, recursive bool) ([]models.Group, error)
+	InviteUser(ctx context.Context, user *models.User) (*models.Invitation, string, error)
+	AcceptInvitation(ctx context.Context, token, userName, password string) (*models.User, error)
+	GetInvitations(ctx context.Context, offset, limit int) ([]models.Invitation, int, error)
+	RevokeInvitation(ctx context.Context, invitationID string) error
 	CheckPassword(ctx context.Context, username, password string) (bool, error)
+	AuthorizeToken(ctx context.Context, homeTenantID, username string) (bool, error)
 }
@@ -35,6 +35,95 @@ func (m *MockServiceInterface) EXPECT() *MockServiceInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockServiceInterface) AcceptInvitation(arg0 context.Context, arg1, arg2, arg3 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockServiceInterfaceMockRecorder) AcceptInvitation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockServiceInterface)(nil).AcceptInvitation), arg0, arg1, arg2, arg3)
+}
+
+// AddGroupMember mocks base method.
+func (m *MockServiceInterface) AddGroupMember(arg0 context.Context, arg1 string, arg2 models.GroupMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGroupMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddGroupMember indicates an expected call of AddGroupMember.
+func (mr *MockServiceInterfaceMockRecorder) AddGroupMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGroupMember", reflect.TypeOf((*MockServiceInterface)(nil).AddGroupMember), arg0, arg1, arg2)
+}
+
+// AuthorizeToken mocks base method.
+func (m *MockServiceInterface) AuthorizeToken(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeToken indicates an expected call of AuthorizeToken.
+func (mr *MockServiceInterfaceMockRecorder) AuthorizeToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeToken", reflect.TypeOf((*MockServiceInterface)(nil).AuthorizeToken), arg0, arg1, arg2)
+}
+
+// BatchCreateUsers mocks base method.
+func (m *MockServiceInterface) BatchCreateUsers(arg0 context.Context, arg1 []*models.User, arg2 bool) ([]models.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreateUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCreateUsers indicates an expected call of BatchCreateUsers.
+func (mr *MockServiceInterfaceMockRecorder) BatchCreateUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreateUsers", reflect.TypeOf((*MockServiceInterface)(nil).BatchCreateUsers), arg0, arg1, arg2)
+}
+
+// BatchDeleteUsers mocks base method.
+func (m *MockServiceInterface) BatchDeleteUsers(arg0 context.Context, arg1 []string, arg2 bool) ([]models.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDeleteUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDeleteUsers indicates an expected call of BatchDeleteUsers.
+func (mr *MockServiceInterfaceMockRecorder) BatchDeleteUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDeleteUsers", reflect.TypeOf((*MockServiceInterface)(nil).BatchDeleteUsers), arg0, arg1, arg2)
+}
+
+// BatchUpdateUsers mocks base method.
+func (m *MockServiceInterface) BatchUpdateUsers(arg0 context.Context, arg1 []models.UserUpdate, arg2 bool) ([]models.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdateUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchUpdateUsers indicates an expected call of BatchUpdateUsers.
+func (mr *MockServiceInterfaceMockRecorder) BatchUpdateUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdateUsers", reflect.TypeOf((*MockServiceInterface)(nil).BatchUpdateUsers), arg0, arg1, arg2)
+}
+
 // CheckPassword mocks base method.
 func (m *MockServiceInterface) CheckPassword(arg0 context.Context, arg1, arg2 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +139,21 @@ func (mr *MockServiceInterfaceMockRecorder) CheckPassword(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPassword", reflect.TypeOf((*MockServiceInterface)(nil).CheckPassword), arg0, arg1, arg2)
 }
 
+// CreateGroup mocks base method.
+func (m *MockServiceInterface) CreateGroup(arg0 context.Context, arg1 *models.Group) (*models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", arg0, arg1)
+	ret0, _ := ret[0].(*models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockServiceInterfaceMockRecorder) CreateGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockServiceInterface)(nil).CreateGroup), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockServiceInterface) CreateUser(arg0 context.Context, arg1 *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +169,35 @@ func (mr *MockServiceInterfaceMockRecorder) CreateUser(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockServiceInterface)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockServiceInterface) CreateWebhook(arg0 context.Context, arg1 *models.Webhook) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockServiceInterfaceMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockServiceInterface)(nil).CreateWebhook), arg0, arg1)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockServiceInterface) DeleteGroup(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockServiceInterfaceMockRecorder) DeleteGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockServiceInterface)(nil).DeleteGroup), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockServiceInterface) DeleteUser(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -79,40 +212,147 @@ func (mr *MockServiceInterfaceMockRecorder) DeleteUser(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockServiceInterface)(nil).DeleteUser), arg0, arg1)
 }
 
+// DeleteWebhook mocks base method.
+func (m *MockServiceInterface) DeleteWebhook(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockServiceInterfaceMockRecorder) DeleteWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockServiceInterface)(nil).DeleteWebhook), arg0, arg1)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockServiceInterface) GetDeadLetters(arg0 context.Context, arg1, arg2 int) ([]models.WebhookDelivery, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockServiceInterfaceMockRecorder) GetDeadLetters(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockServiceInterface)(nil).GetDeadLetters), arg0, arg1, arg2)
+}
+
+// GetGroup mocks base method.
+func (m *MockServiceInterface) GetGroup(arg0 context.Context, arg1 string) (*models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", arg0, arg1)
+	ret0, _ := ret[0].(*models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockServiceInterfaceMockRecorder) GetGroup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockServiceInterface)(nil).GetGroup), arg0, arg1)
+}
+
+// GetGroupMembers mocks base method.
+func (m *MockServiceInterface) GetGroupMembers(arg0 context.Context, arg1 string, arg2 bool) (*models.GroupMembers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupMembers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.GroupMembers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupMembers indicates an expected call of GetGroupMembers.
+func (mr *MockServiceInterfaceMockRecorder) GetGroupMembers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupMembers", reflect.TypeOf((*MockServiceInterface)(nil).GetGroupMembers), arg0, arg1, arg2)
+}
+
+// GetGroups mocks base method.
+func (m *MockServiceInterface) GetGroups(arg0 context.Context, arg1, arg2 int) ([]models.Group, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroups", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Group)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetGroups indicates an expected call of GetGroups.
+func (mr *MockServiceInterfaceMockRecorder) GetGroups(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroups", reflect.TypeOf((*MockServiceInterface)(nil).GetGroups), arg0, arg1, arg2)
+}
+
+// GetInvitations mocks base method.
+func (m *MockServiceInterface) GetInvitations(arg0 context.Context, arg1, arg2 int) ([]models.Invitation, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Invitation)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockServiceInterfaceMockRecorder) GetInvitations(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockServiceInterface)(nil).GetInvitations), arg0, arg1, arg2)
+}
+
 // GetOneUserByID mocks base method.
-func (m *MockServiceInterface) GetOneUserByID(arg0 context.Context, arg1 string) (*models.User, error) {
+func (m *MockServiceInterface) GetOneUserByID(arg0 context.Context, arg1 string, arg2 bool) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneUserByID", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetOneUserByID", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOneUserByID indicates an expected call of GetOneUserByID.
-func (mr *MockServiceInterfaceMockRecorder) GetOneUserByID(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockServiceInterfaceMockRecorder) GetOneUserByID(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByID", reflect.TypeOf((*MockServiceInterface)(nil).GetOneUserByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByID", reflect.TypeOf((*MockServiceInterface)(nil).GetOneUserByID), arg0, arg1, arg2)
 }
 
 // GetOneUserByUsername mocks base method.
-func (m *MockServiceInterface) GetOneUserByUsername(arg0 context.Context, arg1 string) (*models.User, error) {
+func (m *MockServiceInterface) GetOneUserByUsername(arg0 context.Context, arg1 string, arg2 bool) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneUserByUsername", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetOneUserByUsername", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOneUserByUsername indicates an expected call of GetOneUserByUsername.
-func (mr *MockServiceInterfaceMockRecorder) GetOneUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockServiceInterfaceMockRecorder) GetOneUserByUsername(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByUsername", reflect.TypeOf((*MockServiceInterface)(nil).GetOneUserByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByUsername", reflect.TypeOf((*MockServiceInterface)(nil).GetOneUserByUsername), arg0, arg1, arg2)
+}
+
+// GetUserGroups mocks base method.
+func (m *MockServiceInterface) GetUserGroups(arg0 context.Context, arg1 string, arg2 bool) ([]models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserGroups", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserGroups indicates an expected call of GetUserGroups.
+func (mr *MockServiceInterfaceMockRecorder) GetUserGroups(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserGroups", reflect.TypeOf((*MockServiceInterface)(nil).GetUserGroups), arg0, arg1, arg2)
 }
 
 // GetUsers mocks base method.
-func (m *MockServiceInterface) GetUsers(arg0 context.Context, arg1, arg2 int) ([]models.User, int, error) {
+func (m *MockServiceInterface) GetUsers(arg0 context.Context, arg1 models.UsersFilter) ([]models.User, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsers", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "GetUsers", arg0, arg1)
 	ret0, _ := ret[0].([]models.User)
 	ret1, _ := ret[1].(int)
 	ret2, _ := ret[2].(error)
@@ -120,9 +360,152 @@ func (m *MockServiceInterface) GetUsers(arg0 context.Context, arg1, arg2 int) ([
 }
 
 // GetUsers indicates an expected call of GetUsers.
-func (mr *MockServiceInterfaceMockRecorder) GetUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockServiceInterfaceMockRecorder) GetUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockServiceInterface)(nil).GetUsers), arg0, arg1)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockServiceInterface) GetWebhooks(arg0 context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockServiceInterfaceMockRecorder) GetWebhooks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockServiceInterface)(nil).GetWebhooks), arg0)
+}
+
+// InviteUser mocks base method.
+func (m *MockServiceInterface) InviteUser(arg0 context.Context, arg1 *models.User) (*models.Invitation, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteUser", arg0, arg1)
+	ret0, _ := ret[0].(*models.Invitation)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// InviteUser indicates an expected call of InviteUser.
+func (mr *MockServiceInterfaceMockRecorder) InviteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteUser", reflect.TypeOf((*MockServiceInterface)(nil).InviteUser), arg0, arg1)
+}
+
+// PurgeUser mocks base method.
+func (m *MockServiceInterface) PurgeUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockServiceInterfaceMockRecorder) PurgeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockServiceInterface)(nil).PurgeUser), arg0, arg1)
+}
+
+// ReactivateUser mocks base method.
+func (m *MockServiceInterface) ReactivateUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockServiceInterfaceMockRecorder) ReactivateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockServiceInterface)(nil).ReactivateUser), arg0, arg1, arg2)
+}
+
+// RemoveGroupMember mocks base method.
+func (m *MockServiceInterface) RemoveGroupMember(arg0 context.Context, arg1 string, arg2 models.GroupMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveGroupMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveGroupMember indicates an expected call of RemoveGroupMember.
+func (mr *MockServiceInterfaceMockRecorder) RemoveGroupMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroupMember", reflect.TypeOf((*MockServiceInterface)(nil).RemoveGroupMember), arg0, arg1, arg2)
+}
+
+// RetryDeadLetter mocks base method.
+func (m *MockServiceInterface) RetryDeadLetter(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryDeadLetter", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryDeadLetter indicates an expected call of RetryDeadLetter.
+func (mr *MockServiceInterfaceMockRecorder) RetryDeadLetter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDeadLetter", reflect.TypeOf((*MockServiceInterface)(nil).RetryDeadLetter), arg0, arg1)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockServiceInterface) RevokeInvitation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockServiceInterfaceMockRecorder) RevokeInvitation(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockServiceInterface)(nil).GetUsers), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockServiceInterface)(nil).RevokeInvitation), arg0, arg1)
+}
+
+// SuspendUser mocks base method.
+func (m *MockServiceInterface) SuspendUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendUser indicates an expected call of SuspendUser.
+func (mr *MockServiceInterfaceMockRecorder) SuspendUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendUser", reflect.TypeOf((*MockServiceInterface)(nil).SuspendUser), arg0, arg1, arg2)
+}
+
+// UndeleteUser mocks base method.
+func (m *MockServiceInterface) UndeleteUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UndeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UndeleteUser indicates an expected call of UndeleteUser.
+func (mr *MockServiceInterfaceMockRecorder) UndeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndeleteUser", reflect.TypeOf((*MockServiceInterface)(nil).UndeleteUser), arg0, arg1)
+}
+
+// UpdateGroup mocks base method.
+func (m *MockServiceInterface) UpdateGroup(arg0 context.Context, arg1 models.UpdateGroupDTO, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGroup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGroup indicates an expected call of UpdateGroup.
+func (mr *MockServiceInterfaceMockRecorder) UpdateGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGroup", reflect.TypeOf((*MockServiceInterface)(nil).UpdateGroup), arg0, arg1, arg2)
 }
 
 // UpdateUser mocks base method.
@@ -138,3 +521,17 @@ func (mr *MockServiceInterfaceMockRecorder) UpdateUser(arg0, arg1, arg2 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockServiceInterface)(nil).UpdateUser), arg0, arg1, arg2)
 }
+
+// WatchUsers mocks base method.
+func (m *MockServiceInterface) WatchUsers(arg0 context.Context, arg1 uint64, arg2 bool, arg3 func(models.UserEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchUsers indicates an expected call of WatchUsers.
+func (mr *MockServiceInterfaceMockRecorder) WatchUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchUsers", reflect.TypeOf((*MockServiceInterface)(nil).WatchUsers), arg0, arg1, arg2, arg3)
+}
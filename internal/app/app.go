@@ -3,15 +3,26 @@ package app
 //nolint:depguard
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/models"
 )
 
 type App struct {
-	logger    Logger
-	storage   StorageInterface
-	validator Validator
-	SecretKey string
+	logger          Logger
+	storage         StorageInterface
+	webhooks        WebhookStorage
+	validator       Validator
+	attributeSchema AttributeSchema
+	tenantSchemas   map[string]AttributeSchema
+	passwordPolicy  atomic.Pointer[PasswordPolicy]
+	hashWorkers     int
+	maxFailedLogins int
+	invitationTTL   time.Duration
+	metrics         Metrics
+	now             func() time.Time
+	SecretKey       string
 }
 
 type Logger interface {
@@ -22,20 +33,67 @@ type Logger interface {
 	Fatal(msg string, fields map[string]interface{})
 }
 
+type Metrics interface {
+	ObservePasswordHash(duration time.Duration)
+}
+
 type Validator interface {
 	IsEmail(email string) bool
+	IsPhoneNumber(phone string) bool
 }
 
+// StorageInterface scopes every user and group to a tenant: emails, usernames and group names are unique
+// within a tenant and a user or group of another tenant is not found. models.AllTenants reaches every
+// tenant, except for creating users, groups and invitations and looking users up by username, which need
+// a single one. An invitation token selects its own tenant. Ping and the outbox are process-wide.
+//
 //go:generate mockgen -destination mocks/storageMock.go -package mocks github.com/Baraulia/X-Labs_Test/internal/app StorageInterface
 type StorageInterface interface {
-	CreateUser(ctx context.Context, userDTO *models.User) (*models.User, error)
-	UpdateUser(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error
-	DeleteUser(ctx context.Context, userID string) error
-	GetUsers(ctx context.Context, offset, limit int) ([]models.User, int, error)
-	GetOneUserByID(ctx context.Context, userID string) (*models.User, error)
-	GetOneUserByUsername(ctx context.Context, userName string) (*models.User, error)
+	CreateUser(ctx context.Context, tenantID string, userDTO *models.User) (*models.User, error)
+	UpdateUser(ctx context.Context, tenantID string, userDTO models.UpdateUserDTO, userID string) error
+	DeleteUser(ctx context.Context, tenantID, userID string) error
+	UndeleteUser(ctx context.Context, tenantID, userID string) error
+	PurgeUser(ctx context.Context, tenantID, userID string) error
+	PurgeDeletedUsers(ctx context.Context, tenantID string, deletedBefore time.Time) (int, error)
+	UpdateUserStatus(ctx context.Context, tenantID, userID string, from, to models.UserStatus, reason string) error
+	RecordLogin(ctx context.Context, tenantID, userID string) error
+	RecordFailedLogin(ctx context.Context, tenantID, userID string, maxFailedLogins int) (bool, error)
+	BatchCreateUsers(ctx context.Context, tenantID string, users []*models.User, allOrNothing bool) ([]error, error)
+	BatchUpdateUsers(ctx context.Context, tenantID string, updates []models.UserUpdate, allOrNothing bool) ([]error, error)
+	BatchDeleteUsers(ctx context.Context, tenantID string, userIDs []string, allOrNothing bool) ([]error, error)
+	WatchUsers(ctx context.Context, tenantID string, afterSequence uint64, fromNow bool, send func(models.UserEvent) error) error
+	FetchOutbox(ctx context.Context, limit int) ([]models.OutboxRecord, error)
+	MarkOutboxDelivered(ctx context.Context, recordIDs []string) error
+	GetUsers(ctx context.Context, tenantID string, filter models.UsersFilter) ([]models.User, int, error)
+	GetOneUserByID(ctx context.Context, tenantID, userID string, showDeleted bool) (*models.User, error)
+	GetOneUserByUsername(ctx context.Context, tenantID, userName string, showDeleted bool) (*models.User, error)
+	CreateGroup(ctx context.Context, tenantID string, group *models.Group) (*models.Group, error)
+	UpdateGroup(ctx context.Context, tenantID string, groupDTO models.UpdateGroupDTO, groupID string) error
+	DeleteGroup(ctx context.Context, tenantID, groupID string) error
+	GetGroup(ctx context.Context, tenantID, groupID string) (*models.Group, error)
+	GetGroups(ctx context.Context, tenantID string, offset, limit int) ([]models.Group, int, error)
+	AddGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) error
+	RemoveGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) error
+	GetGroupMembers(ctx context.Context, tenantID, groupID string, recursive bool) (*models.GroupMembers, error)
+	GetUserGroups(ctx context.Context, tenantID, userID string, recursive bool) ([]models.Group, error)
+	CreateInvitation(ctx context.Context, tenantID string, user *models.User, invitation *models.Invitation) (*models.Invitation, error)
+	GetInvitations(ctx context.Context, tenantID string, offset, limit int) ([]models.Invitation, int, error)
+	CheckInvitation(ctx context.Context, tokenHash string) error
+	AcceptInvitation(ctx context.Context, tokenHash, userName, password string) (*models.User, error)
+	RevokeInvitation(ctx context.Context, tenantID, invitationID string) error
+	PurgeExpiredInvitations(ctx context.Context, tenantID string, expiredBefore time.Time) (int, error)
+	Ping(ctx context.Context) error
+}
+
+func (a *App) SetMetrics(metrics Metrics) {
+	a.metrics = metrics
 }
 
 func NewApp(logger Logger, storage StorageInterface, validator Validator, secretKey string) *App {
-	return &App{logger: logger, storage: storage, validator: validator, SecretKey: secretKey}
+	return &App{logger: logger, storage: storage, validator: validator, now: time.Now, SecretKey: secretKey}
+}
+
+// SetClock replaces time.Now for the expiry of invitations and the retention of deleted users, e.g. in tests.
+func (a *App) SetClock(now func() time.Time) {
+	a.now = now
 }
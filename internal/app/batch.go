@@ -0,0 +1,264 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+const maxBatchSize = 1000
+
+var ErrBatchAborted = errors.New("batch aborted: another item of the all-or-nothing batch failed")
+
+func (a *App) SetHashWorkers(workers int) {
+	a.hashWorkers = workers
+}
+
+func (a *App) BatchCreateUsers(ctx context.Context, users []*models.User, allOrNothing bool) ([]models.BatchResult, error) {
+	ctx, span := startSpan(ctx, "App.BatchCreateUsers")
+	defer span.End()
+
+	if err := checkBatchSize(len(users)); err != nil {
+		a.log(ctx).Error("invalid batch size", map[string]interface{}{"size": len(users)})
+		return nil, err
+	}
+
+	errs := make([]error, len(users))
+	passwords := make([]*string, len(users))
+	for i, user := range users {
+		if errs[i] = a.validateUser(ctx, user); errs[i] == nil {
+			passwords[i] = &user.Password
+		}
+
+		if user.Status == "" {
+			user.Status = models.StatusActive
+		}
+	}
+
+	if aborted := abortBatch(errs, allOrNothing); aborted {
+		return batchResults(nil, errs), ErrBatchAborted
+	}
+
+	for i, err := range a.hashPasswords(ctx, passwords) {
+		if err != nil {
+			errs[i] = err
+		}
+	}
+
+	if aborted := abortBatch(errs, allOrNothing); aborted {
+		return batchResults(nil, errs), ErrBatchAborted
+	}
+
+	valid := make([]*models.User, 0, len(users))
+	positions := make([]int, 0, len(users))
+	for i, user := range users {
+		if errs[i] == nil {
+			valid = append(valid, user)
+			positions = append(positions, i)
+		}
+	}
+
+	storageErrs, err := a.storage.BatchCreateUsers(ctx, TenantFromContext(ctx), valid, allOrNothing)
+	if storageErrs == nil {
+		return nil, err
+	}
+
+	for i, storageErr := range storageErrs {
+		errs[positions[i]] = storageErr
+	}
+
+	return batchResults(users, errs), err
+}
+
+func (a *App) BatchUpdateUsers(ctx context.Context, updates []models.UserUpdate, allOrNothing bool) ([]models.BatchResult, error) {
+	ctx, span := startSpan(ctx, "App.BatchUpdateUsers")
+	defer span.End()
+
+	if err := checkBatchSize(len(updates)); err != nil {
+		a.log(ctx).Error("invalid batch size", map[string]interface{}{"size": len(updates)})
+		return nil, err
+	}
+
+	errs := make([]error, len(updates))
+	passwords := make([]*string, len(updates))
+	for i, update := range updates {
+		if errs[i] = a.validateUpdate(ctx, update.DTO, update.ID); errs[i] == nil {
+			passwords[i] = update.DTO.Password
+		}
+	}
+
+	if aborted := abortBatch(errs, allOrNothing); aborted {
+		return batchResults(nil, errs), ErrBatchAborted
+	}
+
+	for i, err := range a.hashPasswords(ctx, passwords) {
+		if err != nil {
+			errs[i] = err
+		}
+	}
+
+	if aborted := abortBatch(errs, allOrNothing); aborted {
+		return batchResults(nil, errs), ErrBatchAborted
+	}
+
+	valid := make([]models.UserUpdate, 0, len(updates))
+	positions := make([]int, 0, len(updates))
+	for i, update := range updates {
+		if errs[i] == nil {
+			valid = append(valid, update)
+			positions = append(positions, i)
+		}
+	}
+
+	storageErrs, err := a.storage.BatchUpdateUsers(ctx, TenantFromContext(ctx), valid, allOrNothing)
+	if storageErrs == nil {
+		return nil, err
+	}
+
+	for i, storageErr := range storageErrs {
+		errs[positions[i]] = storageErr
+	}
+
+	return batchResults(nil, errs), err
+}
+
+func (a *App) BatchDeleteUsers(ctx context.Context, userIDs []string, allOrNothing bool) ([]models.BatchResult, error) {
+	ctx, span := startSpan(ctx, "App.BatchDeleteUsers")
+	defer span.End()
+
+	if err := checkBatchSize(len(userIDs)); err != nil {
+		a.log(ctx).Error("invalid batch size", map[string]interface{}{"size": len(userIDs)})
+		return nil, err
+	}
+
+	errs := make([]error, len(userIDs))
+	for i, userID := range userIDs {
+		if _, err := uuid.Parse(userID); err != nil {
+			errs[i] = fmt.Errorf("invalid id(not UUID: %s", userID)
+		}
+	}
+
+	if aborted := abortBatch(errs, allOrNothing); aborted {
+		return batchResults(nil, errs), ErrBatchAborted
+	}
+
+	valid := make([]string, 0, len(userIDs))
+	positions := make([]int, 0, len(userIDs))
+	for i, userID := range userIDs {
+		if errs[i] == nil {
+			valid = append(valid, userID)
+			positions = append(positions, i)
+		}
+	}
+
+	storageErrs, err := a.storage.BatchDeleteUsers(ctx, TenantFromContext(ctx), valid, allOrNothing)
+	if storageErrs == nil {
+		return nil, err
+	}
+
+	for i, storageErr := range storageErrs {
+		errs[positions[i]] = storageErr
+	}
+
+	return batchResults(nil, errs), err
+}
+
+// hashPasswords replaces every non-nil password with its bcrypt hash using a bounded pool of workers.
+func (a *App) hashPasswords(ctx context.Context, passwords []*string) []error {
+	workers := a.hashWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	errs := make([]error, len(passwords))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+
+				hashedPassword, err := a.hashPassword(ctx, *passwords[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+
+				*passwords[i] = hashedPassword
+			}
+		}()
+	}
+
+	for i, password := range passwords {
+		if password != nil {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+func checkBatchSize(size int) error {
+	if size == 0 {
+		return errors.New("empty batch")
+	}
+
+	if size > maxBatchSize {
+		return fmt.Errorf("batch size %d exceeds the limit of %d", size, maxBatchSize)
+	}
+
+	return nil
+}
+
+// abortBatch marks every item as aborted when an all-or-nothing batch contains a failed item.
+func abortBatch(errs []error, allOrNothing bool) bool {
+	if !allOrNothing {
+		return false
+	}
+
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			failed = true
+			break
+		}
+	}
+
+	if !failed {
+		return false
+	}
+
+	for i := range errs {
+		if errs[i] == nil {
+			errs[i] = ErrBatchAborted
+		}
+	}
+
+	return true
+}
+
+func batchResults(users []*models.User, errs []error) []models.BatchResult {
+	results := make([]models.BatchResult, len(errs))
+	for i, err := range errs {
+		results[i].Err = err
+		if err == nil && users != nil {
+			results[i].User = users[i]
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBatchCreateUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+
+	t.Run("best effort", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		storage := mocks.NewMockStorageInterface(c)
+		users := []*models.User{
+			{Email: "batch1@gmail.com", UserName: "batch1", Password: "password1"},
+			{Email: "invalid email", UserName: "batch2", Password: "password2"},
+			{Email: "batch3@gmail.com", UserName: "batch3", Password: "password3"},
+		}
+		storage.EXPECT().BatchCreateUsers(ctx, models.DefaultTenant, []*models.User{users[0], users[2]}, false).
+			Return([]error{nil, errors.New("storage error")}, nil)
+		app := NewApp(logg, storage, validator, "")
+		app.SetHashWorkers(2)
+
+		results, err := app.BatchCreateUsers(ctx, users, false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, users[0], results[0].User)
+		require.Error(t, results[1].Err)
+		require.Error(t, results[2].Err)
+		require.Nil(t, results[2].User)
+
+		require.NoError(t, bcrypt.CompareHashAndPassword([]byte(users[0].Password), []byte("password1")))
+		require.NoError(t, bcrypt.CompareHashAndPassword([]byte(users[2].Password), []byte("password3")))
+		require.Equal(t, "password2", users[1].Password)
+		require.Equal(t, models.StatusActive, users[0].Status)
+	})
+
+	t.Run("all or nothing with invalid item", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		storage := mocks.NewMockStorageInterface(c)
+		users := []*models.User{
+			{Email: "batch1@gmail.com", UserName: "batch1", Password: "password1"},
+			{Email: "batch2@gmail.com", Password: "password2"},
+		}
+		app := NewApp(logg, storage, validator, "")
+
+		results, err := app.BatchCreateUsers(ctx, users, true)
+		require.ErrorIs(t, err, ErrBatchAborted)
+		require.ErrorIs(t, results[0].Err, ErrBatchAborted)
+		require.Error(t, results[1].Err)
+		require.Equal(t, "password1", users[0].Password)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		c := gomock.NewController(t)
+		defer c.Finish()
+		storage := mocks.NewMockStorageInterface(c)
+		app := NewApp(logg, storage, validator, "")
+
+		_, err := app.BatchCreateUsers(ctx, nil, false)
+		require.Error(t, err)
+	})
+}
+
+func TestBatchUpdateUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+	password := "password"
+	updates := []models.UserUpdate{
+		{ID: newUUID, DTO: models.UpdateUserDTO{Password: &password}},
+		{ID: "invalid uuid"},
+	}
+	storage.EXPECT().BatchUpdateUsers(ctx, models.DefaultTenant, updates[:1], false).Return([]error{nil}, nil)
+	app := NewApp(logg, storage, validator, "")
+
+	results, err := app.BatchUpdateUsers(ctx, updates, false)
+	require.NoError(t, err)
+	require.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(password), []byte("password")))
+}
+
+func TestBatchDeleteUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+	newUUID := uuid.New().String()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+	storage.EXPECT().BatchDeleteUsers(ctx, models.DefaultTenant, []string{newUUID}, true).
+		Return([]error{ErrBatchAborted}, ErrBatchAborted)
+	app := NewApp(logg, storage, validator, "")
+
+	results, err := app.BatchDeleteUsers(ctx, []string{newUUID}, true)
+	require.ErrorIs(t, err, ErrBatchAborted)
+	require.ErrorIs(t, results[0].Err, ErrBatchAborted)
+}
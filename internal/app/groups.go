@@ -0,0 +1,140 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+var ErrGroupCycle = errors.New("group membership would be a cycle")
+
+func (a *App) CreateGroup(ctx context.Context, group *models.Group) (*models.Group, error) {
+	ctx, span := startSpan(ctx, "App.CreateGroup")
+	defer span.End()
+
+	if group.Name == "" {
+		a.log(ctx).Error("empty group name", nil)
+		return nil, errors.New("empty group name")
+	}
+
+	return a.storage.CreateGroup(ctx, TenantFromContext(ctx), group)
+}
+
+func (a *App) UpdateGroup(ctx context.Context, groupDTO models.UpdateGroupDTO, groupID string) error {
+	ctx, span := startSpan(ctx, "App.UpdateGroup")
+	defer span.End()
+
+	if err := a.validateID(ctx, groupID); err != nil {
+		return err
+	}
+
+	if groupDTO.Name != nil && *groupDTO.Name == "" {
+		a.log(ctx).Error("empty group name", map[string]interface{}{"id": groupID})
+		return errors.New("empty group name")
+	}
+
+	return a.storage.UpdateGroup(ctx, TenantFromContext(ctx), groupDTO, groupID)
+}
+
+func (a *App) DeleteGroup(ctx context.Context, groupID string) error {
+	ctx, span := startSpan(ctx, "App.DeleteGroup")
+	defer span.End()
+
+	if err := a.validateID(ctx, groupID); err != nil {
+		return err
+	}
+
+	return a.storage.DeleteGroup(ctx, TenantFromContext(ctx), groupID)
+}
+
+func (a *App) GetGroup(ctx context.Context, groupID string) (*models.Group, error) {
+	ctx, span := startSpan(ctx, "App.GetGroup")
+	defer span.End()
+
+	if err := a.validateID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.GetGroup(ctx, TenantFromContext(ctx), groupID)
+}
+
+func (a *App) GetGroups(ctx context.Context, offset, limit int) ([]models.Group, int, error) {
+	ctx, span := startSpan(ctx, "App.GetGroups")
+	defer span.End()
+
+	return a.storage.GetGroups(ctx, TenantFromContext(ctx), offset, limit)
+}
+
+// AddGroupMember adds a user or a nested group to the group, ErrGroupCycle is returned when the nested
+// group already contains the group.
+func (a *App) AddGroupMember(ctx context.Context, groupID string, member models.GroupMember) error {
+	ctx, span := startSpan(ctx, "App.AddGroupMember")
+	defer span.End()
+
+	if err := a.validateMember(ctx, groupID, member); err != nil {
+		return err
+	}
+
+	return a.storage.AddGroupMember(ctx, TenantFromContext(ctx), groupID, member)
+}
+
+func (a *App) RemoveGroupMember(ctx context.Context, groupID string, member models.GroupMember) error {
+	ctx, span := startSpan(ctx, "App.RemoveGroupMember")
+	defer span.End()
+
+	if err := a.validateMember(ctx, groupID, member); err != nil {
+		return err
+	}
+
+	return a.storage.RemoveGroupMember(ctx, TenantFromContext(ctx), groupID, member)
+}
+
+// GetGroupMembers expands the nested groups when recursive is set, so the users of the result are every
+// user the group grants its membership to.
+func (a *App) GetGroupMembers(ctx context.Context, groupID string, recursive bool) (*models.GroupMembers, error) {
+	ctx, span := startSpan(ctx, "App.GetGroupMembers")
+	defer span.End()
+
+	if err := a.validateID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.GetGroupMembers(ctx, TenantFromContext(ctx), groupID, recursive)
+}
+
+func (a *App) GetUserGroups(ctx context.Context, userID string, recursive bool) ([]models.Group, error) {
+	ctx, span := startSpan(ctx, "App.GetUserGroups")
+	defer span.End()
+
+	if err := a.validateID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return a.storage.GetUserGroups(ctx, TenantFromContext(ctx), userID, recursive)
+}
+
+func (a *App) validateMember(ctx context.Context, groupID string, member models.GroupMember) error {
+	if err := a.validateID(ctx, groupID); err != nil {
+		return err
+	}
+
+	if member.Type != models.MemberUser && member.Type != models.MemberGroup {
+		a.log(ctx).Error("invalid member type", map[string]interface{}{"type": member.Type})
+		return fmt.Errorf("invalid member type: %s", member.Type)
+	}
+
+	return a.validateID(ctx, member.ID)
+}
+
+func (a *App) validateID(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		return fmt.Errorf("invalid id(not UUID: %s", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddGroupMember(t *testing.T) {
+	type mockBehavior func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := WithTenant(context.Background(), "acme")
+	groupID := uuid.New().String()
+	memberID := uuid.New().String()
+
+	testTable := []struct {
+		name          string
+		groupID       string
+		member        models.GroupMember
+		mockBehavior  mockBehavior
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:    "user",
+			groupID: groupID,
+			member:  models.GroupMember{Type: models.MemberUser, ID: memberID},
+			mockBehavior: func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember) {
+				s.EXPECT().AddGroupMember(ctx, "acme", groupID, member).Return(nil)
+			},
+		},
+		{
+			name:    "nested group with a cycle",
+			groupID: groupID,
+			member:  models.GroupMember{Type: models.MemberGroup, ID: memberID},
+			mockBehavior: func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember) {
+				s.EXPECT().AddGroupMember(ctx, "acme", groupID, member).Return(ErrGroupCycle)
+			},
+			expectedError: true,
+			errorIs:       ErrGroupCycle,
+		},
+		{
+			name:          "invalid group id",
+			groupID:       "engineering",
+			member:        models.GroupMember{Type: models.MemberUser, ID: memberID},
+			mockBehavior:  func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember) {},
+			expectedError: true,
+		},
+		{
+			name:          "unknown member type",
+			groupID:       groupID,
+			member:        models.GroupMember{Type: "role", ID: memberID},
+			mockBehavior:  func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember) {},
+			expectedError: true,
+		},
+		{
+			name:          "invalid member id",
+			groupID:       groupID,
+			member:        models.GroupMember{Type: models.MemberUser, ID: "alice"},
+			mockBehavior:  func(s *mocks.MockStorageInterface, groupID string, member models.GroupMember) {},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			testCase.mockBehavior(storage, testCase.groupID, testCase.member)
+			app := NewApp(logg, storage, validator, "")
+
+			err := app.AddGroupMember(ctx, testCase.groupID, testCase.member)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if testCase.errorIs != nil {
+				require.ErrorIs(t, err, testCase.errorIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+const (
+	invitationTokenSize  = 32
+	defaultInvitationTTL = 72 * time.Hour
+)
+
+var (
+	ErrInvitationInvalid = errors.New("invitation token is invalid or was already used")
+	ErrInvitationExpired = errors.New("invitation has expired")
+)
+
+// SetInvitationTTL sets how long an invitation token can be accepted, 72 hours by default.
+func (a *App) SetInvitationTTL(ttl time.Duration) {
+	a.invitationTTL = ttl
+}
+
+// InviteUser creates a pending user with the email and admin flag of user and an invitation for it. The
+// returned token is the only place the caller can read it, the storage keeps its hash.
+func (a *App) InviteUser(ctx context.Context, user *models.User) (*models.Invitation, string, error) {
+	ctx, span := startSpan(ctx, "App.InviteUser")
+	defer span.End()
+
+	if valid := a.validator.IsEmail(user.Email); !valid {
+		a.log(ctx).Error("invalid email", map[string]interface{}{"email": user.Email})
+		return nil, "", fmt.Errorf("invalid email: %s", user.Email)
+	}
+
+	token := make([]byte, invitationTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		a.log(ctx).Error("Error generating invitation token", map[string]interface{}{"error": err})
+		return nil, "", fmt.Errorf("error while generate invitation token: %w", err)
+	}
+
+	ttl := a.invitationTTL
+	if ttl <= 0 {
+		ttl = defaultInvitationTTL
+	}
+
+	encodedToken := hex.EncodeToString(token)
+	invitation, err := a.storage.CreateInvitation(ctx, TenantFromContext(ctx), user, &models.Invitation{
+		TokenHash: hashInvitationToken(encodedToken),
+		ExpiresAt: a.now().Add(ttl),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return invitation, encodedToken, nil
+}
+
+// AcceptInvitation lets the invitee pick the username and password of the pending user, which becomes
+// active. The password policy applies as for every other new password.
+func (a *App) AcceptInvitation(ctx context.Context, token, userName, password string) (*models.User, error) {
+	ctx, span := startSpan(ctx, "App.AcceptInvitation")
+	defer span.End()
+
+	if len(userName) == 0 {
+		a.log(ctx).Error("empty username", nil)
+		return nil, errors.New("empty username")
+	}
+
+	// a colon cannot be a part of Basic credentials, nobody would log in with such a username
+	if strings.Contains(userName, ":") {
+		a.log(ctx).Error("invalid username", map[string]interface{}{"username": userName})
+		return nil, fmt.Errorf("invalid username: %s", userName)
+	}
+
+	// bcrypt is slow, an anonymous caller must not make the server hash passwords for made-up tokens
+	tokenHash := hashInvitationToken(token)
+	if err := a.storage.CheckInvitation(ctx, tokenHash); err != nil {
+		return nil, err
+	}
+
+	if err := a.validatePassword(ctx, password); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := a.hashPassword(ctx, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.storage.AcceptInvitation(ctx, tokenHash, userName, hashedPassword)
+}
+
+func (a *App) GetInvitations(ctx context.Context, offset, limit int) ([]models.Invitation, int, error) {
+	ctx, span := startSpan(ctx, "App.GetInvitations")
+	defer span.End()
+
+	return a.storage.GetInvitations(ctx, TenantFromContext(ctx), offset, limit)
+}
+
+// RevokeInvitation invalidates the token and removes the pending user created with the invitation.
+func (a *App) RevokeInvitation(ctx context.Context, invitationID string) error {
+	ctx, span := startSpan(ctx, "App.RevokeInvitation")
+	defer span.End()
+
+	if err := a.validateID(ctx, invitationID); err != nil {
+		return err
+	}
+
+	return a.storage.RevokeInvitation(ctx, TenantFromContext(ctx), invitationID)
+}
+
+// hashInvitationToken keeps tokens out of the storage, a fast hash is enough for 256 random bits.
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestInviteUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := WithTenant(context.Background(), "acme")
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+	app := NewApp(logg, storage, validation.New(), "")
+	app.SetInvitationTTL(time.Hour)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	app.SetClock(func() time.Time { return now })
+
+	var tokenHash string
+	storage.EXPECT().CreateInvitation(ctx, "acme", &models.User{Email: "alice@gmail.com"}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ *models.User, invitation *models.Invitation) (*models.Invitation, error) {
+			require.Equal(t, now.Add(time.Hour), invitation.ExpiresAt)
+			tokenHash = invitation.TokenHash
+			return invitation, nil
+		})
+
+	_, token, err := app.InviteUser(ctx, &models.User{Email: "alice@gmail.com"})
+	require.NoError(t, err)
+	require.Len(t, token, 2*invitationTokenSize)
+	require.Equal(t, hashInvitationToken(token), tokenHash, "only the hash of the token is stored")
+	require.NotEqual(t, token, tokenHash)
+
+	_, _, err = app.InviteUser(ctx, &models.User{Email: "alice"})
+	require.Error(t, err)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	type mockBehavior func(s *mocks.MockStorageInterface)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		username      string
+		password      string
+		mockBehavior  mockBehavior
+		expectedError bool
+		errorIs       error
+	}{
+		{
+			name:     "successful",
+			username: "alice",
+			password: "Secret-password1",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().CheckInvitation(ctx, hashInvitationToken("token")).Return(nil)
+				s.EXPECT().AcceptInvitation(ctx, hashInvitationToken("token"), "alice", gomock.Any()).DoAndReturn(
+					func(_ context.Context, _, userName, password string) (*models.User, error) {
+						require.NoError(t, bcrypt.CompareHashAndPassword([]byte(password), []byte("Secret-password1")))
+						return &models.User{UserName: userName, Password: password, Status: models.StatusActive}, nil
+					})
+			},
+		},
+		{
+			name:     "expired",
+			username: "alice",
+			password: "Secret-password1",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().CheckInvitation(ctx, hashInvitationToken("token")).Return(ErrInvitationExpired)
+			},
+			expectedError: true,
+			errorIs:       ErrInvitationExpired,
+		},
+		{
+			name:     "weak password",
+			username: "alice",
+			password: "short",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().CheckInvitation(ctx, hashInvitationToken("token")).Return(nil)
+			},
+			expectedError: true,
+			errorIs:       ErrWeakPassword,
+		},
+		{
+			name:     "invalid token is checked before the password",
+			username: "alice",
+			password: "short",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().CheckInvitation(ctx, hashInvitationToken("token")).Return(ErrInvitationInvalid)
+			},
+			expectedError: true,
+			errorIs:       ErrInvitationInvalid,
+		},
+		{
+			name:          "username with a colon",
+			username:      "alice:admin",
+			password:      "Secret-password1",
+			mockBehavior:  func(s *mocks.MockStorageInterface) {},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			testCase.mockBehavior(storage)
+			app := NewApp(logg, storage, validation.New(), "")
+			app.SetPasswordPolicy(PasswordPolicy{MinLength: 8})
+
+			_, err := app.AcceptInvitation(ctx, "token", testCase.username, testCase.password)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if testCase.errorIs != nil {
+				require.ErrorIs(t, err, testCase.errorIs)
+			}
+		})
+	}
+}
+
+func TestPurgeExpiredInvitations(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+	app := NewApp(logg, storage, validation.New(), "")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	app.SetClock(func() time.Time { return now })
+
+	storage.EXPECT().PurgeExpiredInvitations(ctx, models.AllTenants, now).Return(1, nil)
+	storage.EXPECT().PurgeDeletedUsers(ctx, models.AllTenants, now.Add(-time.Hour)).Return(0, nil)
+
+	app.PurgeExpiredInvitations(ctx)
+	app.PurgeDeletedUsers(ctx, time.Hour)
+}
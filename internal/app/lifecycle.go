@@ -0,0 +1,259 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Component is a part of the process managed by Lifecycle, every function is optional.
+type Component struct {
+	Name string
+	// Start prepares the component, e.g. binds a listener, a failure aborts the startup.
+	Start func(ctx context.Context) error
+	// Run is the blocking part, e.g. Serve of a server or the loop of a worker. Its context is canceled
+	// once Stop returns, returning before the component is stopped is a failure.
+	Run func(ctx context.Context) error
+	// Stop drains the component before its deadline, the one of the whole shutdown.
+	Stop func(ctx context.Context) error
+	// Kill stops the component at once when Stop or Run are still busy at the deadline. A component
+	// without Kill cannot be interrupted, the shutdown stops waiting for it at the deadline and reports
+	// it as leaked, so its Stop and Run must return once their context is done.
+	Kill func()
+	// Critical components shut the whole process down when their Run fails.
+	Critical bool
+}
+
+// StateReporter publishes whether a component is running, health.Checker implements it.
+type StateReporter interface {
+	SetStatus(name string, serving bool)
+}
+
+type ComponentState string
+
+const (
+	StateStarting ComponentState = "starting"
+	StateRunning  ComponentState = "running"
+	StateStopping ComponentState = "stopping"
+	StateStopped  ComponentState = "stopped"
+	StateFailed   ComponentState = "failed"
+)
+
+// stopGrace is how long a component may take to return after the shutdown deadline before it is killed
+// or reported as leaked.
+const stopGrace = 10 * time.Millisecond
+
+type component struct {
+	Component
+	state  ComponentState
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Lifecycle starts components in the order they were added and stops them in the reverse order, all
+// within a single shutdown timeout. Components with Run are reported to the StateReporter as serving while
+// they run.
+type Lifecycle struct {
+	mu         sync.Mutex
+	logger     Logger
+	reporter   StateReporter
+	timeout    time.Duration
+	components []*component
+	started    int
+	failed     chan error
+	shutdown   sync.Once
+	stopErr    error
+}
+
+func NewLifecycle(logger Logger, shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{logger: logger, timeout: shutdownTimeout, failed: make(chan error, 1)}
+}
+
+func (l *Lifecycle) SetStateReporter(reporter StateReporter) {
+	l.reporter = reporter
+}
+
+// Add must be called before Start.
+func (l *Lifecycle) Add(c Component) {
+	l.components = append(l.components, &component{Component: c, done: make(chan struct{})})
+}
+
+// State returns the state of the named component, empty for an unknown one.
+func (l *Lifecycle) State(name string) ComponentState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range l.components {
+		if c.Name == name {
+			return c.state
+		}
+	}
+
+	return ""
+}
+
+// Run starts the components and blocks until ctx is canceled or a critical component fails, then shuts
+// the started ones down.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	if err := l.Start(ctx); err != nil {
+		return err
+	}
+
+	var failure error
+	select {
+	case <-ctx.Done():
+	case failure = <-l.failed:
+	}
+
+	return errors.Join(failure, l.Shutdown())
+}
+
+// Start starts the components in order, when one fails the already started ones are shut down.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, c := range l.components {
+		l.setState(c, StateStarting)
+		l.logger.Info("starting component", map[string]interface{}{"component": c.Name})
+
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				l.setState(c, StateFailed)
+				err = fmt.Errorf("error while starting %s: %w", c.Name, err)
+
+				return errors.Join(err, l.Shutdown())
+			}
+		}
+
+		l.mu.Lock()
+		l.started++
+		l.mu.Unlock()
+
+		l.setState(c, StateRunning)
+
+		if c.Run == nil {
+			close(c.done)
+		} else {
+			var runCtx context.Context
+			runCtx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+			go l.run(runCtx, c)
+		}
+	}
+
+	return nil
+}
+
+func (l *Lifecycle) run(ctx context.Context, c *component) {
+	defer close(c.done)
+
+	err := c.Run(ctx)
+
+	// Run returns during Stop, e.g. Serve once GracefulStop is called
+	l.mu.Lock()
+	stopping := c.state == StateStopping || c.state == StateStopped
+	l.mu.Unlock()
+
+	if stopping {
+		return
+	}
+
+	if err == nil {
+		err = errors.New("stopped unexpectedly")
+	}
+
+	l.setState(c, StateFailed)
+	l.logger.Error("component failed", map[string]interface{}{"component": c.Name, "error": err})
+
+	if c.Critical {
+		select {
+		case l.failed <- fmt.Errorf("%s failed: %w", c.Name, err):
+		default:
+		}
+	}
+}
+
+// Shutdown stops the started components in the reverse order, it is safe to call more than once. The
+// shutdown timeout is shared by all of them: a component still busy at the deadline is killed, or left
+// behind when it has no Kill, and the next one is stopped with the expired context.
+func (l *Lifecycle) Shutdown() error {
+	l.shutdown.Do(func() {
+		l.mu.Lock()
+		started := l.components[:l.started]
+		l.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+		defer cancel()
+
+		var errs []error
+		for i := len(started) - 1; i >= 0; i-- {
+			if err := l.stop(ctx, started[i]); err != nil {
+				errs = append(errs, fmt.Errorf("error while stopping %s: %w", started[i].Name, err))
+			}
+		}
+
+		l.stopErr = errors.Join(errs...)
+	})
+
+	return l.stopErr
+}
+
+func (l *Lifecycle) stop(ctx context.Context, c *component) error {
+	l.setState(c, StateStopping)
+	l.logger.Info("stopping component", map[string]interface{}{"component": c.Name})
+
+	result := make(chan error, 1)
+	go func() {
+		var err error
+		if c.Stop != nil {
+			err = c.Stop(ctx)
+		}
+
+		if c.cancel != nil {
+			c.cancel()
+		}
+		<-c.done
+
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		l.setState(c, StateStopped)
+		return err
+	case <-ctx.Done():
+	}
+
+	// a component stopped with an already expired context is given a moment to notice it
+	select {
+	case err := <-result:
+		l.setState(c, StateStopped)
+		return err
+	case <-time.After(stopGrace):
+	}
+
+	if c.Kill == nil {
+		l.logger.Warn("component did not stop in time and cannot be killed, leaving it behind",
+			map[string]interface{}{"component": c.Name, "timeout": l.timeout})
+		l.setState(c, StateFailed)
+
+		return fmt.Errorf("leaked at the shutdown deadline of %s", l.timeout)
+	}
+
+	l.logger.Warn("component did not stop in time, killing it", map[string]interface{}{"component": c.Name, "timeout": l.timeout})
+
+	c.Kill()
+	l.setState(c, StateStopped)
+
+	return fmt.Errorf("killed at the shutdown deadline of %s", l.timeout)
+}
+
+func (l *Lifecycle) setState(c *component, state ComponentState) {
+	l.mu.Lock()
+	c.state = state
+	l.mu.Unlock()
+
+	if l.reporter != nil && c.Run != nil {
+		l.reporter.SetStatus(c.Name, state == StateRunning)
+	}
+}
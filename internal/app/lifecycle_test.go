@@ -0,0 +1,293 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type statusRecorder struct {
+	mu       sync.Mutex
+	statuses map[string]bool
+}
+
+func (r *statusRecorder) SetStatus(name string, serving bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.statuses[name] = serving
+}
+
+func (r *statusRecorder) status(name string) (bool, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	serving, ok := r.statuses[name]
+
+	return serving, ok
+}
+
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *callRecorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *callRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.calls...)
+}
+
+func newTestLifecycle(t *testing.T, timeout time.Duration) (*Lifecycle, *statusRecorder) {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	reporter := &statusRecorder{statuses: make(map[string]bool)}
+	lifecycle := NewLifecycle(logg, timeout)
+	lifecycle.SetStateReporter(reporter)
+
+	return lifecycle, reporter
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	lifecycle, reporter := newTestLifecycle(t, time.Second)
+	calls := &callRecorder{}
+
+	for _, name := range []string{"logger", "storage", "server"} {
+		name := name
+		lifecycle.Add(Component{
+			Name:  name,
+			Start: func(context.Context) error { calls.record("start " + name); return nil },
+			Stop:  func(context.Context) error { calls.record("stop " + name); return nil },
+		})
+	}
+	lifecycle.Add(Component{
+		Name: "worker",
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			calls.record("worker done")
+			return nil
+		},
+	})
+
+	require.NoError(t, lifecycle.Start(context.Background()))
+	require.Equal(t, StateRunning, lifecycle.State("worker"))
+
+	serving, ok := reporter.status("worker")
+	require.True(t, ok)
+	require.True(t, serving)
+
+	_, ok = reporter.status("storage")
+	require.False(t, ok, "only components with Run are reported")
+
+	require.NoError(t, lifecycle.Shutdown())
+	require.NoError(t, lifecycle.Shutdown(), "shutdown is idempotent")
+	require.Equal(t, []string{
+		"start logger", "start storage", "start server",
+		"worker done", "stop server", "stop storage", "stop logger",
+	}, calls.get())
+	require.Equal(t, StateStopped, lifecycle.State("worker"))
+
+	serving, _ = reporter.status("worker")
+	require.False(t, serving)
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	lifecycle, _ := newTestLifecycle(t, time.Second)
+	calls := &callRecorder{}
+
+	lifecycle.Add(Component{
+		Name: "storage",
+		Stop: func(context.Context) error { calls.record("stop storage"); return nil },
+	})
+	lifecycle.Add(Component{
+		Name:  "server",
+		Start: func(context.Context) error { return errors.New("address already in use") },
+		Stop:  func(context.Context) error { calls.record("stop server"); return nil },
+	})
+	lifecycle.Add(Component{
+		Name:  "admin",
+		Start: func(context.Context) error { calls.record("start admin"); return nil },
+	})
+
+	err := lifecycle.Start(context.Background())
+	require.ErrorContains(t, err, "error while starting server: address already in use")
+	require.Equal(t, []string{"stop storage"}, calls.get(), "only started components are stopped")
+	require.Equal(t, StateFailed, lifecycle.State("server"))
+	require.Equal(t, ComponentState(""), lifecycle.State("admin"))
+}
+
+func TestLifecycleKill(t *testing.T) {
+	lifecycle, _ := newTestLifecycle(t, 20*time.Millisecond)
+	calls := &callRecorder{}
+
+	stuck := make(chan struct{})
+	lifecycle.Add(Component{
+		Name: "storage",
+		Stop: func(context.Context) error { calls.record("stop storage"); return nil },
+	})
+	lifecycle.Add(Component{
+		Name: "server",
+		Run: func(context.Context) error {
+			<-stuck
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			<-stuck
+			return nil
+		},
+		Kill: func() {
+			calls.record("kill server")
+			close(stuck)
+		},
+	})
+
+	require.NoError(t, lifecycle.Start(context.Background()))
+
+	err := lifecycle.Shutdown()
+	require.ErrorContains(t, err, "error while stopping server: killed at the shutdown deadline of 20ms")
+	require.Equal(t, []string{"kill server", "stop storage"}, calls.get(), "the next component is stopped after the kill")
+}
+
+func TestLifecycleSharedDeadline(t *testing.T) {
+	timeout := 100 * time.Millisecond
+	lifecycle, _ := newTestLifecycle(t, timeout)
+	calls := &callRecorder{}
+
+	for _, name := range []string{"snapshot", "server.http", "server.grpc"} {
+		name := name
+		stuck := make(chan struct{})
+		component := Component{
+			Name: name,
+			Stop: func(ctx context.Context) error {
+				select {
+				case <-stuck:
+				case <-time.After(10 * timeout):
+				}
+				return nil
+			},
+			Kill: func() {
+				calls.record("kill " + name)
+				close(stuck)
+			},
+		}
+
+		if name == "snapshot" {
+			component.Stop = func(ctx context.Context) error {
+				calls.record("stop snapshot, deadline exceeded: " + strconv.FormatBool(ctx.Err() != nil))
+				return ctx.Err()
+			}
+			component.Kill = nil
+		}
+
+		lifecycle.Add(component)
+	}
+
+	require.NoError(t, lifecycle.Start(context.Background()))
+
+	started := time.Now()
+	err := lifecycle.Shutdown()
+	elapsed := time.Since(started)
+
+	require.ErrorContains(t, err, "error while stopping server.grpc")
+	require.ErrorContains(t, err, "error while stopping server.http")
+	require.ErrorContains(t, err, "error while stopping snapshot: context deadline exceeded")
+	require.Less(t, elapsed, timeout+timeout/2, "the components share a single deadline")
+	require.Equal(t, []string{"kill server.grpc", "kill server.http", "stop snapshot, deadline exceeded: true"}, calls.get(),
+		"a component that cannot be killed is still stopped after the deadline")
+}
+
+func TestLifecycleLeak(t *testing.T) {
+	timeout := 20 * time.Millisecond
+	lifecycle, _ := newTestLifecycle(t, timeout)
+	calls := &callRecorder{}
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	lifecycle.Add(Component{
+		Name: "storage",
+		Stop: func(context.Context) error { calls.record("stop storage"); return nil },
+	})
+	lifecycle.Add(Component{
+		Name: "worker",
+		Stop: func(context.Context) error {
+			<-stuck
+			return nil
+		},
+	})
+
+	require.NoError(t, lifecycle.Start(context.Background()))
+
+	started := time.Now()
+	err := lifecycle.Shutdown()
+	elapsed := time.Since(started)
+
+	require.ErrorContains(t, err, "error while stopping worker: leaked at the shutdown deadline of 20ms")
+	require.Less(t, elapsed, 10*timeout, "the shutdown does not wait for a component that ignores its context")
+	require.Equal(t, StateFailed, lifecycle.State("worker"))
+	require.Equal(t, []string{"stop storage"}, calls.get(), "the next component is stopped after the leak")
+}
+
+func TestLifecycleRunFailure(t *testing.T) {
+	lifecycle, reporter := newTestLifecycle(t, time.Second)
+
+	crash := make(chan struct{})
+	lifecycle.Add(Component{
+		Name: "worker",
+		Run: func(ctx context.Context) error {
+			<-crash
+			return nil
+		},
+	})
+	lifecycle.Add(Component{
+		Name: "server",
+		Run: func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-crash:
+				return errors.New("listener closed")
+			}
+		},
+		Critical: true,
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- lifecycle.Run(context.Background())
+	}()
+
+	require.Eventually(t, func() bool {
+		serving, _ := reporter.status("server")
+		return serving
+	}, time.Second, 5*time.Millisecond)
+
+	close(crash)
+
+	select {
+	case err := <-result:
+		require.ErrorContains(t, err, "server failed: listener closed")
+	case <-time.After(time.Second):
+		t.Fatal("a failed critical component must shut the lifecycle down")
+	}
+
+	serving, _ := reporter.status("worker")
+	require.False(t, serving)
+}
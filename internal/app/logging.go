@@ -0,0 +1,25 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+)
+
+// ContextLogger returns the request-scoped logger stored in ctx by the request interceptor (request id,
+// method, peer, principal) or fallback outside of requests. Records carry the trace of ctx.
+func ContextLogger(ctx context.Context, fallback Logger) Logger {
+	requestLogger, ok := logger.FromContext(ctx)
+	if !ok {
+		if requestLogger, ok = fallback.(*logger.ZapLogger); !ok {
+			return fallback
+		}
+	}
+
+	return requestLogger.WithContext(ctx)
+}
+
+func (a *App) log(ctx context.Context) Logger {
+	return ContextLogger(ctx, a.logger)
+}
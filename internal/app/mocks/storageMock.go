@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/Baraulia/X-Labs_Test/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,67 +36,291 @@ func (m *MockStorageInterface) EXPECT() *MockStorageInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockStorageInterface) AcceptInvitation(arg0 context.Context, arg1, arg2, arg3 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockStorageInterfaceMockRecorder) AcceptInvitation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockStorageInterface)(nil).AcceptInvitation), arg0, arg1, arg2, arg3)
+}
+
+// AddGroupMember mocks base method.
+func (m *MockStorageInterface) AddGroupMember(arg0 context.Context, arg1, arg2 string, arg3 models.GroupMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGroupMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddGroupMember indicates an expected call of AddGroupMember.
+func (mr *MockStorageInterfaceMockRecorder) AddGroupMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGroupMember", reflect.TypeOf((*MockStorageInterface)(nil).AddGroupMember), arg0, arg1, arg2, arg3)
+}
+
+// BatchCreateUsers mocks base method.
+func (m *MockStorageInterface) BatchCreateUsers(arg0 context.Context, arg1 string, arg2 []*models.User, arg3 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchCreateUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchCreateUsers indicates an expected call of BatchCreateUsers.
+func (mr *MockStorageInterfaceMockRecorder) BatchCreateUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchCreateUsers", reflect.TypeOf((*MockStorageInterface)(nil).BatchCreateUsers), arg0, arg1, arg2, arg3)
+}
+
+// BatchDeleteUsers mocks base method.
+func (m *MockStorageInterface) BatchDeleteUsers(arg0 context.Context, arg1 string, arg2 []string, arg3 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDeleteUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDeleteUsers indicates an expected call of BatchDeleteUsers.
+func (mr *MockStorageInterfaceMockRecorder) BatchDeleteUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDeleteUsers", reflect.TypeOf((*MockStorageInterface)(nil).BatchDeleteUsers), arg0, arg1, arg2, arg3)
+}
+
+// BatchUpdateUsers mocks base method.
+func (m *MockStorageInterface) BatchUpdateUsers(arg0 context.Context, arg1 string, arg2 []models.UserUpdate, arg3 bool) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdateUsers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchUpdateUsers indicates an expected call of BatchUpdateUsers.
+func (mr *MockStorageInterfaceMockRecorder) BatchUpdateUsers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdateUsers", reflect.TypeOf((*MockStorageInterface)(nil).BatchUpdateUsers), arg0, arg1, arg2, arg3)
+}
+
+// CheckInvitation mocks base method.
+func (m *MockStorageInterface) CheckInvitation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckInvitation indicates an expected call of CheckInvitation.
+func (mr *MockStorageInterfaceMockRecorder) CheckInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInvitation", reflect.TypeOf((*MockStorageInterface)(nil).CheckInvitation), arg0, arg1)
+}
+
+// CreateGroup mocks base method.
+func (m *MockStorageInterface) CreateGroup(arg0 context.Context, arg1 string, arg2 *models.Group) (*models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockStorageInterfaceMockRecorder) CreateGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockStorageInterface)(nil).CreateGroup), arg0, arg1, arg2)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockStorageInterface) CreateInvitation(arg0 context.Context, arg1 string, arg2 *models.User, arg3 *models.Invitation) (*models.Invitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Invitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockStorageInterfaceMockRecorder) CreateInvitation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockStorageInterface)(nil).CreateInvitation), arg0, arg1, arg2, arg3)
+}
+
 // CreateUser mocks base method.
-func (m *MockStorageInterface) CreateUser(arg0 context.Context, arg1 *models.User) (*models.User, error) {
+func (m *MockStorageInterface) CreateUser(arg0 context.Context, arg1 string, arg2 *models.User) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockStorageInterfaceMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStorageInterfaceMockRecorder) CreateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStorageInterface)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStorageInterface)(nil).CreateUser), arg0, arg1, arg2)
+}
+
+// DeleteGroup mocks base method.
+func (m *MockStorageInterface) DeleteGroup(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGroup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGroup indicates an expected call of DeleteGroup.
+func (mr *MockStorageInterfaceMockRecorder) DeleteGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGroup", reflect.TypeOf((*MockStorageInterface)(nil).DeleteGroup), arg0, arg1, arg2)
 }
 
 // DeleteUser mocks base method.
-func (m *MockStorageInterface) DeleteUser(arg0 context.Context, arg1 string) error {
+func (m *MockStorageInterface) DeleteUser(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStorageInterfaceMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStorageInterfaceMockRecorder) DeleteUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStorageInterface)(nil).DeleteUser), arg0, arg1, arg2)
+}
+
+// FetchOutbox mocks base method.
+func (m *MockStorageInterface) FetchOutbox(arg0 context.Context, arg1 int) ([]models.OutboxRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchOutbox", arg0, arg1)
+	ret0, _ := ret[0].([]models.OutboxRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchOutbox indicates an expected call of FetchOutbox.
+func (mr *MockStorageInterfaceMockRecorder) FetchOutbox(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStorageInterface)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchOutbox", reflect.TypeOf((*MockStorageInterface)(nil).FetchOutbox), arg0, arg1)
+}
+
+// GetGroup mocks base method.
+func (m *MockStorageInterface) GetGroup(arg0 context.Context, arg1, arg2 string) (*models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockStorageInterfaceMockRecorder) GetGroup(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockStorageInterface)(nil).GetGroup), arg0, arg1, arg2)
+}
+
+// GetGroupMembers mocks base method.
+func (m *MockStorageInterface) GetGroupMembers(arg0 context.Context, arg1, arg2 string, arg3 bool) (*models.GroupMembers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupMembers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.GroupMembers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupMembers indicates an expected call of GetGroupMembers.
+func (mr *MockStorageInterfaceMockRecorder) GetGroupMembers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupMembers", reflect.TypeOf((*MockStorageInterface)(nil).GetGroupMembers), arg0, arg1, arg2, arg3)
+}
+
+// GetGroups mocks base method.
+func (m *MockStorageInterface) GetGroups(arg0 context.Context, arg1 string, arg2, arg3 int) ([]models.Group, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroups", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Group)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetGroups indicates an expected call of GetGroups.
+func (mr *MockStorageInterfaceMockRecorder) GetGroups(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroups", reflect.TypeOf((*MockStorageInterface)(nil).GetGroups), arg0, arg1, arg2, arg3)
+}
+
+// GetInvitations mocks base method.
+func (m *MockStorageInterface) GetInvitations(arg0 context.Context, arg1 string, arg2, arg3 int) ([]models.Invitation, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Invitation)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockStorageInterfaceMockRecorder) GetInvitations(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockStorageInterface)(nil).GetInvitations), arg0, arg1, arg2, arg3)
 }
 
 // GetOneUserByID mocks base method.
-func (m *MockStorageInterface) GetOneUserByID(arg0 context.Context, arg1 string) (*models.User, error) {
+func (m *MockStorageInterface) GetOneUserByID(arg0 context.Context, arg1, arg2 string, arg3 bool) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneUserByID", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetOneUserByID", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOneUserByID indicates an expected call of GetOneUserByID.
-func (mr *MockStorageInterfaceMockRecorder) GetOneUserByID(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStorageInterfaceMockRecorder) GetOneUserByID(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByID", reflect.TypeOf((*MockStorageInterface)(nil).GetOneUserByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByID", reflect.TypeOf((*MockStorageInterface)(nil).GetOneUserByID), arg0, arg1, arg2, arg3)
 }
 
 // GetOneUserByUsername mocks base method.
-func (m *MockStorageInterface) GetOneUserByUsername(arg0 context.Context, arg1 string) (*models.User, error) {
+func (m *MockStorageInterface) GetOneUserByUsername(arg0 context.Context, arg1, arg2 string, arg3 bool) (*models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneUserByUsername", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetOneUserByUsername", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOneUserByUsername indicates an expected call of GetOneUserByUsername.
-func (mr *MockStorageInterfaceMockRecorder) GetOneUserByUsername(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStorageInterfaceMockRecorder) GetOneUserByUsername(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByUsername", reflect.TypeOf((*MockStorageInterface)(nil).GetOneUserByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneUserByUsername", reflect.TypeOf((*MockStorageInterface)(nil).GetOneUserByUsername), arg0, arg1, arg2, arg3)
+}
+
+// GetUserGroups mocks base method.
+func (m *MockStorageInterface) GetUserGroups(arg0 context.Context, arg1, arg2 string, arg3 bool) ([]models.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserGroups", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserGroups indicates an expected call of GetUserGroups.
+func (mr *MockStorageInterfaceMockRecorder) GetUserGroups(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserGroups", reflect.TypeOf((*MockStorageInterface)(nil).GetUserGroups), arg0, arg1, arg2, arg3)
 }
 
 // GetUsers mocks base method.
-func (m *MockStorageInterface) GetUsers(arg0 context.Context, arg1, arg2 int) ([]models.User, int, error) {
+func (m *MockStorageInterface) GetUsers(arg0 context.Context, arg1 string, arg2 models.UsersFilter) ([]models.User, int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUsers", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]models.User)
@@ -110,16 +335,201 @@ func (mr *MockStorageInterfaceMockRecorder) GetUsers(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockStorageInterface)(nil).GetUsers), arg0, arg1, arg2)
 }
 
+// MarkOutboxDelivered mocks base method.
+func (m *MockStorageInterface) MarkOutboxDelivered(arg0 context.Context, arg1 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxDelivered", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxDelivered indicates an expected call of MarkOutboxDelivered.
+func (mr *MockStorageInterfaceMockRecorder) MarkOutboxDelivered(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxDelivered", reflect.TypeOf((*MockStorageInterface)(nil).MarkOutboxDelivered), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockStorageInterface) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStorageInterfaceMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStorageInterface)(nil).Ping), arg0)
+}
+
+// PurgeDeletedUsers mocks base method.
+func (m *MockStorageInterface) PurgeDeletedUsers(arg0 context.Context, arg1 string, arg2 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedUsers indicates an expected call of PurgeDeletedUsers.
+func (mr *MockStorageInterfaceMockRecorder) PurgeDeletedUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedUsers", reflect.TypeOf((*MockStorageInterface)(nil).PurgeDeletedUsers), arg0, arg1, arg2)
+}
+
+// PurgeExpiredInvitations mocks base method.
+func (m *MockStorageInterface) PurgeExpiredInvitations(arg0 context.Context, arg1 string, arg2 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredInvitations", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredInvitations indicates an expected call of PurgeExpiredInvitations.
+func (mr *MockStorageInterfaceMockRecorder) PurgeExpiredInvitations(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredInvitations", reflect.TypeOf((*MockStorageInterface)(nil).PurgeExpiredInvitations), arg0, arg1, arg2)
+}
+
+// PurgeUser mocks base method.
+func (m *MockStorageInterface) PurgeUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockStorageInterfaceMockRecorder) PurgeUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockStorageInterface)(nil).PurgeUser), arg0, arg1, arg2)
+}
+
+// RecordFailedLogin mocks base method.
+func (m *MockStorageInterface) RecordFailedLogin(arg0 context.Context, arg1, arg2 string, arg3 int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedLogin", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailedLogin indicates an expected call of RecordFailedLogin.
+func (mr *MockStorageInterfaceMockRecorder) RecordFailedLogin(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedLogin", reflect.TypeOf((*MockStorageInterface)(nil).RecordFailedLogin), arg0, arg1, arg2, arg3)
+}
+
+// RecordLogin mocks base method.
+func (m *MockStorageInterface) RecordLogin(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLogin", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLogin indicates an expected call of RecordLogin.
+func (mr *MockStorageInterfaceMockRecorder) RecordLogin(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLogin", reflect.TypeOf((*MockStorageInterface)(nil).RecordLogin), arg0, arg1, arg2)
+}
+
+// RemoveGroupMember mocks base method.
+func (m *MockStorageInterface) RemoveGroupMember(arg0 context.Context, arg1, arg2 string, arg3 models.GroupMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveGroupMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveGroupMember indicates an expected call of RemoveGroupMember.
+func (mr *MockStorageInterfaceMockRecorder) RemoveGroupMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveGroupMember", reflect.TypeOf((*MockStorageInterface)(nil).RemoveGroupMember), arg0, arg1, arg2, arg3)
+}
+
+// RevokeInvitation mocks base method.
+func (m *MockStorageInterface) RevokeInvitation(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeInvitation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeInvitation indicates an expected call of RevokeInvitation.
+func (mr *MockStorageInterfaceMockRecorder) RevokeInvitation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvitation", reflect.TypeOf((*MockStorageInterface)(nil).RevokeInvitation), arg0, arg1, arg2)
+}
+
+// UndeleteUser mocks base method.
+func (m *MockStorageInterface) UndeleteUser(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UndeleteUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UndeleteUser indicates an expected call of UndeleteUser.
+func (mr *MockStorageInterfaceMockRecorder) UndeleteUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UndeleteUser", reflect.TypeOf((*MockStorageInterface)(nil).UndeleteUser), arg0, arg1, arg2)
+}
+
+// UpdateGroup mocks base method.
+func (m *MockStorageInterface) UpdateGroup(arg0 context.Context, arg1 string, arg2 models.UpdateGroupDTO, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGroup", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGroup indicates an expected call of UpdateGroup.
+func (mr *MockStorageInterfaceMockRecorder) UpdateGroup(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGroup", reflect.TypeOf((*MockStorageInterface)(nil).UpdateGroup), arg0, arg1, arg2, arg3)
+}
+
 // UpdateUser mocks base method.
-func (m *MockStorageInterface) UpdateUser(arg0 context.Context, arg1 models.UpdateUserDTO, arg2 string) error {
+func (m *MockStorageInterface) UpdateUser(arg0 context.Context, arg1 string, arg2 models.UpdateUserDTO, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockStorageInterfaceMockRecorder) UpdateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockStorageInterfaceMockRecorder) UpdateUser(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStorageInterface)(nil).UpdateUser), arg0, arg1, arg2, arg3)
+}
+
+// UpdateUserStatus mocks base method.
+func (m *MockStorageInterface) UpdateUserStatus(arg0 context.Context, arg1, arg2 string, arg3, arg4 models.UserStatus, arg5 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserStatus", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserStatus indicates an expected call of UpdateUserStatus.
+func (mr *MockStorageInterfaceMockRecorder) UpdateUserStatus(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStatus", reflect.TypeOf((*MockStorageInterface)(nil).UpdateUserStatus), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// WatchUsers mocks base method.
+func (m *MockStorageInterface) WatchUsers(arg0 context.Context, arg1 string, arg2 uint64, arg3 bool, arg4 func(models.UserEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchUsers", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WatchUsers indicates an expected call of WatchUsers.
+func (mr *MockStorageInterfaceMockRecorder) WatchUsers(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStorageInterface)(nil).UpdateUser), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchUsers", reflect.TypeOf((*MockStorageInterface)(nil).WatchUsers), arg0, arg1, arg2, arg3, arg4)
 }
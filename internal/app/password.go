@@ -0,0 +1,76 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrWeakPassword = errors.New("password does not satisfy the password policy")
+
+// PasswordPolicy is checked on every new password, the zero policy accepts any password.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// SetPasswordPolicy replaces the policy, it is safe to call while requests are served.
+func (a *App) SetPasswordPolicy(policy PasswordPolicy) {
+	a.passwordPolicy.Store(&policy)
+}
+
+func (a *App) validatePassword(ctx context.Context, password string) error {
+	policy := a.passwordPolicy.Load()
+	if policy == nil {
+		return nil
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	violations := make([]string, 0)
+	if length := utf8.RuneCountInString(password); length < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+
+	if policy.RequireUpper && !upper {
+		violations = append(violations, "an upper case letter")
+	}
+
+	if policy.RequireLower && !lower {
+		violations = append(violations, "a lower case letter")
+	}
+
+	if policy.RequireDigit && !digit {
+		violations = append(violations, "a digit")
+	}
+
+	if policy.RequireSymbol && !symbol {
+		violations = append(violations, "a symbol")
+	}
+
+	if len(violations) > 0 {
+		a.log(ctx).Error("weak password", map[string]interface{}{"violations": violations})
+		return fmt.Errorf("%w: must contain %s", ErrWeakPassword, strings.Join(violations, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testPasswordPolicy = PasswordPolicy{
+	MinLength:     8,
+	RequireUpper:  true,
+	RequireLower:  true,
+	RequireDigit:  true,
+	RequireSymbol: true,
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		policy        *PasswordPolicy
+		password      string
+		expectedError bool
+	}{
+		{
+			name:     "no policy",
+			password: "x",
+		},
+		{
+			name:     "strong password",
+			policy:   &testPasswordPolicy,
+			password: "Str0ng-pass",
+		},
+		{
+			name:          "too short",
+			policy:        &testPasswordPolicy,
+			password:      "S0rt-p",
+			expectedError: true,
+		},
+		{
+			name:          "no upper case letter",
+			policy:        &testPasswordPolicy,
+			password:      "str0ng-pass",
+			expectedError: true,
+		},
+		{
+			name:          "no digit",
+			policy:        &testPasswordPolicy,
+			password:      "Strong-pass",
+			expectedError: true,
+		},
+		{
+			name:          "no symbol",
+			policy:        &testPasswordPolicy,
+			password:      "Str0ngpass",
+			expectedError: true,
+		},
+		{
+			name:     "length counts characters",
+			policy:   &PasswordPolicy{MinLength: 4},
+			password: "пароль",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().CreateUser(ctx, models.DefaultTenant, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, user *models.User) (*models.User, error) { return user, nil },
+			).MaxTimes(1)
+
+			app := NewApp(logg, storage, validator, "")
+			if testCase.policy != nil {
+				app.SetPasswordPolicy(*testCase.policy)
+			}
+
+			_, err := app.CreateUser(ctx, &models.User{Email: "test@gmail.com", UserName: "testUserName", Password: testCase.password})
+			if testCase.expectedError {
+				require.ErrorIs(t, err, ErrWeakPassword)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdateUserPasswordPolicy(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+
+	app := NewApp(logg, storage, validation.New(), "")
+	app.SetPasswordPolicy(PasswordPolicy{MinLength: 8})
+
+	weak := "short"
+	err = app.UpdateUser(ctx, models.UpdateUserDTO{Password: &weak}, uuid.New().String())
+	require.ErrorIs(t, err, ErrWeakPassword)
+}
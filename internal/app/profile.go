@@ -0,0 +1,166 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"golang.org/x/text/language"
+)
+
+const maxDisplayNameLength = 128
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeInt    AttributeType = "int"
+	AttributeBool   AttributeType = "bool"
+)
+
+type AttributeRule struct {
+	Type      AttributeType
+	MaxLength int
+}
+
+type AttributeSchema struct {
+	MaxAttributes int
+	Attributes    map[string]AttributeRule
+}
+
+// SetAttributeSchema sets the schema of the tenants without one of their own.
+func (a *App) SetAttributeSchema(schema AttributeSchema) {
+	a.attributeSchema = schema
+}
+
+// SetTenantAttributeSchemas sets the schemas of single tenants by their ID, each one replaces the default
+// schema for the users of its tenant.
+func (a *App) SetTenantAttributeSchemas(schemas map[string]AttributeSchema) {
+	a.tenantSchemas = schemas
+}
+
+func (a *App) attributeSchemaOf(tenantID string) AttributeSchema {
+	if schema, ok := a.tenantSchemas[tenantID]; ok {
+		return schema
+	}
+
+	return a.attributeSchema
+}
+
+func (a *App) validateProfile(ctx context.Context, profile models.Profile) error {
+	if utf8.RuneCountInString(profile.DisplayName) > maxDisplayNameLength {
+		a.log(ctx).Error("display name is too long", map[string]interface{}{"displayName": profile.DisplayName})
+		return fmt.Errorf("display name is longer than %d characters", maxDisplayNameLength)
+	}
+
+	if profile.Locale != "" {
+		if _, err := language.Parse(profile.Locale); err != nil {
+			a.log(ctx).Error("invalid locale", map[string]interface{}{"locale": profile.Locale})
+			return fmt.Errorf("invalid locale: %s", profile.Locale)
+		}
+	}
+
+	if profile.TimeZone != "" {
+		if _, err := time.LoadLocation(profile.TimeZone); err != nil {
+			a.log(ctx).Error("invalid time zone", map[string]interface{}{"timeZone": profile.TimeZone})
+			return fmt.Errorf("invalid time zone: %s", profile.TimeZone)
+		}
+	}
+
+	if profile.PhoneNumber != "" && !a.validator.IsPhoneNumber(profile.PhoneNumber) {
+		a.log(ctx).Error("invalid phone number", map[string]interface{}{"phoneNumber": profile.PhoneNumber})
+		return fmt.Errorf("invalid phone number: %s", profile.PhoneNumber)
+	}
+
+	return nil
+}
+
+// validateAttributes checks attributes against the schema of the tenant of ctx. Empty values
+// are allowed for known keys only when allowEmpty is set, since they mean removal on update.
+func (a *App) validateAttributes(ctx context.Context, attributes map[string]string, allowEmpty bool) error {
+	schema := a.attributeSchemaOf(TenantFromContext(ctx))
+
+	if schema.MaxAttributes > 0 && len(attributes) > schema.MaxAttributes {
+		a.log(ctx).Error("too many attributes", map[string]interface{}{"count": len(attributes)})
+		return fmt.Errorf("too many attributes: %d, max %d", len(attributes), schema.MaxAttributes)
+	}
+
+	for key, value := range attributes {
+		rule, ok := schema.Attributes[key]
+		if !ok {
+			a.log(ctx).Error("unknown attribute", map[string]interface{}{"key": key})
+			return fmt.Errorf("unknown attribute: %s", key)
+		}
+
+		if value == "" && allowEmpty {
+			continue
+		}
+
+		if err := validateAttribute(rule, value); err != nil {
+			a.log(ctx).Error("invalid attribute", map[string]interface{}{"key": key, "value": value, "error": err})
+			return fmt.Errorf("invalid attribute %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) checkMergedAttributes(ctx context.Context, attributes map[string]string, userID string) error {
+	tenantID := TenantFromContext(ctx)
+	schema := a.attributeSchemaOf(tenantID)
+	if len(attributes) == 0 || schema.MaxAttributes <= 0 {
+		return nil
+	}
+
+	user, err := a.storage.GetOneUserByID(ctx, tenantID, userID, false)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]struct{}, len(user.Attributes))
+	for key := range user.Attributes {
+		merged[key] = struct{}{}
+	}
+
+	for key, value := range attributes {
+		if value == "" {
+			delete(merged, key)
+		} else {
+			merged[key] = struct{}{}
+		}
+	}
+
+	if len(merged) > schema.MaxAttributes {
+		a.log(ctx).Error("too many attributes", map[string]interface{}{"id": userID, "count": len(merged)})
+		return fmt.Errorf("too many attributes: %d, max %d", len(merged), schema.MaxAttributes)
+	}
+
+	return nil
+}
+
+func validateAttribute(rule AttributeRule, value string) error {
+	if rule.MaxLength > 0 && utf8.RuneCountInString(value) > rule.MaxLength {
+		return fmt.Errorf("value is longer than %d characters", rule.MaxLength)
+	}
+
+	switch rule.Type {
+	case AttributeString:
+		return nil
+	case AttributeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("value is not an integer: %s", value)
+		}
+	case AttributeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("value is not a boolean: %s", value)
+		}
+	default:
+		return fmt.Errorf("unsupported attribute type: %s", rule.Type)
+	}
+
+	return nil
+}
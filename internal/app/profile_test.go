@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = AttributeSchema{
+	MaxAttributes: 2,
+	Attributes: map[string]AttributeRule{
+		"department":  {Type: AttributeString, MaxLength: 8},
+		"employee_id": {Type: AttributeInt},
+		"newsletter":  {Type: AttributeBool},
+	},
+}
+
+func TestCreateUserProfile(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		profile       models.Profile
+		attributes    map[string]string
+		expectedError bool
+	}{
+		{
+			name: "successful",
+			profile: models.Profile{
+				DisplayName: "Test User",
+				Locale:      "en-US",
+				TimeZone:    "Europe/Minsk",
+				PhoneNumber: "+375291234567",
+			},
+			attributes:    map[string]string{"department": "sales", "employee_id": "42"},
+			expectedError: false,
+		},
+		{
+			name:          "invalid locale",
+			profile:       models.Profile{Locale: "not a locale"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid time zone",
+			profile:       models.Profile{TimeZone: "Mars/Olympus"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid phone number",
+			profile:       models.Profile{PhoneNumber: "123"},
+			expectedError: true,
+		},
+		{
+			name:          "unknown attribute",
+			attributes:    map[string]string{"salary": "100"},
+			expectedError: true,
+		},
+		{
+			name:          "too long attribute",
+			attributes:    map[string]string{"department": "engineering"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid int attribute",
+			attributes:    map[string]string{"employee_id": "forty-two"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid bool attribute",
+			attributes:    map[string]string{"newsletter": "maybe"},
+			expectedError: true,
+		},
+		{
+			name:          "too many attributes",
+			attributes:    map[string]string{"department": "sales", "employee_id": "42", "newsletter": "true"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			user := &models.User{
+				Email:      "test@gmail.com",
+				UserName:   "testUserName",
+				Password:   "test",
+				Profile:    testCase.profile,
+				Attributes: testCase.attributes,
+			}
+			if !testCase.expectedError {
+				storage.EXPECT().CreateUser(ctx, models.DefaultTenant, user).Return(user, nil)
+			}
+			app := NewApp(logg, storage, validator, "")
+			app.SetAttributeSchema(testSchema)
+
+			_, err = app.CreateUser(ctx, user)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdateUserAttributes(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+	newUUID := uuid.New().String()
+
+	testTable := []struct {
+		name          string
+		attributes    map[string]string
+		expectedError bool
+	}{
+		{
+			name:          "replace attribute",
+			attributes:    map[string]string{"department": "support"},
+			expectedError: false,
+		},
+		{
+			name:          "remove and add attribute",
+			attributes:    map[string]string{"department": "", "newsletter": "false"},
+			expectedError: false,
+		},
+		{
+			name:          "exceed max attributes",
+			attributes:    map[string]string{"newsletter": "false"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			dto := models.UpdateUserDTO{Attributes: testCase.attributes}
+			storage.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, newUUID, false).Return(&models.User{
+				ID:         newUUID,
+				Attributes: map[string]string{"department": "sales", "employee_id": "42"},
+			}, nil)
+			if !testCase.expectedError {
+				storage.EXPECT().UpdateUser(ctx, models.DefaultTenant, dto, newUUID).Return(nil)
+			}
+			app := NewApp(logg, storage, validator, "")
+			app.SetAttributeSchema(testSchema)
+
+			err = app.UpdateUser(ctx, dto, newUUID)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTenantAttributeSchema(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+
+	testTable := []struct {
+		name          string
+		tenantID      string
+		attributes    map[string]string
+		expectedError bool
+	}{
+		{
+			name:       "attribute of the tenant schema",
+			tenantID:   "acme",
+			attributes: map[string]string{"region": "emea"},
+		},
+		{
+			name:          "attribute of the default schema in a tenant with its own",
+			tenantID:      "acme",
+			attributes:    map[string]string{"department": "sales"},
+			expectedError: true,
+		},
+		{
+			name:          "tenant schema limits",
+			tenantID:      "acme",
+			attributes:    map[string]string{"region": "north-america"},
+			expectedError: true,
+		},
+		{
+			name:       "tenant without a schema of its own",
+			tenantID:   "globex",
+			attributes: map[string]string{"department": "sales"},
+		},
+		{
+			name:          "attribute of another tenant schema",
+			tenantID:      "globex",
+			attributes:    map[string]string{"region": "emea"},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			ctx := WithTenant(context.Background(), testCase.tenantID)
+			user := &models.User{Email: "test@gmail.com", UserName: "test", Password: "password", Attributes: testCase.attributes}
+			if !testCase.expectedError {
+				storage.EXPECT().CreateUser(ctx, testCase.tenantID, user).Return(user, nil)
+			}
+			app := NewApp(logg, storage, validator, "")
+			app.SetAttributeSchema(testSchema)
+			app.SetTenantAttributeSchemas(map[string]AttributeSchema{
+				"acme": {Attributes: map[string]AttributeRule{"region": {Type: AttributeString, MaxLength: 8}}},
+			})
+
+			_, err = app.CreateUser(ctx, user)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
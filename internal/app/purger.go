@@ -0,0 +1,63 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// RunPurger purges the deleted users after the retention and the expired invitations with their pending
+// users every interval.
+func (a *App) RunPurger(ctx context.Context, retention, interval time.Duration) {
+	if interval <= 0 {
+		a.log(ctx).Warn("purger is disabled", map[string]interface{}{"interval": interval})
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.PurgeDeletedUsers(ctx, retention)
+			a.PurgeExpiredInvitations(ctx)
+		}
+	}
+}
+
+// PurgeDeletedUsers purges the users of every tenant, it runs in the background and not on behalf of one.
+func (a *App) PurgeDeletedUsers(ctx context.Context, retention time.Duration) {
+	ctx, span := startSpan(ctx, "App.PurgeDeletedUsers")
+	defer span.End()
+
+	purged, err := a.storage.PurgeDeletedUsers(ctx, models.AllTenants, a.now().Add(-retention))
+	if err != nil {
+		a.log(ctx).Error("error while purging deleted users", map[string]interface{}{"error": err})
+		return
+	}
+
+	if purged > 0 {
+		a.log(ctx).Info("deleted users were purged", map[string]interface{}{"count": purged})
+	}
+}
+
+// PurgeExpiredInvitations purges the invitations of every tenant that can no longer be accepted.
+func (a *App) PurgeExpiredInvitations(ctx context.Context) {
+	ctx, span := startSpan(ctx, "App.PurgeExpiredInvitations")
+	defer span.End()
+
+	purged, err := a.storage.PurgeExpiredInvitations(ctx, models.AllTenants, a.now())
+	if err != nil {
+		a.log(ctx).Error("error while purging expired invitations", map[string]interface{}{"error": err})
+		return
+	}
+
+	if purged > 0 {
+		a.log(ctx).Info("expired invitations were purged", map[string]interface{}{"count": purged})
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+var (
+	ErrTenantRequired = errors.New("a single tenant must be selected")
+	ErrCrossTenant    = errors.New("only a super-admin has access to other tenants")
+)
+
+type tenantContextKey struct{}
+
+// WithTenant scopes the storage calls made with ctx to the tenant, models.AllTenants to every tenant.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns models.DefaultTenant when ctx has no tenant, e.g. for calls made at startup.
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+
+	return models.DefaultTenant
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestTenantFromContext(t *testing.T) {
+	require.Equal(t, models.DefaultTenant, TenantFromContext(context.Background()))
+	require.Equal(t, "acme", TenantFromContext(WithTenant(context.Background(), "acme")))
+	require.Equal(t, models.AllTenants, TenantFromContext(WithTenant(context.Background(), models.AllTenants)))
+}
+
+func TestCheckPasswordTenant(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	newUser := func(tenantID string, superAdmin bool) *models.User {
+		return &models.User{
+			ID:         "id-" + tenantID,
+			TenantID:   tenantID,
+			UserName:   "admin",
+			Password:   string(hashedPassword),
+			Admin:      true,
+			SuperAdmin: superAdmin,
+			Status:     models.StatusActive,
+		}
+	}
+	notFound := errors.New("user with username admin does not exist")
+
+	testTable := []struct {
+		name          string
+		tenantID      string
+		mockBehavior  func(s *mocks.MockStorageInterface)
+		expectedError error
+	}{
+		{
+			name:     "admin of the tenant",
+			tenantID: "acme",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), "acme", "admin", false).Return(newUser("acme", false), nil)
+				s.EXPECT().RecordLogin(gomock.Any(), "acme", "id-acme").Return(nil)
+			},
+		},
+		{
+			name:     "super-admin in another tenant",
+			tenantID: "acme",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), "acme", "admin", false).Return(nil, notFound)
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.DefaultTenant, "admin", false).
+					Return(newUser(models.DefaultTenant, true), nil)
+				s.EXPECT().RecordLogin(gomock.Any(), models.DefaultTenant, "id-default").Return(nil)
+			},
+		},
+		{
+			name:     "super-admin in every tenant",
+			tenantID: models.AllTenants,
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.AllTenants, "admin", false).Return(nil, ErrTenantRequired)
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.DefaultTenant, "admin", false).
+					Return(newUser(models.DefaultTenant, true), nil)
+				s.EXPECT().RecordLogin(gomock.Any(), models.DefaultTenant, "id-default").Return(nil)
+			},
+		},
+		{
+			name:     "admin of the default tenant in another tenant",
+			tenantID: "acme",
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), "acme", "admin", false).Return(nil, notFound)
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.DefaultTenant, "admin", false).
+					Return(newUser(models.DefaultTenant, false), nil)
+			},
+			expectedError: ErrCrossTenant,
+		},
+		{
+			name:     "unknown user in every tenant",
+			tenantID: models.AllTenants,
+			mockBehavior: func(s *mocks.MockStorageInterface) {
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.AllTenants, "admin", false).Return(nil, ErrTenantRequired)
+				s.EXPECT().GetOneUserByUsername(gomock.Any(), models.DefaultTenant, "admin", false).Return(nil, notFound)
+			},
+			expectedError: notFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			testCase.mockBehavior(storage)
+			app := NewApp(logg, storage, validator, "")
+
+			ok, err := app.CheckPassword(WithTenant(context.Background(), testCase.tenantID), "admin", "password")
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+				require.False(t, ok)
+			} else {
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+		})
+	}
+}
+
+func TestAuthorizeToken(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name          string
+		tenantID      string
+		homeTenantID  string
+		user          *models.User
+		expectedError error
+	}{
+		{
+			name:         "admin of the tenant",
+			tenantID:     "acme",
+			homeTenantID: "acme",
+			user:         &models.User{TenantID: "acme", UserName: "alice", Admin: true, Status: models.StatusActive},
+		},
+		{
+			name:          "user of the tenant",
+			tenantID:      "acme",
+			homeTenantID:  "acme",
+			user:          &models.User{TenantID: "acme", UserName: "alice", Status: models.StatusActive},
+			expectedError: ErrNotAdmin,
+		},
+		{
+			name:          "admin in another tenant",
+			tenantID:      "globex",
+			homeTenantID:  "acme",
+			user:          &models.User{TenantID: "acme", UserName: "alice", Admin: true, Status: models.StatusActive},
+			expectedError: ErrCrossTenant,
+		},
+		{
+			name:         "super-admin in every tenant",
+			tenantID:     models.AllTenants,
+			homeTenantID: models.DefaultTenant,
+			user:         &models.User{TenantID: models.DefaultTenant, UserName: "alice", Admin: true, SuperAdmin: true, Status: models.StatusActive},
+		},
+		{
+			name:          "suspended user",
+			tenantID:      "acme",
+			homeTenantID:  "acme",
+			user:          &models.User{TenantID: "acme", UserName: "alice", Admin: true, Status: models.StatusSuspended},
+			expectedError: ErrUserSuspended,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().GetOneUserByUsername(gomock.Any(), testCase.homeTenantID, "alice", false).Return(testCase.user, nil)
+			app := NewApp(logg, storage, validation.New(), "")
+
+			ok, err := app.AuthorizeToken(WithTenant(context.Background(), testCase.tenantID), testCase.homeTenantID, "alice")
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+				require.False(t, ok)
+			} else {
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+		})
+	}
+}
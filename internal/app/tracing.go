@@ -0,0 +1,33 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "github.com/Baraulia/X-Labs_Test/internal/app"
+
+// startSpan starts a child of the request span carried by ctx. Calls outside a sampled request keep ctx
+// untouched and get a no-op span, so untraced work does not pay for span allocation.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, noop.Span{}
+	}
+
+	return otel.Tracer(instrumentationName).Start(ctx, name)
+}
+
+// endSpan records err on span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -5,109 +5,320 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/models"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// loginRecordInterval is the precision of the last login of a user.
+const loginRecordInterval = time.Minute
+
+var (
+	ErrPasswordMismatch = errors.New("password does not match the hash")
+	ErrNotAdmin         = errors.New("user is not an admin")
+)
+
 func (a *App) CreateUser(ctx context.Context, userDTO *models.User) (*models.User, error) {
+	ctx, span := startSpan(ctx, "App.CreateUser")
+	defer span.End()
+
+	if err := a.validateUser(ctx, userDTO); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := a.hashPassword(ctx, userDTO.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	userDTO.Password = hashedPassword
+
+	if userDTO.Status == "" {
+		userDTO.Status = models.StatusActive
+	}
+
+	return a.storage.CreateUser(ctx, TenantFromContext(ctx), userDTO)
+}
+
+func (a *App) UpdateUser(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error {
+	ctx, span := startSpan(ctx, "App.UpdateUser")
+	defer span.End()
+
+	if err := a.validateUpdate(ctx, userDTO, userID); err != nil {
+		return err
+	}
+
+	if userDTO.Password != nil {
+		hashedPassword, err := a.hashPassword(ctx, *userDTO.Password)
+		if err != nil {
+			return err
+		}
+
+		*userDTO.Password = hashedPassword
+	}
+
+	return a.storage.UpdateUser(ctx, TenantFromContext(ctx), userDTO, userID)
+}
+
+func (a *App) hashPassword(ctx context.Context, password string) (string, error) {
+	_, span := startSpan(ctx, "App.hashPassword")
+	start := time.Now()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password+a.SecretKey), bcrypt.DefaultCost)
+	if a.metrics != nil {
+		a.metrics.ObservePasswordHash(time.Since(start))
+	}
+	endSpan(span, err)
+
+	if err != nil {
+		a.log(ctx).Error("Error generating hash", map[string]interface{}{"error": err})
+		return "", fmt.Errorf("error while generate hash: %w", err)
+	}
+
+	return string(hashedPassword), nil
+}
+
+func (a *App) validateUser(ctx context.Context, userDTO *models.User) error {
+	ctx, span := startSpan(ctx, "App.validateUser")
+	defer span.End()
+
 	if len(userDTO.UserName) == 0 {
-		a.logger.Error("empty username", nil)
-		return nil, errors.New("empty username")
+		a.log(ctx).Error("empty username", nil)
+		return errors.New("empty username")
 	}
 
 	if valid := a.validator.IsEmail(userDTO.Email); !valid {
-		a.logger.Error("invalid email", map[string]interface{}{"email": userDTO.Email})
-		return nil, fmt.Errorf("invalid email: %s", userDTO.Email)
+		a.log(ctx).Error("invalid email", map[string]interface{}{"email": userDTO.Email})
+		return fmt.Errorf("invalid email: %s", userDTO.Email)
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userDTO.Password+a.SecretKey), bcrypt.DefaultCost)
-	if err != nil {
-		a.logger.Error("Error generating hash", map[string]interface{}{"error": err})
-		return nil, fmt.Errorf("error while generate hash: %w", err)
+	if err := a.validatePassword(ctx, userDTO.Password); err != nil {
+		return err
 	}
 
-	userDTO.Password = string(hashedPassword)
+	if err := a.validateProfile(ctx, userDTO.Profile); err != nil {
+		return err
+	}
 
-	return a.storage.CreateUser(ctx, userDTO)
+	return a.validateAttributes(ctx, userDTO.Attributes, false)
 }
 
-func (a *App) UpdateUser(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error {
+func (a *App) validateUpdate(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error {
+	ctx, span := startSpan(ctx, "App.validateUpdate")
+	defer span.End()
+
 	_, err := uuid.Parse(userID)
 	if err != nil {
-		a.logger.Error("invalid id(not UUID)", map[string]interface{}{"id": userID})
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": userID})
 		return fmt.Errorf("invalid id(not UUID: %s", userID)
 	}
 
 	if userDTO.Email != nil {
 		valid := a.validator.IsEmail(*userDTO.Email)
 		if !valid {
-			a.logger.Error("invalid email", map[string]interface{}{"email": *userDTO.Email})
+			a.log(ctx).Error("invalid email", map[string]interface{}{"email": *userDTO.Email})
 			return fmt.Errorf("invalid email: %s", *userDTO.Email)
 		}
 	}
 
 	if userDTO.Password != nil {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*userDTO.Password+a.SecretKey), bcrypt.DefaultCost)
-		if err != nil {
-			a.logger.Error("Error generating hash", map[string]interface{}{"error": err})
-			return fmt.Errorf("error while generate hash: %w", err)
+		if err := a.validatePassword(ctx, *userDTO.Password); err != nil {
+			return err
 		}
+	}
 
-		*userDTO.Password = string(hashedPassword)
+	if userDTO.Profile != nil {
+		if err := a.validateProfile(ctx, *userDTO.Profile); err != nil {
+			return err
+		}
 	}
 
-	return a.storage.UpdateUser(ctx, userDTO, userID)
+	if err := a.validateAttributes(ctx, userDTO.Attributes, true); err != nil {
+		return err
+	}
+
+	return a.checkMergedAttributes(ctx, userDTO.Attributes, userID)
 }
 
 func (a *App) DeleteUser(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "App.DeleteUser")
+	defer span.End()
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		return fmt.Errorf("invalid id(not UUID: %s", id)
+	}
+
+	return a.storage.DeleteUser(ctx, TenantFromContext(ctx), id)
+}
+
+func (a *App) UndeleteUser(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "App.UndeleteUser")
+	defer span.End()
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		return fmt.Errorf("invalid id(not UUID: %s", id)
+	}
+
+	return a.storage.UndeleteUser(ctx, TenantFromContext(ctx), id)
+}
+
+func (a *App) PurgeUser(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "App.PurgeUser")
+	defer span.End()
+
 	_, err := uuid.Parse(id)
 	if err != nil {
-		a.logger.Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
 		return fmt.Errorf("invalid id(not UUID: %s", id)
 	}
 
-	return a.storage.DeleteUser(ctx, id)
+	return a.storage.PurgeUser(ctx, TenantFromContext(ctx), id)
 }
 
-func (a *App) GetUsers(ctx context.Context, offset, limit int) ([]models.User, int, error) {
-	return a.storage.GetUsers(ctx, offset, limit)
+func (a *App) GetUsers(ctx context.Context, filter models.UsersFilter) ([]models.User, int, error) {
+	ctx, span := startSpan(ctx, "App.GetUsers")
+	defer span.End()
+
+	return a.storage.GetUsers(ctx, TenantFromContext(ctx), filter)
 }
 
-func (a *App) GetOneUserByID(ctx context.Context, userID string) (*models.User, error) {
+func (a *App) GetOneUserByID(ctx context.Context, userID string, showDeleted bool) (*models.User, error) {
+	ctx, span := startSpan(ctx, "App.GetOneUserByID")
+	defer span.End()
+
 	_, err := uuid.Parse(userID)
 	if err != nil {
-		a.logger.Error("invalid id(not UUID)", map[string]interface{}{"id": userID})
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": userID})
 		return nil, fmt.Errorf("invalid id(not UUID: %s", userID)
 	}
 
-	return a.storage.GetOneUserByID(ctx, userID)
+	return a.storage.GetOneUserByID(ctx, TenantFromContext(ctx), userID, showDeleted)
 }
 
-func (a *App) GetOneUserByUsername(ctx context.Context, userName string) (*models.User, error) {
-	return a.storage.GetOneUserByUsername(ctx, userName)
+func (a *App) GetOneUserByUsername(ctx context.Context, userName string, showDeleted bool) (*models.User, error) {
+	ctx, span := startSpan(ctx, "App.GetOneUserByUsername")
+	defer span.End()
+
+	return a.storage.GetOneUserByUsername(ctx, TenantFromContext(ctx), userName, showDeleted)
 }
 
+// CheckPassword authenticates the user in the tenant of ctx. A super-admin lives in the default tenant and
+// is let into every other one, including models.AllTenants, the other users of the default tenant are not.
 func (a *App) CheckPassword(ctx context.Context, userName, password string) (bool, error) {
-	user, err := a.GetOneUserByUsername(ctx, userName)
+	ctx, span := startSpan(ctx, "App.CheckPassword")
+	defer span.End()
+
+	tenantID := TenantFromContext(ctx)
+	user, err := a.authenticate(ctx, tenantID, userName, password)
+	if err != nil && !isStatusError(err) && tenantID != models.DefaultTenant {
+		home, homeErr := a.authenticate(ctx, models.DefaultTenant, userName, password)
+		switch {
+		case homeErr == nil && home.SuperAdmin:
+			user, err = home, nil
+		case homeErr == nil:
+			err = ErrCrossTenant
+		case tenantID == models.AllTenants:
+			err = homeErr
+		}
+	}
+
 	if err != nil {
 		return false, err
 	}
 
+	a.recordLogin(ctx, user)
+
+	if !user.Admin {
+		return false, ErrNotAdmin
+	}
+
+	return true, nil
+}
+
+// AuthorizeToken checks the subject of a verified bearer token as CheckPassword checks credentials: the user
+// of the home tenant of the token must be active, and only a super-admin may act in another tenant.
+func (a *App) AuthorizeToken(ctx context.Context, homeTenantID, userName string) (bool, error) {
+	ctx, span := startSpan(ctx, "App.AuthorizeToken")
+	defer span.End()
+
+	user, err := a.storage.GetOneUserByUsername(ctx, homeTenantID, userName, false)
+	if err != nil {
+		return false, err
+	}
+
+	if err = checkUserStatus(user); err != nil {
+		return false, err
+	}
+
+	if TenantFromContext(ctx) != homeTenantID && !user.SuperAdmin {
+		return false, ErrCrossTenant
+	}
+
+	if !user.Admin {
+		return false, ErrNotAdmin
+	}
+
+	return true, nil
+}
+
+// recordLogin takes the write lock of the storage, so a client sending Basic credentials with every call only
+// moves the last login of its user once per loginRecordInterval. A login after a wrong password is always
+// recorded, it resets the count of failed logins.
+func (a *App) recordLogin(ctx context.Context, user *models.User) {
+	if user.FailedLogins == 0 && user.LastLoginAt != nil && a.now().Sub(*user.LastLoginAt) < loginRecordInterval {
+		return
+	}
+
+	if err := a.storage.RecordLogin(ctx, user.TenantID, user.ID); err != nil {
+		a.log(ctx).Warn("error while recording login", map[string]interface{}{"id": user.ID, "error": err})
+	}
+}
+
+// recordFailedLogin locks an active user after SetMaxFailedLogins wrong passwords in a row. Super-admins are
+// never locked, otherwise anyone knowing their name could lock every admin out, the rate limits protect them.
+func (a *App) recordFailedLogin(ctx context.Context, user *models.User) {
+	if a.maxFailedLogins <= 0 || user.SuperAdmin || user.Status != models.StatusActive {
+		return
+	}
+
+	locked, err := a.storage.RecordFailedLogin(ctx, user.TenantID, user.ID, a.maxFailedLogins)
+	if err != nil {
+		a.log(ctx).Warn("error while recording failed login", map[string]interface{}{"id": user.ID, "error": err})
+		return
+	}
+
+	if locked {
+		a.log(ctx).Warn("user was locked", map[string]interface{}{"id": user.ID, "failedLogins": a.maxFailedLogins})
+	}
+}
+
+func (a *App) authenticate(ctx context.Context, tenantID, userName, password string) (*models.User, error) {
+	user, err := a.storage.GetOneUserByUsername(ctx, tenantID, userName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := startSpan(ctx, "App.comparePassword")
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password+a.SecretKey))
+	span.End()
 	if err != nil {
 		switch errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 		case true:
-			return false, errors.New("password does not match the hash")
+			a.recordFailedLogin(ctx, user)
+			return nil, ErrPasswordMismatch
 		default:
-			return false, err
+			return nil, err
 		}
 	}
 
-	if !user.Admin {
-		return false, errors.New("user is not an admin")
+	if err = checkUserStatus(user); err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return user, nil
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
 	"github.com/Baraulia/X-Labs_Test/internal/models"
@@ -11,6 +12,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -36,7 +38,7 @@ func TestCreateUser(t *testing.T) {
 				Admin:    false,
 			},
 			mockBehavior: func(s *mocks.MockStorageInterface, dto *models.User) {
-				s.EXPECT().CreateUser(ctx, dto).Return(&models.User{
+				s.EXPECT().CreateUser(ctx, models.DefaultTenant, dto).Return(&models.User{
 					ID:       uuid.New().String(),
 					Email:    "test@gmail.com",
 					UserName: "testUserName",
@@ -112,7 +114,7 @@ func TestUpdateUser(t *testing.T) {
 			},
 			inputID: newUUID,
 			mockBehavior: func(s *mocks.MockStorageInterface, dto models.UpdateUserDTO, userId string) {
-				s.EXPECT().UpdateUser(ctx, dto, userId).Return(nil)
+				s.EXPECT().UpdateUser(ctx, models.DefaultTenant, dto, userId).Return(nil)
 			},
 			expectedError: false,
 		},
@@ -172,7 +174,7 @@ func TestGetOneUserById(t *testing.T) {
 			name:    "successful",
 			inputID: newUUID,
 			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
-				s.EXPECT().GetOneUserByID(ctx, userId).Return(&models.User{
+				s.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, userId, false).Return(&models.User{
 					ID:       uuid.New().String(),
 					Email:    "test@gmail.com",
 					UserName: "testUserName",
@@ -198,7 +200,7 @@ func TestGetOneUserById(t *testing.T) {
 			testCase.mockBehavior(storage, testCase.inputID)
 			app := NewApp(logg, storage, validator, "")
 
-			_, err = app.GetOneUserByID(ctx, testCase.inputID)
+			_, err = app.GetOneUserByID(ctx, testCase.inputID, false)
 			if testCase.expectedError {
 				require.Error(t, err)
 			} else {
@@ -226,7 +228,7 @@ func TestDeleteUser(t *testing.T) {
 			name:    "successful",
 			inputID: newUUID,
 			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
-				s.EXPECT().DeleteUser(ctx, userId).Return(nil)
+				s.EXPECT().DeleteUser(ctx, models.DefaultTenant, userId).Return(nil)
 			},
 			expectedError: false,
 		},
@@ -255,3 +257,123 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUndeleteUser(t *testing.T) {
+	type mockBehavior func(s *mocks.MockStorageInterface, userId string)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	newUUID := uuid.New().String()
+	validator := validation.New()
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		inputID       string
+		mockBehavior  mockBehavior
+		expectedError bool
+	}{
+		{
+			name:    "successful",
+			inputID: newUUID,
+			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
+				s.EXPECT().UndeleteUser(ctx, models.DefaultTenant, userId).Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:          "invalid id",
+			inputID:       "invalid uuid",
+			mockBehavior:  func(s *mocks.MockStorageInterface, userId string) {},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			testCase.mockBehavior(storage, testCase.inputID)
+			app := NewApp(logg, storage, validator, "")
+
+			err = app.UndeleteUser(ctx, testCase.inputID)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+	retention := 24 * time.Hour
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockStorageInterface(c)
+	storage.EXPECT().PurgeDeletedUsers(ctx, models.AllTenants, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, deletedBefore time.Time) (int, error) {
+			require.WithinDuration(t, time.Now().Add(-retention), deletedBefore, time.Second)
+			return 1, nil
+		})
+	app := NewApp(logg, storage, validator, "")
+
+	app.PurgeDeletedUsers(ctx, retention)
+}
+
+func TestCheckPasswordRecordsLogin(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	justNow := now.Add(-loginRecordInterval / 2)
+	longAgo := now.Add(-loginRecordInterval)
+
+	testTable := []struct {
+		name         string
+		lastLoginAt  *time.Time
+		failedLogins int
+		recorded     bool
+	}{
+		{"first login", nil, 0, true},
+		{"login within the interval", &justNow, 0, false},
+		{"login after the interval", &longAgo, 0, true},
+		{"login after a wrong password", &justNow, 2, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().GetOneUserByUsername(ctx, models.DefaultTenant, "admin", false).Return(&models.User{
+				ID:           "id",
+				TenantID:     models.DefaultTenant,
+				UserName:     "admin",
+				Password:     string(hashedPassword),
+				Admin:        true,
+				Status:       models.StatusActive,
+				LastLoginAt:  testCase.lastLoginAt,
+				FailedLogins: testCase.failedLogins,
+			}, nil)
+			if testCase.recorded {
+				storage.EXPECT().RecordLogin(ctx, models.DefaultTenant, "id").Return(nil)
+			}
+			app := NewApp(logg, storage, validation.New(), "")
+			app.SetClock(func() time.Time { return now })
+
+			ok, err := app.CheckPassword(ctx, "admin", "password")
+			require.NoError(t, err)
+			require.True(t, ok)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserPending   = errors.New("user account is pending activation")
+	ErrUserSuspended = errors.New("user account is suspended")
+	ErrUserLocked    = errors.New("user account is locked")
+)
+
+var allowedTransitions = map[models.UserStatus][]models.UserStatus{
+	models.StatusPending:   {models.StatusActive, models.StatusSuspended},
+	models.StatusActive:    {models.StatusSuspended, models.StatusLocked},
+	models.StatusSuspended: {models.StatusActive},
+	models.StatusLocked:    {models.StatusActive},
+}
+
+// SetMaxFailedLogins sets how many wrong passwords in a row lock an active user, zero disables locking. A
+// locked user is unlocked by ReactivateUser.
+func (a *App) SetMaxFailedLogins(maxFailedLogins int) {
+	a.maxFailedLogins = maxFailedLogins
+}
+
+func (a *App) SuspendUser(ctx context.Context, userID, reason string) error {
+	ctx, span := startSpan(ctx, "App.SuspendUser")
+	defer span.End()
+
+	return a.changeUserStatus(ctx, userID, models.StatusSuspended, reason)
+}
+
+func (a *App) ReactivateUser(ctx context.Context, userID, reason string) error {
+	ctx, span := startSpan(ctx, "App.ReactivateUser")
+	defer span.End()
+
+	return a.changeUserStatus(ctx, userID, models.StatusActive, reason)
+}
+
+func (a *App) changeUserStatus(ctx context.Context, userID string, to models.UserStatus, reason string) error {
+	_, err := uuid.Parse(userID)
+	if err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": userID})
+		return fmt.Errorf("invalid id(not UUID: %s", userID)
+	}
+
+	user, err := a.storage.GetOneUserByID(ctx, TenantFromContext(ctx), userID, false)
+	if err != nil {
+		return err
+	}
+
+	if !canTransition(user.Status, to) {
+		a.log(ctx).Error("forbidden status transition", map[string]interface{}{"id": userID, "from": user.Status, "to": to})
+		return fmt.Errorf("status transition from %s to %s is not allowed", user.Status, to)
+	}
+
+	return a.storage.UpdateUserStatus(ctx, TenantFromContext(ctx), userID, user.Status, to, reason)
+}
+
+func canTransition(from, to models.UserStatus) bool {
+	for _, status := range allowedTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkUserStatus(user *models.User) error {
+	switch user.Status {
+	case models.StatusActive:
+		return nil
+	case models.StatusPending:
+		return ErrUserPending
+	case models.StatusSuspended:
+		return ErrUserSuspended
+	case models.StatusLocked:
+		return ErrUserLocked
+	default:
+		return fmt.Errorf("unknown user status: %s", user.Status)
+	}
+}
+
+// isStatusError reports whether the credentials were right but the account may not log in.
+func isStatusError(err error) bool {
+	return errors.Is(err, ErrUserPending) || errors.Is(err, ErrUserSuspended) || errors.Is(err, ErrUserLocked)
+}
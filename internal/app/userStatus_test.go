@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSuspendUser(t *testing.T) {
+	type mockBehavior func(s *mocks.MockStorageInterface, userId string)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	newUUID := uuid.New().String()
+	validator := validation.New()
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		inputID       string
+		mockBehavior  mockBehavior
+		expectedError bool
+	}{
+		{
+			name:    "active user",
+			inputID: newUUID,
+			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
+				s.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, userId, false).Return(&models.User{ID: userId, Status: models.StatusActive}, nil)
+				s.EXPECT().UpdateUserStatus(ctx, models.DefaultTenant, userId, models.StatusActive, models.StatusSuspended, "reason").Return(nil)
+			},
+			expectedError: false,
+		},
+		{
+			name:    "already suspended user",
+			inputID: newUUID,
+			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
+				s.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, userId, false).Return(&models.User{ID: userId, Status: models.StatusSuspended}, nil)
+			},
+			expectedError: true,
+		},
+		{
+			name:    "locked user",
+			inputID: newUUID,
+			mockBehavior: func(s *mocks.MockStorageInterface, userId string) {
+				s.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, userId, false).Return(&models.User{ID: userId, Status: models.StatusLocked}, nil)
+			},
+			expectedError: true,
+		},
+		{
+			name:          "invalid id",
+			inputID:       "invalid uuid",
+			mockBehavior:  func(s *mocks.MockStorageInterface, userId string) {},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			testCase.mockBehavior(storage, testCase.inputID)
+			app := NewApp(logg, storage, validator, "")
+
+			err = app.SuspendUser(ctx, testCase.inputID, "reason")
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReactivateUser(t *testing.T) {
+	type mockBehavior func(s *mocks.MockStorageInterface, userId string)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	newUUID := uuid.New().String()
+	validator := validation.New()
+	ctx := context.Background()
+
+	testTable := []struct {
+		name          string
+		status        models.UserStatus
+		expectedError bool
+	}{
+		{"pending user", models.StatusPending, false},
+		{"suspended user", models.StatusSuspended, false},
+		{"locked user", models.StatusLocked, false},
+		{"active user", models.StatusActive, true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().GetOneUserByID(ctx, models.DefaultTenant, newUUID, false).Return(&models.User{ID: newUUID, Status: testCase.status}, nil)
+			if !testCase.expectedError {
+				storage.EXPECT().UpdateUserStatus(ctx, models.DefaultTenant, newUUID, testCase.status, models.StatusActive, "").Return(nil)
+			}
+			app := NewApp(logg, storage, validator, "")
+
+			err = app.ReactivateUser(ctx, newUUID, "")
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordStatus(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := context.Background()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name          string
+		status        models.UserStatus
+		expectedError error
+	}{
+		{"active user", models.StatusActive, nil},
+		{"pending user", models.StatusPending, ErrUserPending},
+		{"suspended user", models.StatusSuspended, ErrUserSuspended},
+		{"locked user", models.StatusLocked, ErrUserLocked},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().GetOneUserByUsername(ctx, models.DefaultTenant, "admin", false).Return(&models.User{
+				TenantID: models.DefaultTenant,
+				UserName: "admin",
+				Password: string(hashedPassword),
+				Admin:    true,
+				Status:   testCase.status,
+			}, nil)
+			if testCase.expectedError == nil {
+				storage.EXPECT().RecordLogin(ctx, models.DefaultTenant, gomock.Any()).Return(nil)
+			}
+			app := NewApp(logg, storage, validator, "")
+
+			ok, err := app.CheckPassword(ctx, "admin", "password")
+			if testCase.expectedError != nil {
+				require.ErrorIs(t, err, testCase.expectedError)
+				require.False(t, ok)
+			} else {
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordLocksUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	testTable := []struct {
+		name            string
+		maxFailedLogins int
+		superAdmin      bool
+		status          models.UserStatus
+		locked          bool
+		recorded        bool
+	}{
+		{name: "failure counted", maxFailedLogins: 5, status: models.StatusActive, recorded: true},
+		{name: "user locked", maxFailedLogins: 5, status: models.StatusActive, recorded: true, locked: true},
+		{name: "locking disabled", status: models.StatusActive},
+		{name: "super-admin", maxFailedLogins: 5, superAdmin: true, status: models.StatusActive},
+		{name: "suspended user", maxFailedLogins: 5, status: models.StatusSuspended},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			storage := mocks.NewMockStorageInterface(c)
+			storage.EXPECT().GetOneUserByUsername(ctx, models.DefaultTenant, "admin", false).Return(&models.User{
+				ID:         "id",
+				TenantID:   models.DefaultTenant,
+				UserName:   "admin",
+				Password:   string(hashedPassword),
+				Admin:      true,
+				SuperAdmin: testCase.superAdmin,
+				Status:     testCase.status,
+			}, nil)
+			if testCase.recorded {
+				storage.EXPECT().RecordFailedLogin(ctx, models.DefaultTenant, "id", testCase.maxFailedLogins).Return(testCase.locked, nil)
+			}
+			app := NewApp(logg, storage, validation.New(), "")
+			app.SetMaxFailedLogins(testCase.maxFailedLogins)
+
+			ok, err := app.CheckPassword(ctx, "admin", "wrong")
+			require.ErrorIs(t, err, ErrPasswordMismatch, "the lock is not revealed to the caller")
+			require.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"errors"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+var ErrSequenceExpired = errors.New("requested sequence is no longer available")
+
+func (a *App) WatchUsers(ctx context.Context, afterSequence uint64, fromNow bool, send func(models.UserEvent) error) error {
+	return a.storage.WatchUsers(ctx, TenantFromContext(ctx), afterSequence, fromNow, send)
+}
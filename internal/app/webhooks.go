@@ -0,0 +1,146 @@
+package app
+
+//nolint:depguard
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+const webhookSecretSize = 32
+
+var ErrWebhooksDisabled = errors.New("webhooks are not configured")
+
+//go:generate mockgen -destination mocks/webhookStorageMock.go -package mocks github.com/Baraulia/X-Labs_Test/internal/app WebhookStorage
+type WebhookStorage interface {
+	CreateWebhook(ctx context.Context, tenantID string, webhook *models.Webhook) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, tenantID, webhookID string) error
+	GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error)
+	GetWebhooks(ctx context.Context, tenantID string) ([]models.Webhook, error)
+	EnqueueDeliveries(ctx context.Context, sequence uint64, deliveries []models.WebhookDelivery) error
+	LastSequence(ctx context.Context) (uint64, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	CompleteDelivery(ctx context.Context, deliveryID string) error
+	RescheduleDelivery(ctx context.Context, deliveryID string, nextAttemptAt time.Time, lastError string) error
+	DeadLetterDelivery(ctx context.Context, deliveryID string, lastError string) error
+	GetDeadLetters(ctx context.Context, tenantID string, offset, limit int) ([]models.WebhookDelivery, int, error)
+	RetryDeadLetter(ctx context.Context, tenantID, deliveryID string) error
+}
+
+func (a *App) SetWebhookStorage(webhooks WebhookStorage) {
+	a.webhooks = webhooks
+}
+
+// CreateWebhook registers a subscription. When no secret is supplied a random one is generated;
+// the returned webhook is the only place the caller can read it.
+func (a *App) CreateWebhook(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	ctx, span := startSpan(ctx, "App.CreateWebhook")
+	defer span.End()
+
+	if a.webhooks == nil {
+		return nil, ErrWebhooksDisabled
+	}
+
+	if err := a.validateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	if webhook.Secret == "" {
+		secret := make([]byte, webhookSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			a.log(ctx).Error("Error generating webhook secret", map[string]interface{}{"error": err})
+			return nil, fmt.Errorf("error while generate webhook secret: %w", err)
+		}
+
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return a.webhooks.CreateWebhook(ctx, TenantFromContext(ctx), webhook)
+}
+
+func (a *App) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "App.DeleteWebhook")
+	defer span.End()
+
+	if a.webhooks == nil {
+		return ErrWebhooksDisabled
+	}
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		return fmt.Errorf("invalid id(not UUID: %s", id)
+	}
+
+	return a.webhooks.DeleteWebhook(ctx, TenantFromContext(ctx), id)
+}
+
+func (a *App) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	ctx, span := startSpan(ctx, "App.GetWebhooks")
+	defer span.End()
+
+	if a.webhooks == nil {
+		return nil, ErrWebhooksDisabled
+	}
+
+	return a.webhooks.GetWebhooks(ctx, TenantFromContext(ctx))
+}
+
+func (a *App) GetDeadLetters(ctx context.Context, offset, limit int) ([]models.WebhookDelivery, int, error) {
+	ctx, span := startSpan(ctx, "App.GetDeadLetters")
+	defer span.End()
+
+	if a.webhooks == nil {
+		return nil, 0, ErrWebhooksDisabled
+	}
+
+	return a.webhooks.GetDeadLetters(ctx, TenantFromContext(ctx), offset, limit)
+}
+
+func (a *App) RetryDeadLetter(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "App.RetryDeadLetter")
+	defer span.End()
+
+	if a.webhooks == nil {
+		return ErrWebhooksDisabled
+	}
+
+	_, err := uuid.Parse(id)
+	if err != nil {
+		a.log(ctx).Error("invalid id(not UUID)", map[string]interface{}{"id": id})
+		return fmt.Errorf("invalid id(not UUID: %s", id)
+	}
+
+	return a.webhooks.RetryDeadLetter(ctx, TenantFromContext(ctx), id)
+}
+
+func (a *App) validateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		a.log(ctx).Error("invalid webhook url", map[string]interface{}{"url": webhook.URL})
+		return fmt.Errorf("invalid webhook url: %s", webhook.URL)
+	}
+
+	if len(webhook.EventTypes) == 0 {
+		a.log(ctx).Error("webhook without event types", nil)
+		return errors.New("webhook must subscribe to at least one event type")
+	}
+
+	for _, eventType := range webhook.EventTypes {
+		switch eventType {
+		case models.EventUserCreated, models.EventUserUpdated, models.EventUserDeleted:
+		default:
+			a.log(ctx).Error("invalid webhook event type", map[string]interface{}{"eventType": eventType})
+			return fmt.Errorf("invalid webhook event type: %s", eventType)
+		}
+	}
+
+	return nil
+}
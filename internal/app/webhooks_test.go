@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app/mocks"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	type mockBehavior func(s *mocks.MockWebhookStorage, webhook *models.Webhook)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	validator := validation.New()
+	ctx := WithTenant(context.Background(), "acme")
+
+	testTable := []struct {
+		name          string
+		inputData     models.Webhook
+		mockBehavior  mockBehavior
+		expectedError bool
+	}{
+		{
+			name: "successful",
+			inputData: models.Webhook{
+				URL:        "https://crm.example.com/hooks/users",
+				EventTypes: []models.UserEventType{models.EventUserCreated, models.EventUserDeleted},
+				Secret:     "secret",
+			},
+			mockBehavior: func(s *mocks.MockWebhookStorage, webhook *models.Webhook) {
+				s.EXPECT().CreateWebhook(ctx, "acme", webhook).Return(webhook, nil)
+			},
+			expectedError: false,
+		},
+		{
+			name: "generated secret",
+			inputData: models.Webhook{
+				URL:        "http://billing:8080/events",
+				EventTypes: []models.UserEventType{models.EventUserUpdated},
+			},
+			mockBehavior: func(s *mocks.MockWebhookStorage, webhook *models.Webhook) {
+				s.EXPECT().CreateWebhook(ctx, "acme", webhook).DoAndReturn(
+					func(_ context.Context, _ string, webhook *models.Webhook) (*models.Webhook, error) {
+						require.Len(t, webhook.Secret, 2*webhookSecretSize)
+						return webhook, nil
+					})
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid scheme",
+			inputData: models.Webhook{
+				URL:        "ftp://crm.example.com/hooks",
+				EventTypes: []models.UserEventType{models.EventUserCreated},
+			},
+			mockBehavior:  func(s *mocks.MockWebhookStorage, webhook *models.Webhook) {},
+			expectedError: true,
+		},
+		{
+			name: "without event types",
+			inputData: models.Webhook{
+				URL: "https://crm.example.com/hooks/users",
+			},
+			mockBehavior:  func(s *mocks.MockWebhookStorage, webhook *models.Webhook) {},
+			expectedError: true,
+		},
+		{
+			name: "unknown event type",
+			inputData: models.Webhook{
+				URL:        "https://crm.example.com/hooks/users",
+				EventTypes: []models.UserEventType{"renamed"},
+			},
+			mockBehavior:  func(s *mocks.MockWebhookStorage, webhook *models.Webhook) {},
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			webhooks := mocks.NewMockWebhookStorage(c)
+			testCase.mockBehavior(webhooks, &testCase.inputData)
+			app := NewApp(logg, mocks.NewMockStorageInterface(c), validator, "")
+			app.SetWebhookStorage(webhooks)
+
+			_, err = app.CreateWebhook(ctx, &testCase.inputData)
+			if testCase.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWebhooksDisabled(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+	app := NewApp(logg, mocks.NewMockStorageInterface(c), validation.New(), "")
+
+	_, err = app.GetWebhooks(context.Background())
+	require.ErrorIs(t, err, ErrWebhooksDisabled)
+}
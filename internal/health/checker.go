@@ -0,0 +1,143 @@
+package health
+
+//nolint:depguard
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Probe func(ctx context.Context) error
+
+// Checker backs grpc.health.v1 with one status per dependency. Probes are polled, components report
+// their own status through SetStatus. The overall status (empty service name and every name
+// passed to NewChecker) is SERVING only while every dependency is.
+type Checker struct {
+	mu       sync.Mutex
+	server   *health.Server
+	logger   app.Logger
+	services []string
+	probes   map[string]Probe
+	statuses map[string]bool
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func NewChecker(logger app.Logger, interval, timeout time.Duration, services ...string) *Checker {
+	c := &Checker{
+		server:   health.NewServer(),
+		logger:   logger,
+		services: append([]string{""}, services...),
+		probes:   make(map[string]Probe),
+		statuses: make(map[string]bool),
+		interval: interval,
+		timeout:  timeout,
+	}
+	c.updateOverall()
+
+	return c
+}
+
+func (c *Checker) Server() *health.Server {
+	return c.server
+}
+
+// AddProbe registers a dependency that is NOT_SERVING until its first successful check.
+func (c *Checker) AddProbe(name string, probe Probe) {
+	c.mu.Lock()
+	c.probes[name] = probe
+	c.mu.Unlock()
+
+	c.SetStatus(name, false)
+}
+
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown switches every service to NOT_SERVING for good, so load balancers stop routing new calls
+// while GracefulStop drains the running ones.
+func (c *Checker) Shutdown() {
+	c.server.Shutdown()
+}
+
+func (c *Checker) check(ctx context.Context) {
+	c.mu.Lock()
+	probes := make(map[string]Probe, len(c.probes))
+	for name, probe := range c.probes {
+		probes[name] = probe
+	}
+	c.mu.Unlock()
+
+	for name, probe := range probes {
+		probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := runProbe(probeCtx, probe)
+		cancel()
+
+		if err != nil && ctx.Err() == nil {
+			c.logger.Warn("health probe failed", map[string]interface{}{"dependency": name, "error": err})
+		}
+
+		c.SetStatus(name, err == nil)
+	}
+}
+
+// runProbe does not wait for a probe stuck past its deadline, e.g. on a deadlocked storage mutex.
+func runProbe(ctx context.Context, probe Probe) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- probe(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetStatus reports a dependency, e.g. a background worker, the lifecycle reports components through it.
+func (c *Checker) SetStatus(name string, serving bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.statuses[name] = serving
+	c.server.SetServingStatus(name, servingStatus(serving))
+	c.updateOverall()
+}
+
+// updateOverall must be called with mu held, so concurrent updates cannot publish a stale overall status.
+func (c *Checker) updateOverall() {
+	serving := true
+	for _, dependencyServing := range c.statuses {
+		serving = serving && dependencyServing
+	}
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, servingStatus(serving))
+	}
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
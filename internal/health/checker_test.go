@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func statusOf(t *testing.T, checker *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := checker.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return resp.Status
+}
+
+func newTestChecker(t *testing.T) *Checker {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	return NewChecker(logg, 5*time.Millisecond, 50*time.Millisecond, "user.UserService")
+}
+
+func TestProbes(t *testing.T) {
+	checker := newTestChecker(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var healthy atomic.Bool
+	checker.AddProbe("storage", func(context.Context) error {
+		if healthy.Load() {
+			return nil
+		}
+
+		return errors.New("unreachable")
+	})
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, ""))
+
+	go checker.Run(ctx)
+
+	healthy.Store(true)
+	require.Eventually(t, func() bool {
+		return statusOf(t, checker, "") == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, checker, "user.UserService"))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, checker, "storage"))
+
+	healthy.Store(false)
+	require.Eventually(t, func() bool {
+		return statusOf(t, checker, "storage") == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, ""))
+}
+
+func TestStuckProbe(t *testing.T) {
+	checker := newTestChecker(t)
+	block := make(chan struct{})
+	defer close(block)
+
+	checker.AddProbe("storage", func(context.Context) error {
+		<-block
+		return nil
+	})
+
+	checker.check(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, "storage"))
+}
+
+func TestSetStatus(t *testing.T) {
+	checker := newTestChecker(t)
+
+	checker.SetStatus("worker.crashing", true)
+	checker.SetStatus("worker.stable", true)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, checker, ""))
+
+	checker.SetStatus("worker.crashing", false)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, "worker.crashing"))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, checker, "worker.stable"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, "user.UserService"))
+}
+
+func TestShutdown(t *testing.T) {
+	checker := newTestChecker(t)
+	checker.AddProbe("storage", func(context.Context) error { return nil })
+	checker.check(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(t, checker, ""))
+
+	checker.Shutdown()
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, "user.UserService"))
+
+	checker.check(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(t, checker, ""), "shutdown is final")
+}
@@ -0,0 +1,151 @@
+package metrics
+
+//nolint:depguard
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/storage/instrumented"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "xlabs"
+
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	auth         *prometheus.CounterVec
+	passwordHash prometheus.Histogram
+	storage      *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of handled gRPC requests by method and status code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of handled gRPC requests by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		auth: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_attempts_total",
+			Help:      "Authentication attempts by result and reason.",
+		}, []string{"result", "reason"}),
+		passwordHash: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "password_hash_duration_seconds",
+			Help:      "Time spent computing bcrypt password hashes.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+		}),
+		storage: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Latency of storage operations by backend, operation and result.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{"backend", "operation", "result"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests, m.latency, m.auth, m.passwordHash, m.storage,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterUserCount exposes the number of users that are not soft-deleted, computed on every scrape.
+func (m *Metrics) RegisterUserCount(count func(ctx context.Context) (int, error)) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "users",
+		Help:      "Current number of users.",
+	}, func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		users, err := count(ctx)
+		if err != nil {
+			return 0
+		}
+
+		return float64(users)
+	}))
+}
+
+func (m *Metrics) ObservePasswordHash(duration time.Duration) {
+	m.passwordHash.Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveAuth(success bool, reason string) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	m.auth.WithLabelValues(result, reason).Inc()
+}
+
+func (m *Metrics) UnaryInterceptor(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observeRequest(info.FullMethod, start, err)
+
+	return resp, err
+}
+
+func (m *Metrics) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observeRequest(info.FullMethod, start, err)
+
+	return err
+}
+
+func (m *Metrics) observeRequest(method string, start time.Time, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		// the grpc server reports bare context errors as Canceled/DeadlineExceeded, so the metric does too
+		st = status.FromContextError(err)
+	}
+	code := st.Code().String()
+
+	m.requests.WithLabelValues(method, code).Inc()
+	m.latency.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// StorageObserver times every storage operation of the given backend.
+func (m *Metrics) StorageObserver(backend string) instrumented.Observer {
+	return func(ctx context.Context, operation string) (context.Context, func(err error)) {
+		start := time.Now()
+
+		return ctx, func(err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+
+			m.storage.WithLabelValues(backend, operation, result).Observe(time.Since(start).Seconds())
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	memorystorage "github.com/Baraulia/X-Labs_Test/internal/storage/inMemory"
+	"github.com/Baraulia/X-Labs_Test/internal/storage/instrumented"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	m := New()
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUsers"}
+
+	_, err := m.UnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = m.UnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.PermissionDenied, "denied")
+		})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(info.FullMethod, "OK")))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.requests.WithLabelValues(info.FullMethod, "PermissionDenied")))
+	require.Equal(t, 2, testutil.CollectAndCount(m.latency))
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	m := New()
+	info := &grpc.StreamServerInfo{FullMethod: "/user.UserService/WatchUsers"}
+
+	err := m.StreamInterceptor(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return context.Canceled
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.requests.WithLabelValues(info.FullMethod, "Canceled")))
+}
+
+func TestObserveAuthAndPasswordHash(t *testing.T) {
+	m := New()
+
+	m.ObserveAuth(true, "admin")
+	m.ObserveAuth(false, "invalid_credentials")
+	m.ObserveAuth(false, "invalid_credentials")
+	m.ObservePasswordHash(50 * time.Millisecond)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.auth.WithLabelValues("success", "admin")))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.auth.WithLabelValues("failure", "invalid_credentials")))
+	require.Equal(t, 1, testutil.CollectAndCount(m.passwordHash))
+}
+
+func TestStorageObserver(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	m := New()
+	storage := instrumented.New(memorystorage.NewUserStorage(logg), m.StorageObserver("memory"))
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "metrics@gmail.com", UserName: "metrics"})
+	require.NoError(t, err)
+	_, err = storage.GetOneUserByID(ctx, models.DefaultTenant, user.ID, false)
+	require.NoError(t, err)
+	_, err = storage.GetOneUserByUsername(ctx, models.DefaultTenant, "missing", false)
+	require.Error(t, err)
+
+	expected := map[string]uint64{"CreateUser/ok": 1, "GetOneUserByID/ok": 1, "GetOneUserByUsername/error": 1}
+	families, err := m.registry.Gather()
+	require.NoError(t, err)
+
+	observed := make(map[string]uint64)
+	for _, family := range families {
+		if family.GetName() != "xlabs_storage_operation_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			require.Equal(t, "memory", labels["backend"])
+			observed[labels["operation"]+"/"+labels["result"]] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	require.Equal(t, expected, observed)
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	users := 3
+	m.RegisterUserCount(func(ctx context.Context) (int, error) {
+		return users, nil
+	})
+	m.ObserveAuth(false, "anonymous")
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	scrape := func() string {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return string(body)
+	}
+
+	body := scrape()
+	require.Contains(t, body, "xlabs_users 3")
+	require.Contains(t, body, `xlabs_auth_attempts_total{reason="anonymous",result="failure"} 1`)
+	require.Contains(t, body, "go_goroutines")
+
+	users = 5
+	require.Contains(t, scrape(), "xlabs_users 5")
+}
+
+func TestUserCountError(t *testing.T) {
+	m := New()
+	m.RegisterUserCount(func(ctx context.Context) (int, error) {
+		return 0, errors.New("storage is unavailable")
+	})
+
+	families, err := m.registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if strings.HasSuffix(family.GetName(), "_users") {
+			require.Equal(t, 0.0, family.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type Group struct {
+	ID          string
+	TenantID    string
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type UpdateGroupDTO struct {
+	Name        *string
+	Description *string
+}
+
+type MemberType string
+
+const (
+	MemberUser  MemberType = "user"
+	MemberGroup MemberType = "group"
+)
+
+// GroupMember is either a user or a nested group, the members of a nested group are members of its parents.
+type GroupMember struct {
+	Type MemberType
+	ID   string
+}
+
+type GroupMembers struct {
+	Users  []User
+	Groups []Group
+}
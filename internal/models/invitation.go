@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Invitation belongs to a pending user created with it. Only the SHA-256 hash of the token is kept,
+// the token itself is handed to the inviting admin once.
+type Invitation struct {
+	ID        string
+	TenantID  string
+	UserID    string
+	Email     string
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
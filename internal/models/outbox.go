@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+type OutboxRecord struct {
+	ID          string
+	Event       UserEvent
+	CreatedAt   time.Time
+	DeliveredAt *time.Time
+}
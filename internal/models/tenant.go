@@ -0,0 +1,8 @@
+package models
+
+const (
+	// DefaultTenant holds the users of requests that name no tenant, e.g. the admin created at startup.
+	DefaultTenant = "default"
+	// AllTenants selects the users of every tenant, only a super-admin may use it.
+	AllTenants = "*"
+)
@@ -1,15 +1,84 @@
 package models
 
+import "time"
+
+type UserStatus string
+
+const (
+	StatusPending   UserStatus = "pending"
+	StatusActive    UserStatus = "active"
+	StatusSuspended UserStatus = "suspended"
+	StatusLocked    UserStatus = "locked"
+)
+
+type Profile struct {
+	DisplayName string
+	Locale      string
+	TimeZone    string
+	PhoneNumber string
+}
+
 type User struct {
-	ID       string
-	Email    string
-	UserName string
-	Password string
-	Admin    bool
+	ID           string
+	TenantID     string
+	Email        string
+	UserName     string
+	Password     string
+	Admin        bool
+	SuperAdmin   bool
+	Status       UserStatus
+	StatusReason string
+	Profile      Profile
+	Attributes   map[string]string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	LastLoginAt  *time.Time
+	FailedLogins int
+	DeletedAt    *time.Time
 }
 
 type UpdateUserDTO struct {
-	Email    *string
-	UserName *string
-	Password *string
+	Email      *string
+	UserName   *string
+	Password   *string
+	Profile    *Profile
+	Attributes map[string]string
+}
+
+type UserEventType string
+
+const (
+	EventUserCreated UserEventType = "created"
+	EventUserUpdated UserEventType = "updated"
+	EventUserDeleted UserEventType = "deleted"
+)
+
+type UserEvent struct {
+	Sequence   uint64
+	Type       UserEventType
+	User       User
+	OccurredAt time.Time
+}
+
+type UserUpdate struct {
+	ID  string
+	DTO UpdateUserDTO
+}
+
+type BatchResult struct {
+	User *User
+	Err  error
+}
+
+type UsersFilter struct {
+	Offset          int
+	Limit           int
+	ShowDeleted     bool
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	UpdatedAfter    *time.Time
+	UpdatedBefore   *time.Time
+	LastLoginAfter  *time.Time
+	LastLoginBefore *time.Time
+	Attributes      map[string]string
 }
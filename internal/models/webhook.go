@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Webhook struct {
+	ID         string
+	TenantID   string
+	URL        string
+	EventTypes []UserEventType
+	Secret     string
+	CreatedAt  time.Time
+}
+
+type WebhookDelivery struct {
+	ID            string
+	TenantID      string
+	WebhookID     string
+	Event         UserEvent
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
@@ -0,0 +1,35 @@
+package outbox
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// ChannelPublisher hands records to in-process consumers reading from Records.
+type ChannelPublisher struct {
+	records chan models.OutboxRecord
+}
+
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{records: make(chan models.OutboxRecord, buffer)}
+}
+
+func (p *ChannelPublisher) Records() <-chan models.OutboxRecord {
+	return p.records
+}
+
+func (p *ChannelPublisher) Publish(ctx context.Context, record models.OutboxRecord) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case p.records <- record:
+		return nil
+	}
+}
+
+func (p *ChannelPublisher) Close() error {
+	close(p.records)
+	return nil
+}
@@ -0,0 +1,49 @@
+package outbox
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// FilePublisher appends one JSON document per line and syncs the file after every record.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening outbox file: %w", err)
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(_ context.Context, record models.OutboxRecord) error {
+	body, err := Encode(record)
+	if err != nil {
+		return fmt.Errorf("error while encoding outbox record: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err = p.file.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("error while writing outbox file: %w", err)
+	}
+
+	return p.file.Sync()
+}
+
+func (p *FilePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.file.Close()
+}
@@ -0,0 +1,70 @@
+package outbox
+
+//nolint:depguard
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// Message is the JSON representation of a user event shared by the outbox publishers and webhooks.
+type Message struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	Sequence   uint64      `json:"sequence"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	User       MessageUser `json:"user"`
+}
+
+type MessageUser struct {
+	ID           string            `json:"id"`
+	TenantID     string            `json:"tenantId"`
+	Email        string            `json:"email"`
+	UserName     string            `json:"username"`
+	Admin        bool              `json:"admin"`
+	Status       string            `json:"status"`
+	StatusReason string            `json:"statusReason,omitempty"`
+	DisplayName  string            `json:"displayName,omitempty"`
+	Locale       string            `json:"locale,omitempty"`
+	TimeZone     string            `json:"timeZone,omitempty"`
+	PhoneNumber  string            `json:"phoneNumber,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	DeletedAt    *time.Time        `json:"deletedAt,omitempty"`
+}
+
+// NewMessage never copies the password hash into the message.
+func NewMessage(id string, event models.UserEvent) Message {
+	user := event.User
+
+	return Message{
+		ID:         id,
+		Event:      "user." + string(event.Type),
+		Sequence:   event.Sequence,
+		OccurredAt: event.OccurredAt,
+		User: MessageUser{
+			ID:           user.ID,
+			TenantID:     user.TenantID,
+			Email:        user.Email,
+			UserName:     user.UserName,
+			Admin:        user.Admin,
+			Status:       string(user.Status),
+			StatusReason: user.StatusReason,
+			DisplayName:  user.Profile.DisplayName,
+			Locale:       user.Profile.Locale,
+			TimeZone:     user.Profile.TimeZone,
+			PhoneNumber:  user.Profile.PhoneNumber,
+			Attributes:   user.Attributes,
+			CreatedAt:    user.CreatedAt,
+			UpdatedAt:    user.UpdatedAt,
+			DeletedAt:    user.DeletedAt,
+		},
+	}
+}
+
+// Encode is the body written by the NATS and file publishers.
+func Encode(record models.OutboxRecord) ([]byte, error) {
+	return json.Marshal(NewMessage(record.ID, record.Event))
+}
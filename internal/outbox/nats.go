@@ -0,0 +1,150 @@
+package outbox
+
+//nolint:depguard
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// NATSPublisher speaks the NATS client protocol over plain TCP, so it works with nats-server and compatible
+// brokers without pulling in a client library. Every PUB is followed by PING and confirmed by the PONG,
+// which guarantees the broker has processed the message before the record is marked delivered.
+// Records are published to "<subject>.<event type>", e.g. "users.created".
+type NATSPublisher struct {
+	mu      sync.Mutex
+	address string
+	subject string
+	timeout time.Duration
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+func NewNATSPublisher(address, subject string, timeout time.Duration) *NATSPublisher {
+	return &NATSPublisher{
+		address: strings.TrimPrefix(address, "nats://"),
+		subject: subject,
+		timeout: timeout,
+	}
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, record models.OutboxRecord) error {
+	body, err := Encode(record)
+	if err != nil {
+		return fmt.Errorf("error while encoding outbox record: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err = p.publish(ctx, p.subject+"."+string(record.Event.Type), body); err != nil {
+		p.close()
+		return err
+	}
+
+	return nil
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.close()
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, subject string, body []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\nPING\r\n", subject, len(body), body); err != nil {
+		return fmt.Errorf("error while publishing to nats: %w", err)
+	}
+
+	return p.awaitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("error while connecting to nats: %w", err)
+	}
+
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+
+	if err = conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error while reading nats INFO: %w", err)
+	}
+
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("unexpected nats greeting: %q", strings.TrimSpace(line))
+	}
+
+	if _, err = fmt.Fprint(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"x-labs-users\"}\r\nPING\r\n"); err != nil {
+		return fmt.Errorf("error while sending nats CONNECT: %w", err)
+	}
+
+	return p.awaitPong()
+}
+
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("error while waiting for nats PONG: %w", err)
+		}
+
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err = fmt.Fprint(p.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(p.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+
+	return deadline
+}
+
+func (p *NATSPublisher) close() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+
+	return err
+}
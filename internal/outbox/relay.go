@@ -0,0 +1,104 @@
+package outbox
+
+//nolint:depguard
+import (
+	"context"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+type Storage interface {
+	FetchOutbox(ctx context.Context, limit int) ([]models.OutboxRecord, error)
+	MarkOutboxDelivered(ctx context.Context, recordIDs []string) error
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, record models.OutboxRecord) error
+	Close() error
+}
+
+// Relay moves outbox records to a Publisher. Records are marked delivered only after Publish succeeded,
+// so delivery is at-least-once: consumers should deduplicate by record ID.
+type Relay struct {
+	logger    app.Logger
+	storage   Storage
+	publisher Publisher
+	batchSize int
+	interval  time.Duration
+}
+
+func NewRelay(logger app.Logger, storage Storage, publisher Publisher, batchSize int, interval time.Duration) *Relay {
+	return &Relay{
+		logger:    logger,
+		storage:   storage,
+		publisher: publisher,
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	defer func() {
+		if err := r.publisher.Close(); err != nil {
+			r.logger.Error("error while closing outbox publisher", map[string]interface{}{"error": err})
+		}
+	}()
+
+	if r.interval <= 0 || r.batchSize <= 0 {
+		r.logger.Warn("outbox relay is disabled", map[string]interface{}{"interval": r.interval, "batchSize": r.batchSize})
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay publishes pending records until the outbox is drained or a publish fails. A failed record
+// blocks the ones after it, which keeps the published order identical to the commit order.
+func (r *Relay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		records, err := r.storage.FetchOutbox(ctx, r.batchSize)
+		if err != nil {
+			r.logger.Error("error while fetching outbox", map[string]interface{}{"error": err})
+			return
+		}
+
+		if len(records) == 0 {
+			return
+		}
+
+		delivered := make([]string, 0, len(records))
+		for _, record := range records {
+			if err = r.publisher.Publish(ctx, record); err != nil {
+				r.logger.Error("error while publishing outbox record",
+					map[string]interface{}{"id": record.ID, "sequence": record.Event.Sequence, "error": err})
+
+				break
+			}
+
+			delivered = append(delivered, record.ID)
+		}
+
+		if len(delivered) > 0 {
+			if markErr := r.storage.MarkOutboxDelivered(ctx, delivered); markErr != nil {
+				r.logger.Error("error while marking outbox records delivered", map[string]interface{}{"error": markErr})
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	memorystorage "github.com/Baraulia/X-Labs_Test/internal/storage/inMemory"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []models.OutboxRecord
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, record models.OutboxRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("broker unavailable")
+	}
+
+	p.published = append(p.published, record)
+
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	return nil
+}
+
+func newTestStorage(t *testing.T) *memorystorage.UserStorage {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	storage := memorystorage.NewUserStorage(logg)
+	storage.SetOutboxEnabled(true)
+
+	return storage
+}
+
+func createUsers(t *testing.T, storage *memorystorage.UserStorage, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		_, err := storage.CreateUser(context.Background(), models.DefaultTenant, &models.User{
+			Email:    fmt.Sprintf("user%d@gmail.com", i),
+			UserName: fmt.Sprintf("user%d", i),
+			Password: "$2a$10$hash",
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestRelayChannelPublisher(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := newTestStorage(t)
+	createUsers(t, storage, 3)
+
+	publisher := NewChannelPublisher(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewRelay(logg, storage, publisher, 2, 5*time.Millisecond).Run(ctx)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case record := <-publisher.Records():
+			require.Equal(t, uint64(i+1), record.Event.Sequence)
+			require.Equal(t, models.EventUserCreated, record.Event.Type)
+		case <-time.After(time.Second):
+			t.Fatal("record was not relayed")
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		records, err := storage.FetchOutbox(context.Background(), 10)
+		return err == nil && len(records) == 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRelayKeepsOrderOnFailure(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := newTestStorage(t)
+	createUsers(t, storage, 3)
+	ctx := context.Background()
+
+	publisher := &flakyPublisher{failures: 1}
+	relay := NewRelay(logg, storage, publisher, 10, time.Millisecond)
+
+	relay.relay(ctx)
+	require.Empty(t, publisher.published)
+
+	records, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3, "failed records stay in the outbox")
+
+	relay.relay(ctx)
+	require.Len(t, publisher.published, 3)
+	for i, record := range publisher.published {
+		require.Equal(t, uint64(i+1), record.Event.Sequence)
+	}
+
+	records, err = storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	record := models.OutboxRecord{
+		ID: "record",
+		Event: models.UserEvent{
+			Sequence: 7,
+			Type:     models.EventUserDeleted,
+			User:     models.User{ID: "user", UserName: "testUserName", Password: "$2a$10$hash"},
+		},
+	}
+	require.NoError(t, publisher.Publish(context.Background(), record))
+	require.NoError(t, publisher.Publish(context.Background(), record))
+	require.NoError(t, publisher.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	require.NotContains(t, string(content), "$2a$10$hash")
+
+	var received Message
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &received))
+	require.Equal(t, "user.deleted", received.Event)
+	require.Equal(t, uint64(7), received.Sequence)
+	require.Equal(t, "testUserName", received.User.UserName)
+}
+
+// natsServer accepts one client and records the PUB commands it receives.
+func natsServer(t *testing.T, published chan<- string) string {
+	t.Helper()
+
+	lsn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lsn.Close() })
+
+	go func() {
+		conn, err := lsn.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch {
+			case strings.HasPrefix(line, "PING"):
+				fmt.Fprint(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				payload, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				published <- strings.TrimSpace(line) + " " + strings.TrimSpace(payload)
+			}
+		}
+	}()
+
+	return "nats://" + lsn.Addr().String()
+}
+
+func TestNATSPublisher(t *testing.T) {
+	published := make(chan string, 1)
+	publisher := NewNATSPublisher(natsServer(t, published), "users", time.Second)
+	defer publisher.Close()
+
+	record := models.OutboxRecord{
+		ID:    "record",
+		Event: models.UserEvent{Sequence: 1, Type: models.EventUserCreated, User: models.User{ID: "user"}},
+	}
+	require.NoError(t, publisher.Publish(context.Background(), record))
+
+	body, err := Encode(record)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("PUB users.created %d %s", len(body), body), <-published)
+}
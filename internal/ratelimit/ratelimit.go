@@ -0,0 +1,129 @@
+package ratelimit
+
+//nolint:depguard
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryBackend drops buckets that have refilled completely.
+const sweepInterval = time.Minute
+
+// Limit allows Rate requests per second on average and bursts of up to Burst requests.
+// A non-positive Rate means no limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+
+	return float64(l.Burst)
+}
+
+// Limits is the limit of every method of a client: methods listed in Methods by their short name
+// ("GetUsers", case-insensitive) have their own bucket, all the others share the Default one.
+type Limits struct {
+	Default Limit
+	Methods map[string]Limit
+}
+
+// For returns the limit of fullMethod and the name of the bucket it takes tokens from.
+func (l Limits) For(fullMethod string) (Limit, string) {
+	name := strings.ToLower(fullMethod[strings.LastIndex(fullMethod, "/")+1:])
+	for method, limit := range l.Methods {
+		if strings.ToLower(method) == name {
+			return limit, name
+		}
+	}
+
+	return l.Default, "*"
+}
+
+// Backend takes one token from the bucket of key and, when the bucket is empty, reports how long to wait
+// for the next one. A backend shared by several instances, e.g. one on top of Redis, makes the limits
+// global instead of per instance.
+type Backend interface {
+	Take(ctx context.Context, key string, limit Limit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+type bucket struct {
+	tokens  float64
+	limit   Limit
+	updated time.Time
+}
+
+// refill adds the tokens earned since the last update, a bucket never holds more than the burst.
+func (b *bucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.updated).Seconds() * b.limit.Rate
+	if burst := b.limit.burst(); b.tokens > burst {
+		b.tokens = burst
+	}
+
+	b.updated = now
+}
+
+// MemoryBackend keeps token buckets in process memory, it is the default Backend.
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{buckets: make(map[string]*bucket), now: time.Now, lastSweep: time.Now()}
+}
+
+func (m *MemoryBackend) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	if limit.Unlimited() {
+		return true, 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), limit: limit, updated: now}
+		m.buckets[key] = b
+	}
+
+	// a changed limit applies from now on, the tokens already earned are kept
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), nil
+}
+
+// sweep drops the buckets that are full again, a new bucket starts full anyway.
+func (m *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+
+	m.lastSweep = now
+	for key, b := range m.buckets {
+		b.refill(now)
+		if b.tokens >= b.limit.burst() {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := backend.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := backend.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// other clients have their own buckets
+	allowed, _, err = backend.Take(ctx, "other", limit)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _, err = backend.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, _, err = backend.Take(ctx, "client", Limit{})
+	require.NoError(t, err)
+	require.True(t, allowed)
+}
+
+func TestMemoryBackendSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	backend := NewMemoryBackend()
+	backend.now = func() time.Time { return now }
+
+	_, _, err := backend.Take(ctx, "idle", Limit{Rate: 1, Burst: 1})
+	require.NoError(t, err)
+	_, _, err = backend.Take(ctx, "slow", Limit{Rate: 0.001, Burst: 1})
+	require.NoError(t, err)
+
+	now = now.Add(2 * sweepInterval)
+	_, _, err = backend.Take(ctx, "new", Limit{Rate: 1, Burst: 1})
+	require.NoError(t, err)
+
+	require.NotContains(t, backend.buckets, "idle")
+	require.Contains(t, backend.buckets, "slow")
+	require.Contains(t, backend.buckets, "new")
+}
+
+func TestLimitsFor(t *testing.T) {
+	limits := Limits{
+		Default: Limit{Rate: 10, Burst: 20},
+		Methods: map[string]Limit{"createuser": {Rate: 1, Burst: 2}},
+	}
+
+	limit, bucket := limits.For("/user.UserService/CreateUser")
+	require.Equal(t, Limit{Rate: 1, Burst: 2}, limit)
+	require.Equal(t, "createuser", bucket)
+
+	limit, bucket = limits.For("/user.UserService/GetUsers")
+	require.Equal(t, Limit{Rate: 10, Burst: 20}, limit)
+	require.Equal(t, "*", bucket)
+}
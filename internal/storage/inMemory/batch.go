@@ -0,0 +1,165 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+func (us *UserStorage) BatchCreateUsers(ctx context.Context, tenantID string, users []*models.User, allOrNothing bool) ([]error, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	errs := make([]error, len(users))
+	undo := make([]func(), 0, len(users))
+	for i, user := range users {
+		if errs[i] = us.createUser(ctx, tenantID, user); errs[i] != nil {
+			continue
+		}
+
+		userID := user.ID
+		undo = append(undo, func() { _ = us.purge(userID) })
+	}
+
+	errs, err := us.finishBatch(ctx, errs, undo, allOrNothing)
+	if err == nil {
+		for i, user := range users {
+			if errs[i] == nil {
+				us.publish(models.EventUserCreated, user)
+			}
+		}
+	}
+
+	return errs, err
+}
+
+func (us *UserStorage) BatchUpdateUsers(ctx context.Context, tenantID string, updates []models.UserUpdate, allOrNothing bool) ([]error, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	errs := make([]error, len(updates))
+	undo := make([]func(), 0, len(updates))
+	for i, update := range updates {
+		snapshot, ok := us.snapshot(update.ID)
+		if errs[i] = us.updateUser(ctx, tenantID, update.DTO, update.ID); errs[i] != nil || !ok {
+			continue
+		}
+
+		undo = append(undo, func() { us.restore(snapshot) })
+	}
+
+	errs, err := us.finishBatch(ctx, errs, undo, allOrNothing)
+	if err == nil {
+		for i, update := range updates {
+			if errs[i] == nil {
+				us.publish(models.EventUserUpdated, us.users[update.ID])
+			}
+		}
+	}
+
+	return errs, err
+}
+
+func (us *UserStorage) BatchDeleteUsers(ctx context.Context, tenantID string, userIDs []string, allOrNothing bool) ([]error, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	errs := make([]error, len(userIDs))
+	undo := make([]func(), 0, len(userIDs))
+	for i, userID := range userIDs {
+		snapshot, ok := us.snapshot(userID)
+		if errs[i] = us.deleteUser(ctx, tenantID, userID); errs[i] != nil || !ok {
+			continue
+		}
+
+		undo = append(undo, func() { us.restore(snapshot) })
+	}
+
+	errs, err := us.finishBatch(ctx, errs, undo, allOrNothing)
+	if err == nil {
+		for i, userID := range userIDs {
+			if errs[i] == nil {
+				us.publish(models.EventUserDeleted, us.users[userID])
+			}
+		}
+	}
+
+	return errs, err
+}
+
+// finishBatch rolls back already applied items in reverse order when an all-or-nothing batch has failed items.
+func (us *UserStorage) finishBatch(ctx context.Context, errs []error, undo []func(), allOrNothing bool) ([]error, error) {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	if !allOrNothing || failed == 0 {
+		us.log(ctx).Info("batch was applied", map[string]interface{}{"applied": len(errs) - failed, "failed": failed})
+		return errs, nil
+	}
+
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+
+	for i := range errs {
+		if errs[i] == nil {
+			errs[i] = app.ErrBatchAborted
+		}
+	}
+
+	us.log(ctx).Info("batch was rolled back", map[string]interface{}{"failed": failed})
+
+	return errs, app.ErrBatchAborted
+}
+
+func (us *UserStorage) snapshot(userID string) (models.User, bool) {
+	user, ok := us.users[userID]
+	if !ok {
+		return models.User{}, false
+	}
+
+	return copyUser(user), true
+}
+
+func (us *UserStorage) restore(snapshot models.User) {
+	user := us.users[snapshot.ID]
+	index := us.index(user.TenantID)
+
+	delete(index.byEmail, user.Email)
+	delete(index.byUsername, user.UserName)
+	for key, value := range user.Attributes {
+		index.removeAttribute(user.ID, key, value)
+	}
+
+	*user = snapshot
+
+	index.byEmail[user.Email] = user.ID
+	index.byUsername[user.UserName] = user.ID
+	for key, value := range user.Attributes {
+		index.addAttribute(user.ID, key, value)
+	}
+}
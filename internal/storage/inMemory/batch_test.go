@@ -0,0 +1,116 @@
+package memorystorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCreateUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	newBatch := func() []*models.User {
+		return []*models.User{
+			{Email: "batch1@gmail.com", UserName: "batch1"},
+			{Email: "batch2@gmail.com", UserName: "batch2"},
+			{Email: "batch1@gmail.com", UserName: "batch3"},
+		}
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		storage := NewUserStorage(logg)
+
+		errs, err := storage.BatchCreateUsers(ctx, models.DefaultTenant, newBatch(), false)
+		require.NoError(t, err)
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.Error(t, errs[2])
+		require.Len(t, storage.users, 2)
+	})
+
+	t.Run("all or nothing", func(t *testing.T) {
+		storage := NewUserStorage(logg)
+
+		errs, err := storage.BatchCreateUsers(ctx, models.DefaultTenant, newBatch(), true)
+		require.ErrorIs(t, err, app.ErrBatchAborted)
+		require.ErrorIs(t, errs[0], app.ErrBatchAborted)
+		require.ErrorIs(t, errs[1], app.ErrBatchAborted)
+		require.Error(t, errs[2])
+		require.Empty(t, storage.users)
+		require.Empty(t, storage.lookup(models.DefaultTenant).byEmail)
+		require.Empty(t, storage.lookup(models.DefaultTenant).byUsername)
+		require.Empty(t, storage.listIds)
+	})
+}
+
+func TestBatchUpdateUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	first, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:      "first@gmail.com",
+		UserName:   "first",
+		Attributes: map[string]string{"department": "sales"},
+	})
+	require.NoError(t, err)
+	second, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "second@gmail.com", UserName: "second"})
+	require.NoError(t, err)
+
+	renamed := "renamed"
+	taken := "first"
+	errs, err := storage.BatchUpdateUsers(ctx, models.DefaultTenant, []models.UserUpdate{
+		{ID: first.ID, DTO: models.UpdateUserDTO{UserName: &renamed, Attributes: map[string]string{"department": "support"}}},
+		{ID: second.ID, DTO: models.UpdateUserDTO{UserName: &taken}},
+		{ID: second.ID, DTO: models.UpdateUserDTO{UserName: &renamed}},
+	}, true)
+	require.ErrorIs(t, err, app.ErrBatchAborted)
+	require.ErrorIs(t, errs[0], app.ErrBatchAborted)
+	require.ErrorIs(t, errs[1], app.ErrBatchAborted)
+	require.Error(t, errs[2])
+
+	require.Equal(t, "first", storage.users[first.ID].UserName)
+	require.Equal(t, "second", storage.users[second.ID].UserName)
+	require.Equal(t, first.ID, storage.lookup(models.DefaultTenant).byUsername["first"])
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byUsername, "renamed")
+	require.Equal(t, map[string]string{"department": "sales"}, storage.users[first.ID].Attributes)
+	require.Contains(t, storage.lookup(models.DefaultTenant).byAttribute["department"]["sales"], first.ID)
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byAttribute["department"], "support")
+
+	errs, err = storage.BatchUpdateUsers(ctx, models.DefaultTenant, []models.UserUpdate{
+		{ID: first.ID, DTO: models.UpdateUserDTO{UserName: &renamed}},
+		{ID: second.ID, DTO: models.UpdateUserDTO{UserName: &taken}},
+	}, true)
+	require.NoError(t, err)
+	require.Equal(t, []error{nil, nil}, errs)
+	require.Equal(t, second.ID, storage.lookup(models.DefaultTenant).byUsername["first"])
+	require.Equal(t, first.ID, storage.lookup(models.DefaultTenant).byUsername["renamed"])
+}
+
+func TestBatchDeleteUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "delete@gmail.com", UserName: "delete"})
+	require.NoError(t, err)
+
+	errs, err := storage.BatchDeleteUsers(ctx, models.DefaultTenant, []string{user.ID, "unknown"}, true)
+	require.ErrorIs(t, err, app.ErrBatchAborted)
+	require.Len(t, errs, 2)
+	require.Nil(t, storage.users[user.ID].DeletedAt)
+
+	errs, err = storage.BatchDeleteUsers(ctx, models.DefaultTenant, []string{user.ID, "unknown"}, false)
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+	require.Error(t, errs[1])
+	require.NotNil(t, storage.users[user.ID].DeletedAt)
+}
@@ -0,0 +1,165 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+const defaultEventsCapacity = 1024
+
+// eventLog is a bounded ring buffer of user change events. Watchers wait on notify,
+// which is closed and replaced on every publish.
+type eventLog struct {
+	mu      sync.Mutex
+	events  []models.UserEvent
+	start   int
+	size    int
+	lastSeq uint64
+	notify  chan struct{}
+}
+
+func newEventLog(capacity int) *eventLog {
+	if capacity <= 0 {
+		capacity = defaultEventsCapacity
+	}
+
+	return &eventLog{
+		events: make([]models.UserEvent, capacity),
+		notify: make(chan struct{}),
+	}
+}
+
+func (l *eventLog) publish(event models.UserEvent) models.UserEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastSeq++
+	event.Sequence = l.lastSeq
+
+	capacity := len(l.events)
+	if l.size < capacity {
+		l.events[(l.start+l.size)%capacity] = event
+		l.size++
+	} else {
+		l.events[l.start] = event
+		l.start = (l.start + 1) % capacity
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+
+	return event
+}
+
+func (l *eventLog) last() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lastSeq
+}
+
+// restore drops the retained events and continues the sequence after lastSeq, e.g. the one of a snapshot.
+func (l *eventLog) restore(lastSeq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.start, l.size, l.lastSeq = 0, 0, lastSeq
+}
+
+// since returns retained events with a sequence greater than after and a channel that is closed on the next publish.
+// An after of 0 starts from the oldest retained event, even if older ones were evicted.
+func (l *eventLog) since(after uint64) ([]models.UserEvent, <-chan struct{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	oldest := l.lastSeq - uint64(l.size) + 1
+	if after == 0 {
+		after = oldest - 1
+	}
+
+	if after > l.lastSeq || after+1 < oldest {
+		return nil, nil, fmt.Errorf("%w: requested %d, available %d-%d", app.ErrSequenceExpired, after, oldest, l.lastSeq)
+	}
+
+	count := int(l.lastSeq - after)
+	events := make([]models.UserEvent, 0, count)
+	for i := l.size - count; i < l.size; i++ {
+		events = append(events, l.events[(l.start+i)%len(l.events)])
+	}
+
+	return events, l.notify, nil
+}
+
+func (us *UserStorage) SetEventsCapacity(capacity int) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	events := newEventLog(capacity)
+	events.lastSeq = us.events.last()
+	us.events = events
+}
+
+// WatchUsers sends only the events of users of the tenant, the sequence is shared by every tenant.
+func (us *UserStorage) WatchUsers(
+	ctx context.Context, tenantID string, afterSequence uint64, fromNow bool, send func(models.UserEvent) error,
+) error {
+	us.mu.RLock()
+	events := us.events
+	us.mu.RUnlock()
+
+	if fromNow {
+		afterSequence = events.last()
+	}
+
+	for {
+		batch, wait, err := events.since(afterSequence)
+		if err != nil {
+			us.log(ctx).Error("watcher fell behind the event log", map[string]interface{}{"sequence": afterSequence, "error": err})
+			return err
+		}
+
+		for _, event := range batch {
+			afterSequence = event.Sequence
+			if !inTenant(&event.User, tenantID) {
+				continue
+			}
+
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+func (us *UserStorage) publish(eventType models.UserEventType, user *models.User) {
+	event := us.events.publish(models.UserEvent{
+		Type:       eventType,
+		User:       copyUser(user),
+		OccurredAt: us.now(),
+	})
+
+	us.writeOutbox(event)
+}
+
+func copyUser(user *models.User) models.User {
+	userCopy := *user
+	if user.Attributes != nil {
+		userCopy.Attributes = make(map[string]string, len(user.Attributes))
+		for key, value := range user.Attributes {
+			userCopy.Attributes[key] = value
+		}
+	}
+
+	return userCopy
+}
@@ -0,0 +1,142 @@
+package memorystorage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+var errStopWatch = errors.New("stop watching")
+
+func collectEvents(
+	t *testing.T, storage *UserStorage, afterSequence uint64, fromNow bool, count int,
+) ([]models.UserEvent, error) {
+	t.Helper()
+
+	var events []models.UserEvent
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := storage.WatchUsers(ctx, models.DefaultTenant, afterSequence, fromNow, func(event models.UserEvent) error {
+		events = append(events, event)
+		if len(events) == count {
+			return errStopWatch
+		}
+
+		return nil
+	})
+	if errors.Is(err, errStopWatch) {
+		err = nil
+	}
+
+	return events, err
+}
+
+func TestWatchUsersReplay(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "watch@gmail.com", UserName: "watch"})
+	require.NoError(t, err)
+	newName := "watched"
+	require.NoError(t, storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{UserName: &newName}, user.ID))
+	require.NoError(t, storage.DeleteUser(ctx, models.DefaultTenant, user.ID))
+	require.NoError(t, storage.PurgeUser(ctx, models.DefaultTenant, user.ID))
+
+	events, err := collectEvents(t, storage, 0, false, 3)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, models.EventUserCreated, events[0].Type)
+	require.Equal(t, "watch", events[0].User.UserName)
+	require.Equal(t, models.EventUserUpdated, events[1].Type)
+	require.Equal(t, "watched", events[1].User.UserName)
+	require.Equal(t, models.EventUserDeleted, events[2].Type)
+	require.Equal(t, []uint64{1, 2, 3}, []uint64{events[0].Sequence, events[1].Sequence, events[2].Sequence})
+
+	events, err = collectEvents(t, storage, 2, false, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), events[0].Sequence)
+}
+
+func TestWatchUsersLive(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	_, err = storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "old@gmail.com", UserName: "old"})
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "new@gmail.com", UserName: "new"})
+	}()
+
+	events, err := collectEvents(t, storage, 0, true, 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "new", events[0].User.UserName)
+	require.Equal(t, uint64(2), events[0].Sequence)
+}
+
+func TestWatchUsersEviction(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	storage.SetEventsCapacity(2)
+	ctx := context.Background()
+
+	for _, user := range []*models.User{
+		{Email: "first@gmail.com", UserName: "first"},
+		{Email: "second@gmail.com", UserName: "second"},
+		{Email: "third@gmail.com", UserName: "third"},
+		{Email: "fourth@gmail.com", UserName: "fourth"},
+	} {
+		_, err = storage.CreateUser(ctx, models.DefaultTenant, user)
+		require.NoError(t, err)
+	}
+
+	_, err = collectEvents(t, storage, 1, false, 1)
+	require.ErrorIs(t, err, app.ErrSequenceExpired)
+
+	events, err := collectEvents(t, storage, 2, false, 2)
+	require.NoError(t, err)
+	require.Equal(t, "third", events[0].User.UserName)
+	require.Equal(t, "fourth", events[1].User.UserName)
+
+	events, err = collectEvents(t, storage, 0, false, 2)
+	require.NoError(t, err, "0 replays every retained event even after eviction")
+	require.Equal(t, []uint64{3, 4}, []uint64{events[0].Sequence, events[1].Sequence})
+
+	_, err = collectEvents(t, storage, 10, false, 1)
+	require.ErrorIs(t, err, app.ErrSequenceExpired)
+}
+
+func TestWatchUsersBatchRollback(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	_, err = storage.BatchCreateUsers(ctx, models.DefaultTenant, []*models.User{
+		{Email: "batch@gmail.com", UserName: "batch1"},
+		{Email: "batch@gmail.com", UserName: "batch2"},
+	}, true)
+	require.ErrorIs(t, err, app.ErrBatchAborted)
+	require.Equal(t, uint64(0), storage.events.last())
+
+	_, err = storage.BatchCreateUsers(ctx, models.DefaultTenant, []*models.User{
+		{Email: "batch@gmail.com", UserName: "batch1"},
+		{Email: "batch@gmail.com", UserName: "batch2"},
+	}, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), storage.events.last())
+}
@@ -0,0 +1,403 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+func (us *UserStorage) CreateGroup(ctx context.Context, tenantID string, group *models.Group) (*models.Group, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if tenantID == models.AllTenants {
+		return nil, app.ErrTenantRequired
+	}
+
+	index := us.index(tenantID)
+	if existingID, exists := index.byGroupName[group.Name]; exists {
+		us.log(ctx).Error("group with a such name already exists", map[string]interface{}{"name": group.Name, "id": existingID})
+		return nil, fmt.Errorf("group with name %s already exists (ID: %s)", group.Name, existingID)
+	}
+
+	group.ID = uuid.New().String()
+	group.TenantID = tenantID
+	group.CreatedAt = us.now()
+	group.UpdatedAt = group.CreatedAt
+	us.groups[group.ID] = group
+	us.listGroupIds = append(us.listGroupIds, group.ID)
+	index.byGroupName[group.Name] = group.ID
+
+	us.log(ctx).Info("group was created", map[string]interface{}{"id": group.ID})
+
+	groupCopy := *group
+
+	return &groupCopy, nil
+}
+
+func (us *UserStorage) UpdateGroup(ctx context.Context, tenantID string, groupDTO models.UpdateGroupDTO, groupID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	group, exists := us.findGroup(tenantID, groupID)
+	if !exists {
+		us.log(ctx).Error("group with a such ID does not exist", map[string]interface{}{"id": groupID})
+		return fmt.Errorf("group with ID %s not found", groupID)
+	}
+
+	index := us.index(group.TenantID)
+	if groupDTO.Name != nil {
+		if existingID, ex := index.byGroupName[*groupDTO.Name]; ex && existingID != groupID {
+			us.log(ctx).Error("group with a such name already exists", map[string]interface{}{"name": *groupDTO.Name, "id": existingID})
+			return fmt.Errorf("group with name %s already exists (ID: %s)", *groupDTO.Name, existingID)
+		}
+
+		delete(index.byGroupName, group.Name)
+		index.byGroupName[*groupDTO.Name] = groupID
+		group.Name = *groupDTO.Name
+	}
+
+	if groupDTO.Description != nil {
+		group.Description = *groupDTO.Description
+	}
+
+	group.UpdatedAt = us.now()
+	us.log(ctx).Info("group was updated", map[string]interface{}{"id": groupID})
+
+	return nil
+}
+
+// DeleteGroup removes the group from its parents, its members stay in the storage but lose the membership.
+func (us *UserStorage) DeleteGroup(ctx context.Context, tenantID, groupID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	group, exists := us.findGroup(tenantID, groupID)
+	if !exists {
+		us.log(ctx).Error("group with a such ID does not exist", map[string]interface{}{"id": groupID})
+		return fmt.Errorf("group with ID %s not found", groupID)
+	}
+
+	us.leaveGroups(models.GroupMember{Type: models.MemberGroup, ID: groupID})
+	for member := range us.members[groupID] {
+		us.unlink(groupID, member)
+	}
+
+	delete(us.index(group.TenantID).byGroupName, group.Name)
+	delete(us.groups, groupID)
+	us.listGroupIds = removeString(us.listGroupIds, groupID)
+
+	us.log(ctx).Info("group was deleted", map[string]interface{}{"id": groupID})
+
+	return nil
+}
+
+func (us *UserStorage) GetGroup(ctx context.Context, tenantID, groupID string) (*models.Group, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	group, exists := us.findGroup(tenantID, groupID)
+	if !exists {
+		us.log(ctx).Error("group does not exist", map[string]interface{}{"id": groupID})
+		return nil, fmt.Errorf("group with id: %s does not exist", groupID)
+	}
+
+	groupCopy := *group
+
+	return &groupCopy, nil
+}
+
+func (us *UserStorage) GetGroups(ctx context.Context, tenantID string, offset, limit int) ([]models.Group, int, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	default:
+	}
+
+	visibleIds := make([]string, 0, len(us.listGroupIds))
+	for _, id := range us.listGroupIds {
+		if tenantID == models.AllTenants || us.groups[id].TenantID == tenantID {
+			visibleIds = append(visibleIds, id)
+		}
+	}
+
+	count := len(visibleIds)
+	if offset >= count {
+		return make([]models.Group, 0), count, nil
+	}
+
+	finish := count
+	if offset+limit < count {
+		finish = offset + limit
+	}
+
+	groups := make([]models.Group, 0, finish-offset)
+	for _, id := range visibleIds[offset:finish] {
+		groups = append(groups, *us.groups[id])
+	}
+
+	return groups, count, nil
+}
+
+// AddGroupMember adds a user or a group of the same tenant to the group. A nested group must not contain
+// the group, even transitively, since the membership would become a cycle.
+func (us *UserStorage) AddGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	group, exists := us.findGroup(tenantID, groupID)
+	if !exists {
+		us.log(ctx).Error("group with a such ID does not exist", map[string]interface{}{"id": groupID})
+		return fmt.Errorf("group with ID %s not found", groupID)
+	}
+
+	switch member.Type {
+	case models.MemberUser:
+		user, ok := us.find(group.TenantID, member.ID)
+		if !ok || user.DeletedAt != nil {
+			us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": member.ID})
+			return fmt.Errorf("user with ID %s not found", member.ID)
+		}
+	case models.MemberGroup:
+		if _, ok := us.findGroup(group.TenantID, member.ID); !ok {
+			us.log(ctx).Error("group with a such ID does not exist", map[string]interface{}{"id": member.ID})
+			return fmt.Errorf("group with ID %s not found", member.ID)
+		}
+
+		if us.contains(member.ID, groupID) {
+			us.log(ctx).Error("group membership would be a cycle", map[string]interface{}{"id": groupID, "member": member.ID})
+			return fmt.Errorf("%w: group %s contains group %s", app.ErrGroupCycle, member.ID, groupID)
+		}
+	default:
+		return fmt.Errorf("unknown member type %s", member.Type)
+	}
+
+	if _, exists = us.members[groupID][member]; exists {
+		return fmt.Errorf("%s %s is already a member of group %s", member.Type, member.ID, groupID)
+	}
+
+	us.link(groupID, member)
+	us.log(ctx).Info("member was added to group", map[string]interface{}{"id": groupID, "member": member.ID, "type": member.Type})
+
+	return nil
+}
+
+func (us *UserStorage) RemoveGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if _, exists := us.findGroup(tenantID, groupID); !exists {
+		us.log(ctx).Error("group with a such ID does not exist", map[string]interface{}{"id": groupID})
+		return fmt.Errorf("group with ID %s not found", groupID)
+	}
+
+	if _, exists := us.members[groupID][member]; !exists {
+		return fmt.Errorf("%s %s is not a member of group %s", member.Type, member.ID, groupID)
+	}
+
+	us.unlink(groupID, member)
+	us.log(ctx).Info("member was removed from group", map[string]interface{}{"id": groupID, "member": member.ID, "type": member.Type})
+
+	return nil
+}
+
+// GetGroupMembers returns the direct members of the group, or with recursive the members of its nested
+// groups as well. Deleted users are left out.
+func (us *UserStorage) GetGroupMembers(ctx context.Context, tenantID, groupID string, recursive bool) (*models.GroupMembers, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if _, exists := us.findGroup(tenantID, groupID); !exists {
+		us.log(ctx).Error("group does not exist", map[string]interface{}{"id": groupID})
+		return nil, fmt.Errorf("group with id: %s does not exist", groupID)
+	}
+
+	members := &models.GroupMembers{Users: make([]models.User, 0), Groups: make([]models.Group, 0)}
+	seen := make(map[models.GroupMember]bool)
+	queue := []string{groupID}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for member := range us.members[current] {
+			if seen[member] {
+				continue
+			}
+			seen[member] = true
+
+			if member.Type == models.MemberGroup {
+				members.Groups = append(members.Groups, *us.groups[member.ID])
+				if recursive {
+					queue = append(queue, member.ID)
+				}
+
+				continue
+			}
+
+			if user := us.users[member.ID]; user.DeletedAt == nil {
+				members.Users = append(members.Users, copyUser(user))
+			}
+		}
+	}
+
+	sort.Slice(members.Users, func(i, j int) bool { return members.Users[i].UserName < members.Users[j].UserName })
+	sortGroups(members.Groups)
+
+	return members, nil
+}
+
+// GetUserGroups returns the groups the user is a direct member of, or with recursive the groups
+// containing them as well.
+func (us *UserStorage) GetUserGroups(ctx context.Context, tenantID, userID string, recursive bool) ([]models.Group, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	user, ok := us.find(tenantID, userID)
+	if !ok || user.DeletedAt != nil {
+		us.log(ctx).Error("user does not exist", map[string]interface{}{"id": userID})
+		return nil, fmt.Errorf("user with id: %s does not exist", userID)
+	}
+
+	groups := make([]models.Group, 0)
+	seen := make(map[string]bool)
+	queue := []string{userID}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for parentID := range us.memberOf[current] {
+			if seen[parentID] {
+				continue
+			}
+			seen[parentID] = true
+
+			groups = append(groups, *us.groups[parentID])
+			if recursive {
+				queue = append(queue, parentID)
+			}
+		}
+	}
+
+	sortGroups(groups)
+
+	return groups, nil
+}
+
+// contains reports whether the group is the target or has it among its nested groups.
+func (us *UserStorage) contains(groupID, targetID string) bool {
+	if groupID == targetID {
+		return true
+	}
+
+	for member := range us.members[groupID] {
+		if member.Type == models.MemberGroup && us.contains(member.ID, targetID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// leaveGroups removes the member from every group it belongs to, e.g. when it is purged.
+func (us *UserStorage) leaveGroups(member models.GroupMember) {
+	for parentID := range us.memberOf[member.ID] {
+		us.unlink(parentID, member)
+	}
+}
+
+// link and unlink keep the indexes of both directions in sync.
+func (us *UserStorage) link(groupID string, member models.GroupMember) {
+	if us.members[groupID] == nil {
+		us.members[groupID] = make(map[models.GroupMember]struct{})
+	}
+	us.members[groupID][member] = struct{}{}
+
+	if us.memberOf[member.ID] == nil {
+		us.memberOf[member.ID] = make(map[string]struct{})
+	}
+	us.memberOf[member.ID][groupID] = struct{}{}
+}
+
+func (us *UserStorage) unlink(groupID string, member models.GroupMember) {
+	delete(us.members[groupID], member)
+	if len(us.members[groupID]) == 0 {
+		delete(us.members, groupID)
+	}
+
+	delete(us.memberOf[member.ID], groupID)
+	if len(us.memberOf[member.ID]) == 0 {
+		delete(us.memberOf, member.ID)
+	}
+}
+
+// findGroup hides the groups of other tenants as if they did not exist.
+func (us *UserStorage) findGroup(tenantID, groupID string) (*models.Group, bool) {
+	group, ok := us.groups[groupID]
+	if !ok || (tenantID != models.AllTenants && group.TenantID != tenantID) {
+		return nil, false
+	}
+
+	return group, true
+}
+
+func sortGroups(groups []models.Group) {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+}
@@ -0,0 +1,148 @@
+package memorystorage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	engineering, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "engineering"})
+	require.NoError(t, err)
+	_, err = storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "engineering"})
+	require.Error(t, err, "group names are unique within a tenant")
+	_, err = storage.CreateGroup(ctx, "acme", &models.Group{Name: "engineering"})
+	require.NoError(t, err)
+	_, err = storage.CreateGroup(ctx, models.AllTenants, &models.Group{Name: "all"})
+	require.ErrorIs(t, err, app.ErrTenantRequired)
+
+	backend, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "backend"})
+	require.NoError(t, err)
+
+	name, description := "platform", "core services"
+	require.NoError(t, storage.UpdateGroup(ctx, models.DefaultTenant, models.UpdateGroupDTO{Name: &name, Description: &description}, backend.ID))
+	require.Equal(t, backend.ID, storage.lookup(models.DefaultTenant).byGroupName["platform"])
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byGroupName, "backend")
+
+	_, err = storage.GetGroup(ctx, "acme", backend.ID)
+	require.Error(t, err, "a group is not found in another tenant")
+
+	groups, count, err := storage.GetGroups(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, groups, 2)
+
+	_, count, err = storage.GetGroups(ctx, models.AllTenants, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	require.NoError(t, storage.DeleteGroup(ctx, models.DefaultTenant, backend.ID))
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byGroupName, "platform")
+	require.Error(t, storage.DeleteGroup(ctx, "acme", engineering.ID))
+}
+
+func TestGroupMembers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	alice, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "alice@gmail.com", UserName: "alice"})
+	require.NoError(t, err)
+	bob, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "bob@gmail.com", UserName: "bob"})
+	require.NoError(t, err)
+	stranger, err := storage.CreateUser(ctx, "acme", &models.User{Email: "stranger@gmail.com", UserName: "stranger"})
+	require.NoError(t, err)
+
+	company, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "company"})
+	require.NoError(t, err)
+	engineering, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "engineering"})
+	require.NoError(t, err)
+	backend, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "backend"})
+	require.NoError(t, err)
+
+	user := func(id string) models.GroupMember { return models.GroupMember{Type: models.MemberUser, ID: id} }
+	group := func(id string) models.GroupMember { return models.GroupMember{Type: models.MemberGroup, ID: id} }
+
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, company.ID, group(engineering.ID)))
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, engineering.ID, group(backend.ID)))
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, engineering.ID, user(alice.ID)))
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, user(bob.ID)))
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, user(alice.ID)))
+
+	require.Error(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, user(bob.ID)), "already a member")
+	require.Error(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, user(stranger.ID)), "a user of another tenant")
+	require.ErrorIs(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, group(backend.ID)), app.ErrGroupCycle)
+	require.ErrorIs(t, storage.AddGroupMember(ctx, models.DefaultTenant, backend.ID, group(company.ID)), app.ErrGroupCycle)
+
+	members, err := storage.GetGroupMembers(ctx, models.DefaultTenant, company.ID, false)
+	require.NoError(t, err)
+	require.Empty(t, members.Users)
+	require.Len(t, members.Groups, 1)
+
+	members, err = storage.GetGroupMembers(ctx, models.DefaultTenant, company.ID, true)
+	require.NoError(t, err)
+	require.Len(t, members.Users, 2, "alice is a member of two nested groups but listed once")
+	require.Equal(t, "alice", members.Users[0].UserName)
+	require.Equal(t, "bob", members.Users[1].UserName)
+	require.Equal(t, "backend", members.Groups[0].Name)
+	require.Equal(t, "engineering", members.Groups[1].Name)
+
+	groups, err := storage.GetUserGroups(ctx, models.DefaultTenant, bob.ID, false)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, backend.ID, groups[0].ID)
+
+	groups, err = storage.GetUserGroups(ctx, models.DefaultTenant, bob.ID, true)
+	require.NoError(t, err)
+	require.Len(t, groups, 3)
+
+	require.NoError(t, storage.RemoveGroupMember(ctx, models.DefaultTenant, backend.ID, user(alice.ID)))
+	require.Error(t, storage.RemoveGroupMember(ctx, models.DefaultTenant, backend.ID, user(alice.ID)))
+	require.NotContains(t, storage.memberOf[alice.ID], backend.ID)
+
+	require.NoError(t, storage.DeleteGroup(ctx, models.DefaultTenant, engineering.ID))
+	require.NotContains(t, storage.members, engineering.ID)
+	require.NotContains(t, storage.members, company.ID, "company had engineering as its only member")
+	require.NotContains(t, storage.memberOf, alice.ID)
+	require.NotContains(t, storage.memberOf, backend.ID)
+
+	require.NoError(t, storage.PurgeUser(ctx, models.DefaultTenant, bob.ID))
+	require.NotContains(t, storage.memberOf, bob.ID)
+	members, err = storage.GetGroupMembers(ctx, models.DefaultTenant, backend.ID, false)
+	require.NoError(t, err)
+	require.Empty(t, members.Users)
+}
+
+func TestGroupsSnapshot(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	storage := NewUserStorage(logg)
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "alice@gmail.com", UserName: "alice"})
+	require.NoError(t, err)
+	group, err := storage.CreateGroup(ctx, models.DefaultTenant, &models.Group{Name: "engineering"})
+	require.NoError(t, err)
+	require.NoError(t, storage.AddGroupMember(ctx, models.DefaultTenant, group.ID, models.GroupMember{Type: models.MemberUser, ID: user.ID}))
+	require.NoError(t, storage.SaveSnapshot(path))
+
+	restored := NewUserStorage(logg)
+	loaded, err := restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, storage.members, restored.members)
+	require.Equal(t, storage.memberOf, restored.memberOf)
+	require.Equal(t, group.ID, restored.lookup(models.DefaultTenant).byGroupName["engineering"])
+}
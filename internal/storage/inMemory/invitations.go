@@ -0,0 +1,262 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+// invitedUsername holds the username of a pending user until the invitee picks one, a colon cannot be
+// a part of Basic credentials, so nobody logs in with it.
+const invitedUsername = "invited:"
+
+// CreateInvitation creates the pending user and its invitation at once, so an invitation never refers
+// to a missing user.
+func (us *UserStorage) CreateInvitation(
+	ctx context.Context, tenantID string, user *models.User, invitation *models.Invitation,
+) (*models.Invitation, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	invitation.ID = uuid.New().String()
+	user.UserName = invitedUsername + invitation.ID
+	user.Status = models.StatusPending
+	if err := us.createUser(ctx, tenantID, user); err != nil {
+		return nil, err
+	}
+
+	invitation.TenantID = tenantID
+	invitation.UserID = user.ID
+	invitation.Email = user.Email
+	invitation.CreatedAt = us.now()
+	us.invitations[invitation.ID] = invitation
+	us.listInvitationIds = append(us.listInvitationIds, invitation.ID)
+	us.byTokenHash[invitation.TokenHash] = invitation.ID
+
+	us.publish(models.EventUserCreated, user)
+	us.log(ctx).Info("user was invited", map[string]interface{}{"id": user.ID, "invitation": invitation.ID})
+
+	invitationCopy := *invitation
+
+	return &invitationCopy, nil
+}
+
+// GetInvitations returns the outstanding invitations, expired ones included until they are revoked or purged.
+func (us *UserStorage) GetInvitations(ctx context.Context, tenantID string, offset, limit int) ([]models.Invitation, int, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	default:
+	}
+
+	visibleIds := make([]string, 0, len(us.listInvitationIds))
+	for _, id := range us.listInvitationIds {
+		if tenantID == models.AllTenants || us.invitations[id].TenantID == tenantID {
+			visibleIds = append(visibleIds, id)
+		}
+	}
+
+	count := len(visibleIds)
+	if offset >= count {
+		return make([]models.Invitation, 0), count, nil
+	}
+
+	finish := count
+	if offset+limit < count {
+		finish = offset + limit
+	}
+
+	invitations := make([]models.Invitation, 0, finish-offset)
+	for _, id := range visibleIds[offset:finish] {
+		invitations = append(invitations, *us.invitations[id])
+	}
+
+	return invitations, count, nil
+}
+
+// CheckInvitation reports whether the token belongs to an invitation that can be accepted, so the password
+// is not hashed for a bad token.
+func (us *UserStorage) CheckInvitation(ctx context.Context, tokenHash string) error {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	_, _, err := us.pendingInvitation(ctx, tokenHash)
+
+	return err
+}
+
+// AcceptInvitation activates the pending user of the invitation with the chosen username and password
+// hash. The token selects the tenant, so no tenant is passed.
+func (us *UserStorage) AcceptInvitation(ctx context.Context, tokenHash, userName, password string) (*models.User, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	invitation, user, err := us.pendingInvitation(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	index := us.index(user.TenantID)
+	if existingID, ex := index.byUsername[userName]; ex {
+		us.log(ctx).Error("user with a such username already exists", map[string]interface{}{"username": userName, "id": existingID})
+		return nil, fmt.Errorf("user with username %s already exists (ID: %s)", userName, existingID)
+	}
+
+	delete(index.byUsername, user.UserName)
+	index.byUsername[userName] = user.ID
+	user.UserName = userName
+	user.Password = password
+	user.Status = models.StatusActive
+	user.StatusReason = "invitation accepted"
+	user.UpdatedAt = us.now()
+	us.dropInvitation(user.ID)
+
+	us.publish(models.EventUserUpdated, user)
+	us.log(ctx).Info("invitation was accepted", map[string]interface{}{"id": user.ID, "invitation": invitation.ID})
+
+	userCopy := copyUser(user)
+
+	return &userCopy, nil
+}
+
+// RevokeInvitation invalidates the token and purges the user of the invitation while it is still pending.
+func (us *UserStorage) RevokeInvitation(ctx context.Context, tenantID, invitationID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	invitation, exists := us.invitations[invitationID]
+	if !exists || (tenantID != models.AllTenants && invitation.TenantID != tenantID) {
+		us.log(ctx).Error("invitation with a such ID does not exist", map[string]interface{}{"id": invitationID})
+		return fmt.Errorf("invitation with ID %s not found", invitationID)
+	}
+
+	if err := us.dropPendingUser(invitation); err != nil {
+		return err
+	}
+
+	us.log(ctx).Info("invitation was revoked", map[string]interface{}{"id": invitationID})
+
+	return nil
+}
+
+// PurgeExpiredInvitations purges the pending users of the invitations that expired before expiredBefore
+// together with the invitations, as RevokeInvitation does.
+func (us *UserStorage) PurgeExpiredInvitations(ctx context.Context, tenantID string, expiredBefore time.Time) (int, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	var expired []*models.Invitation
+	for _, id := range us.listInvitationIds {
+		invitation := us.invitations[id]
+		if tenantID != models.AllTenants && invitation.TenantID != tenantID {
+			continue
+		}
+
+		if invitation.ExpiresAt.Before(expiredBefore) {
+			expired = append(expired, invitation)
+		}
+	}
+
+	for _, invitation := range expired {
+		if err := us.dropPendingUser(invitation); err != nil {
+			return 0, err
+		}
+
+		us.log(ctx).Info("expired invitation was purged", map[string]interface{}{"id": invitation.ID, "userID": invitation.UserID})
+	}
+
+	return len(expired), nil
+}
+
+// dropPendingUser purges the user of the invitation together with it while the user is still pending. A user
+// activated in another way, e.g. by ReactivateUser, is kept and only the invitation is removed.
+func (us *UserStorage) dropPendingUser(invitation *models.Invitation) error {
+	user := us.users[invitation.UserID]
+	if user.Status != models.StatusPending {
+		us.dropInvitation(user.ID)
+		return nil
+	}
+
+	if user.DeletedAt == nil {
+		us.publish(models.EventUserDeleted, user)
+	}
+
+	return us.purge(user.ID)
+}
+
+// pendingInvitation finds the invitation of the token and its user, the caller holds the lock.
+func (us *UserStorage) pendingInvitation(ctx context.Context, tokenHash string) (*models.Invitation, *models.User, error) {
+	invitationID, exists := us.byTokenHash[tokenHash]
+	if !exists {
+		us.log(ctx).Error("invitation with a such token does not exist", nil)
+		return nil, nil, app.ErrInvitationInvalid
+	}
+
+	invitation := us.invitations[invitationID]
+	if !us.now().Before(invitation.ExpiresAt) {
+		us.log(ctx).Error("invitation has expired", map[string]interface{}{"id": invitationID, "expiresAt": invitation.ExpiresAt})
+		return nil, nil, app.ErrInvitationExpired
+	}
+
+	user := us.users[invitation.UserID]
+	if user.DeletedAt != nil || user.Status != models.StatusPending {
+		us.log(ctx).Error("invited user is not pending", map[string]interface{}{"id": user.ID, "status": user.Status})
+		return nil, nil, app.ErrInvitationInvalid
+	}
+
+	return invitation, user, nil
+}
+
+// dropInvitation removes the invitation of the user, if any, e.g. when the user is purged.
+func (us *UserStorage) dropInvitation(userID string) {
+	for _, id := range us.listInvitationIds {
+		invitation := us.invitations[id]
+		if invitation.UserID != userID {
+			continue
+		}
+
+		delete(us.invitations, id)
+		delete(us.byTokenHash, invitation.TokenHash)
+		us.listInvitationIds = removeString(us.listInvitationIds, id)
+
+		return
+	}
+}
@@ -0,0 +1,179 @@
+package memorystorage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitations(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := NewUserStorageWithClock(logg, func() time.Time { return now })
+	ctx := context.Background()
+
+	invitation, err := storage.CreateInvitation(ctx, "acme", &models.User{Email: "alice@gmail.com"},
+		&models.Invitation{TokenHash: "alice", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, "acme", invitation.TenantID)
+	require.Equal(t, "alice@gmail.com", invitation.Email)
+
+	pending, err := storage.GetOneUserByID(ctx, "acme", invitation.UserID, false)
+	require.NoError(t, err)
+	require.Equal(t, models.StatusPending, pending.Status)
+	require.Empty(t, pending.Password)
+
+	_, err = storage.CreateInvitation(ctx, "acme", &models.User{Email: "alice@gmail.com"},
+		&models.Invitation{TokenHash: "again", ExpiresAt: now.Add(time.Hour)})
+	require.Error(t, err, "the email is taken by the pending user")
+	require.NotContains(t, storage.byTokenHash, "again")
+
+	_, count, err := storage.GetInvitations(ctx, "acme", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	_, count, err = storage.GetInvitations(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	_, err = storage.AcceptInvitation(ctx, "unknown", "alice", "hash")
+	require.ErrorIs(t, err, app.ErrInvitationInvalid)
+
+	_, err = storage.CreateUser(ctx, "acme", &models.User{Email: "bob@gmail.com", UserName: "bob"})
+	require.NoError(t, err)
+	_, err = storage.AcceptInvitation(ctx, "alice", "bob", "hash")
+	require.Error(t, err, "the username is taken")
+
+	user, err := storage.AcceptInvitation(ctx, "alice", "alice", "hash")
+	require.NoError(t, err)
+	require.Equal(t, invitation.UserID, user.ID)
+	require.Equal(t, models.StatusActive, user.Status)
+	require.Equal(t, "hash", user.Password)
+	require.Equal(t, user.ID, storage.lookup("acme").byUsername["alice"])
+	require.NotContains(t, storage.lookup("acme").byUsername, invitedUsername+invitation.ID)
+
+	_, err = storage.AcceptInvitation(ctx, "alice", "alice2", "hash")
+	require.ErrorIs(t, err, app.ErrInvitationInvalid, "a token is accepted once")
+	require.Empty(t, storage.listInvitationIds)
+}
+
+func TestInvitationExpiryAndRevocation(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := NewUserStorageWithClock(logg, func() time.Time { return now })
+	ctx := context.Background()
+
+	expiring, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "alice@gmail.com"},
+		&models.Invitation{TokenHash: "alice", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	revoked, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "bob@gmail.com"},
+		&models.Invitation{TokenHash: "bob", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	purged, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "carol@gmail.com"},
+		&models.Invitation{TokenHash: "carol", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "users.json")
+	require.NoError(t, storage.SaveSnapshot(path))
+	restored := NewUserStorage(logg)
+	_, err = restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, storage.byTokenHash, restored.byTokenHash)
+
+	require.NoError(t, storage.CheckInvitation(ctx, "alice"))
+	require.ErrorIs(t, storage.CheckInvitation(ctx, "unknown"), app.ErrInvitationInvalid)
+
+	now = now.Add(time.Hour)
+	require.ErrorIs(t, storage.CheckInvitation(ctx, "alice"), app.ErrInvitationExpired)
+	_, err = storage.AcceptInvitation(ctx, "alice", "alice", "hash")
+	require.ErrorIs(t, err, app.ErrInvitationExpired)
+
+	_, count, err := storage.GetInvitations(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, count, "expired invitations are listed until revoked or purged")
+
+	require.Error(t, storage.RevokeInvitation(ctx, "acme", revoked.ID), "an invitation is not found in another tenant")
+	require.NoError(t, storage.RevokeInvitation(ctx, models.DefaultTenant, revoked.ID))
+	_, err = storage.GetOneUserByID(ctx, models.DefaultTenant, revoked.UserID, true)
+	require.Error(t, err, "the pending user is purged with the invitation")
+	_, err = storage.AcceptInvitation(ctx, "bob", "bob", "hash")
+	require.ErrorIs(t, err, app.ErrInvitationInvalid)
+
+	require.NoError(t, storage.PurgeUser(ctx, models.DefaultTenant, purged.UserID))
+	require.NotContains(t, storage.invitations, purged.ID)
+	require.NotContains(t, storage.byTokenHash, "carol")
+	require.Equal(t, []string{expiring.ID}, storage.listInvitationIds)
+}
+
+func TestPurgeExpiredInvitations(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := NewUserStorageWithClock(logg, func() time.Time { return now })
+	ctx := context.Background()
+
+	expired, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "alice@gmail.com"},
+		&models.Invitation{TokenHash: "alice", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	pending, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "bob@gmail.com"},
+		&models.Invitation{TokenHash: "bob", ExpiresAt: now.Add(3 * time.Hour)})
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	purged, err := storage.PurgeExpiredInvitations(ctx, "acme", now)
+	require.NoError(t, err)
+	require.Equal(t, 0, purged, "invitations of another tenant are kept")
+
+	purged, err = storage.PurgeExpiredInvitations(ctx, models.AllTenants, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	_, err = storage.GetOneUserByID(ctx, models.DefaultTenant, expired.UserID, true)
+	require.Error(t, err, "the pending user is purged with the expired invitation")
+	require.NotContains(t, storage.byTokenHash, "alice")
+	require.Equal(t, []string{pending.ID}, storage.listInvitationIds)
+
+	_, err = storage.GetOneUserByID(ctx, models.DefaultTenant, pending.UserID, false)
+	require.NoError(t, err)
+}
+
+func TestInvitationOfActivatedUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := NewUserStorageWithClock(logg, func() time.Time { return now })
+	ctx := context.Background()
+
+	revoked, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "alice@gmail.com"},
+		&models.Invitation{TokenHash: "alice", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	expired, err := storage.CreateInvitation(ctx, models.DefaultTenant, &models.User{Email: "bob@gmail.com"},
+		&models.Invitation{TokenHash: "bob", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	for _, invitation := range []*models.Invitation{revoked, expired} {
+		require.NoError(t, storage.UpdateUserStatus(ctx, models.DefaultTenant, invitation.UserID,
+			models.StatusPending, models.StatusActive, "activated by an admin"))
+	}
+
+	require.NoError(t, storage.RevokeInvitation(ctx, models.DefaultTenant, revoked.ID))
+	now = now.Add(2 * time.Hour)
+	purged, err := storage.PurgeExpiredInvitations(ctx, models.AllTenants, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	for _, invitation := range []*models.Invitation{revoked, expired} {
+		user, err := storage.GetOneUserByID(ctx, models.DefaultTenant, invitation.UserID, false)
+		require.NoError(t, err, "an active user is kept")
+		require.Equal(t, models.StatusActive, user.Status)
+	}
+	require.Empty(t, storage.listInvitationIds)
+	require.Empty(t, storage.byTokenHash)
+}
@@ -0,0 +1,89 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+// SetOutboxEnabled switches on outbox writes. It should only be enabled when a relay drains the outbox,
+// otherwise undelivered records are kept forever.
+func (us *UserStorage) SetOutboxEnabled(enabled bool) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.outboxEnabled = enabled
+}
+
+// FetchOutbox returns up to limit undelivered records in the order the mutations were committed.
+func (us *UserStorage) FetchOutbox(ctx context.Context, limit int) ([]models.OutboxRecord, error) {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	records := make([]models.OutboxRecord, 0, limit)
+	for _, record := range us.outbox {
+		if len(records) == limit {
+			break
+		}
+
+		if record.DeliveredAt == nil {
+			records = append(records, *record)
+		}
+	}
+
+	return records, nil
+}
+
+// MarkOutboxDelivered marks records as delivered and drops the delivered head of the outbox.
+func (us *UserStorage) MarkOutboxDelivered(ctx context.Context, recordIDs []string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	delivered := make(map[string]struct{}, len(recordIDs))
+	for _, id := range recordIDs {
+		delivered[id] = struct{}{}
+	}
+
+	now := us.now()
+	for _, record := range us.outbox {
+		if _, ok := delivered[record.ID]; ok && record.DeliveredAt == nil {
+			record.DeliveredAt = &now
+		}
+	}
+
+	head := 0
+	for head < len(us.outbox) && us.outbox[head].DeliveredAt != nil {
+		head++
+	}
+
+	us.outbox = us.outbox[head:]
+
+	return nil
+}
+
+// writeOutbox must be called under the write lock, in the same critical section as the mutation it records.
+func (us *UserStorage) writeOutbox(event models.UserEvent) {
+	if !us.outboxEnabled {
+		return
+	}
+
+	us.outbox = append(us.outbox, &models.OutboxRecord{
+		ID:        uuid.New().String(),
+		Event:     event,
+		CreatedAt: event.OccurredAt,
+	})
+}
@@ -0,0 +1,79 @@
+package memorystorage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxWrittenWithMutations(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	storage.SetOutboxEnabled(true)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "test@gmail.com", UserName: "test"})
+	require.NoError(t, err)
+	newEmail := "new@gmail.com"
+	require.NoError(t, storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{Email: &newEmail}, user.ID))
+	require.Error(t, storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{Email: &newEmail}, "unknown"))
+	require.NoError(t, storage.DeleteUser(ctx, models.DefaultTenant, user.ID))
+
+	records, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, models.EventUserCreated, records[0].Event.Type)
+	require.Equal(t, models.EventUserUpdated, records[1].Event.Type)
+	require.Equal(t, newEmail, records[1].Event.User.Email)
+	require.Equal(t, models.EventUserDeleted, records[2].Event.Type)
+	for i, record := range records {
+		require.Equal(t, uint64(i+1), record.Event.Sequence)
+	}
+
+	require.NoError(t, storage.MarkOutboxDelivered(ctx, []string{records[1].ID}))
+	pending, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{records[0].ID, records[2].ID}, []string{pending[0].ID, pending[1].ID})
+
+	require.NoError(t, storage.MarkOutboxDelivered(ctx, []string{records[0].ID, records[2].ID}))
+	pending, err = storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.Empty(t, storage.outbox, "delivered records are dropped")
+}
+
+func TestOutboxSkipsAbortedBatch(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	storage.SetOutboxEnabled(true)
+	ctx := context.Background()
+
+	_, err = storage.BatchCreateUsers(ctx, models.DefaultTenant, []*models.User{
+		{Email: "first@gmail.com", UserName: "same"},
+		{Email: "second@gmail.com", UserName: "same"},
+	}, true)
+	require.Error(t, err)
+
+	records, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestOutboxDisabled(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	_, err = storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "test@gmail.com", UserName: "test"})
+	require.NoError(t, err)
+
+	records, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
@@ -0,0 +1,256 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+const snapshotVersion = 1
+
+// snapshotFile holds the users, groups and invitations in the order of their lists, the indexes are rebuilt
+// on load. Events are not a part of it, only their last sequence: the sequence keeps growing after a restart
+// and a watcher resuming from a lost event gets ErrSequenceExpired. Undelivered outbox records are kept, the
+// relay publishes them after a restart. Snapshots saved before groups, invitations, webhooks and the outbox were
+// introduced have none.
+type snapshotFile struct {
+	Version      int
+	SavedAt      time.Time
+	LastSequence uint64 `json:",omitempty"`
+	Users        []*models.User
+	Groups       []*models.Group        `json:",omitempty"`
+	Memberships  []snapshotMembership   `json:",omitempty"`
+	Invitations  []*models.Invitation   `json:",omitempty"`
+	Webhooks     *snapshotWebhooks      `json:",omitempty"`
+	Outbox       []*models.OutboxRecord `json:",omitempty"`
+}
+
+type snapshotMembership struct {
+	GroupID string
+	Member  models.GroupMember
+}
+
+// snapshotWebhooks holds the subscriptions, secrets included, the delivery queue and the sequence of the last
+// enqueued event, so the dispatcher resumes after it.
+type snapshotWebhooks struct {
+	LastSequence uint64
+	Webhooks     []*models.Webhook
+	Deliveries   []*models.WebhookDelivery `json:",omitempty"`
+	DeadLetters  []*models.WebhookDelivery `json:",omitempty"`
+}
+
+// SetWebhookStorage makes the webhooks a part of the snapshot, it must be called before LoadSnapshot.
+func (us *UserStorage) SetWebhookStorage(webhooks *WebhookStorage) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.webhooks = webhooks
+}
+
+// SaveSnapshot writes every user, password hashes included, to path. The file is replaced atomically,
+// so a crash while saving leaves the previous snapshot intact.
+func (us *UserStorage) SaveSnapshot(path string) error {
+	us.mu.RLock()
+	snapshot := snapshotFile{
+		Version:      snapshotVersion,
+		SavedAt:      us.now(),
+		LastSequence: us.events.last(),
+		Users:        make([]*models.User, 0, len(us.listIds)),
+	}
+	for _, id := range us.listIds {
+		snapshot.Users = append(snapshot.Users, us.users[id])
+	}
+	for _, id := range us.listGroupIds {
+		snapshot.Groups = append(snapshot.Groups, us.groups[id])
+		for member := range us.members[id] {
+			snapshot.Memberships = append(snapshot.Memberships, snapshotMembership{GroupID: id, Member: member})
+		}
+	}
+	for _, id := range us.listInvitationIds {
+		snapshot.Invitations = append(snapshot.Invitations, us.invitations[id])
+	}
+	for _, record := range us.outbox {
+		if record.DeliveredAt == nil {
+			snapshot.Outbox = append(snapshot.Outbox, record)
+		}
+	}
+	if us.webhooks != nil {
+		snapshot.Webhooks = us.webhooks.snapshot()
+	}
+	data, err := json.Marshal(snapshot)
+	us.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("error while encoding snapshot: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error while creating snapshot: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("error while writing snapshot: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("error while replacing snapshot: %w", err)
+	}
+
+	us.logger.Debug("snapshot was saved", map[string]interface{}{"path": path, "users": len(snapshot.Users)})
+
+	return nil
+}
+
+// LoadSnapshot replaces the users with the ones saved to path, a missing file is not an error and
+// leaves the storage as it is.
+func (us *UserStorage) LoadSnapshot(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("error while reading snapshot: %w", err)
+	}
+
+	var snapshot snapshotFile
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return false, fmt.Errorf("error while decoding snapshot: %w", err)
+	}
+
+	if snapshot.Version != snapshotVersion {
+		return false, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	us.users = make(map[string]*models.User, len(snapshot.Users))
+	us.tenants = make(map[string]*tenantIndex)
+	us.listIds = make([]string, 0, len(snapshot.Users))
+
+	for _, user := range snapshot.Users {
+		// snapshots saved before tenants were introduced hold the users of the default tenant
+		if user.TenantID == "" {
+			user.TenantID = models.DefaultTenant
+		}
+
+		index := us.index(user.TenantID)
+		us.users[user.ID] = user
+		index.byEmail[user.Email] = user.ID
+		index.byUsername[user.UserName] = user.ID
+		for key, value := range user.Attributes {
+			index.addAttribute(user.ID, key, value)
+		}
+		us.listIds = append(us.listIds, user.ID)
+	}
+
+	us.groups = make(map[string]*models.Group, len(snapshot.Groups))
+	us.listGroupIds = make([]string, 0, len(snapshot.Groups))
+	us.members = make(map[string]map[models.GroupMember]struct{})
+	us.memberOf = make(map[string]map[string]struct{})
+
+	for _, group := range snapshot.Groups {
+		us.groups[group.ID] = group
+		us.index(group.TenantID).byGroupName[group.Name] = group.ID
+		us.listGroupIds = append(us.listGroupIds, group.ID)
+	}
+
+	for _, membership := range snapshot.Memberships {
+		us.link(membership.GroupID, membership.Member)
+	}
+
+	us.invitations = make(map[string]*models.Invitation, len(snapshot.Invitations))
+	us.listInvitationIds = make([]string, 0, len(snapshot.Invitations))
+	us.byTokenHash = make(map[string]string, len(snapshot.Invitations))
+
+	for _, invitation := range snapshot.Invitations {
+		us.invitations[invitation.ID] = invitation
+		us.listInvitationIds = append(us.listInvitationIds, invitation.ID)
+		us.byTokenHash[invitation.TokenHash] = invitation.ID
+	}
+
+	us.outbox = snapshot.Outbox
+	us.events.restore(snapshot.LastSequence)
+	if us.webhooks != nil && snapshot.Webhooks != nil {
+		us.webhooks.restore(snapshot.Webhooks)
+	}
+
+	us.logger.Info("users were restored from snapshot", map[string]interface{}{
+		"path": path, "users": len(snapshot.Users), "groups": len(snapshot.Groups), "outbox": len(snapshot.Outbox), "savedAt": snapshot.SavedAt,
+	})
+
+	return true, nil
+}
+
+// snapshot copies the webhooks under the lock, the deliveries are ordered by the sequence of their events.
+func (ws *WebhookStorage) snapshot() *snapshotWebhooks {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	snapshot := &snapshotWebhooks{LastSequence: ws.lastSequence, Webhooks: make([]*models.Webhook, 0, len(ws.listIds))}
+	for _, id := range ws.listIds {
+		webhook := *ws.webhooks[id]
+		snapshot.Webhooks = append(snapshot.Webhooks, &webhook)
+	}
+
+	for _, delivery := range ws.deliveries {
+		deliveryCopy := *delivery
+		snapshot.Deliveries = append(snapshot.Deliveries, &deliveryCopy)
+	}
+
+	sort.Slice(snapshot.Deliveries, func(i, j int) bool {
+		return snapshot.Deliveries[i].Event.Sequence < snapshot.Deliveries[j].Event.Sequence
+	})
+
+	for _, id := range ws.listDeadIds {
+		deadLetter := *ws.deadLetters[id]
+		snapshot.DeadLetters = append(snapshot.DeadLetters, &deadLetter)
+	}
+
+	return snapshot
+}
+
+func (ws *WebhookStorage) restore(snapshot *snapshotWebhooks) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	ws.lastSequence = snapshot.LastSequence
+	ws.webhooks = make(map[string]*models.Webhook, len(snapshot.Webhooks))
+	ws.listIds = make([]string, 0, len(snapshot.Webhooks))
+	ws.deliveries = make(map[string]*models.WebhookDelivery, len(snapshot.Deliveries))
+	ws.deadLetters = make(map[string]*models.WebhookDelivery, len(snapshot.DeadLetters))
+	ws.listDeadIds = make([]string, 0, len(snapshot.DeadLetters))
+
+	for _, webhook := range snapshot.Webhooks {
+		ws.webhooks[webhook.ID] = webhook
+		ws.listIds = append(ws.listIds, webhook.ID)
+	}
+
+	for _, delivery := range snapshot.Deliveries {
+		ws.deliveries[delivery.ID] = delivery
+	}
+
+	for _, deadLetter := range snapshot.DeadLetters {
+		ws.deadLetters[deadLetter.ID] = deadLetter
+		ws.listDeadIds = append(ws.listDeadIds, deadLetter.ID)
+	}
+}
@@ -0,0 +1,196 @@
+package memorystorage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	storage := NewUserStorage(logg)
+	loaded, err := storage.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.False(t, loaded, "a missing snapshot is a fresh start")
+
+	require.NoError(t, storage.InitAdmin("admin", "admin", "secret"))
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:      "sales@gmail.com",
+		UserName:   "sales",
+		Password:   "hash",
+		Attributes: map[string]string{"department": "sales"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, storage.SaveSnapshot(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the snapshot holds password hashes")
+
+	restored := NewUserStorage(logg)
+	loaded, err = restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.Equal(t, storage.listIds, restored.listIds)
+	require.Equal(t, storage.users[user.ID].Password, restored.users[user.ID].Password)
+
+	found, err := restored.GetOneUserByUsername(ctx, models.DefaultTenant, "sales", false)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, found.ID)
+
+	_, count, err := restored.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10, Attributes: map[string]string{"department": "sales"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	_, err = restored.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "sales@gmail.com", UserName: "other"})
+	require.Error(t, err, "the email index is rebuilt")
+
+	require.NoError(t, restored.InitAdmin("admin", "admin", "secret"))
+	require.Len(t, restored.listIds, 2, "the restored admin is not created again")
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = restored.LoadSnapshot(path)
+	require.Error(t, err)
+	require.Len(t, restored.listIds, 2, "a broken snapshot leaves the storage as it is")
+}
+
+func TestSnapshotWebhooks(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	storage := NewUserStorage(logg)
+	storage.SetWebhookStorage(NewWebhookStorage(logg))
+	webhook, err := storage.webhooks.CreateWebhook(ctx, models.DefaultTenant, &models.Webhook{
+		URL:        "https://crm.example.com/hooks/users",
+		EventTypes: []models.UserEventType{models.EventUserCreated},
+		Secret:     "secret",
+	})
+	require.NoError(t, err)
+
+	for _, user := range []*models.User{
+		{Email: "first@gmail.com", UserName: "first"},
+		{Email: "second@gmail.com", UserName: "second"},
+	} {
+		user, err = storage.CreateUser(ctx, models.DefaultTenant, user)
+		require.NoError(t, err)
+		event := models.UserEvent{Sequence: storage.events.last(), Type: models.EventUserCreated, User: *user}
+		require.NoError(t, storage.webhooks.EnqueueDeliveries(ctx, event.Sequence, []models.WebhookDelivery{{WebhookID: webhook.ID, Event: event}}))
+	}
+
+	claimed, err := storage.webhooks.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 1)
+	require.NoError(t, err)
+	require.NoError(t, storage.webhooks.DeadLetterDelivery(ctx, claimed[0].ID, "gone"))
+	require.NoError(t, storage.SaveSnapshot(path))
+
+	restored := NewUserStorage(logg)
+	restored.SetWebhookStorage(NewWebhookStorage(logg))
+	_, err = restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), restored.events.last(), "the sequence continues after a restart")
+
+	lastSequence, err := restored.webhooks.LastSequence(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), lastSequence)
+
+	restoredWebhook, err := restored.webhooks.GetWebhook(ctx, webhook.ID)
+	require.NoError(t, err)
+	require.Equal(t, "secret", restoredWebhook.Secret)
+
+	deadLetters, count, err := restored.webhooks.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, "first", deadLetters[0].Event.User.UserName)
+
+	pending, err := restored.webhooks.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, "second", pending[0].Event.User.UserName)
+
+	_, err = restored.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "third@gmail.com", UserName: "third"})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), restored.events.last())
+}
+
+func TestSnapshotOutbox(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	storage := NewUserStorage(logg)
+	storage.SetOutboxEnabled(true)
+	for _, user := range []*models.User{
+		{Email: "first@gmail.com", UserName: "first"},
+		{Email: "second@gmail.com", UserName: "second"},
+		{Email: "third@gmail.com", UserName: "third"},
+	} {
+		_, err = storage.CreateUser(ctx, models.DefaultTenant, user)
+		require.NoError(t, err)
+	}
+
+	records, err := storage.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.NoError(t, storage.MarkOutboxDelivered(ctx, []string{records[1].ID}))
+	require.NoError(t, storage.SaveSnapshot(path))
+
+	restored := NewUserStorage(logg)
+	restored.SetOutboxEnabled(true)
+	_, err = restored.LoadSnapshot(path)
+	require.NoError(t, err)
+
+	pending, err := restored.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2, "undelivered records survive a restart")
+	require.Equal(t, []string{records[0].ID, records[2].ID}, []string{pending[0].ID, pending[1].ID})
+	require.Equal(t, "third", pending[1].Event.User.UserName)
+
+	_, err = restored.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "fourth@gmail.com", UserName: "fourth"})
+	require.NoError(t, err)
+	pending, err = restored.FetchOutbox(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	require.Equal(t, uint64(4), pending[2].Event.Sequence, "new records follow the restored ones")
+}
+
+func TestLegacySnapshot(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	// saved before tenants were introduced: no TenantID and no SuperAdmin
+	legacy := `{"Version":1,"SavedAt":"2024-01-01T00:00:00Z","Users":[
+		{"ID":"6f1b8a52-7a43-4a4b-9a3c-2a1c8e0f9d01","Email":"admin@gmail.com","UserName":"admin","Password":"hash","Admin":true,"Status":"active"},
+		{"ID":"6f1b8a52-7a43-4a4b-9a3c-2a1c8e0f9d02","Email":"ops@gmail.com","UserName":"ops","Password":"hash","Admin":true,"Status":"active"}
+	]}`
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0o600))
+
+	storage := NewUserStorage(logg)
+	loaded, err := storage.LoadSnapshot(path)
+	require.NoError(t, err)
+	require.True(t, loaded)
+	require.NoError(t, storage.InitAdmin("admin", "admin", "secret"))
+
+	admin, err := storage.GetOneUserByUsername(ctx, models.DefaultTenant, "admin", false)
+	require.NoError(t, err)
+	require.Equal(t, models.DefaultTenant, admin.TenantID)
+	require.True(t, admin.SuperAdmin, "the configured admin keeps its access to every tenant")
+	require.Equal(t, "hash", admin.Password, "the restored password is kept")
+
+	ops, err := storage.GetOneUserByUsername(ctx, models.DefaultTenant, "ops", false)
+	require.NoError(t, err)
+	require.True(t, ops.Admin)
+	require.False(t, ops.SuperAdmin)
+}
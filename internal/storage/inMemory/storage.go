@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/Baraulia/X-Labs_Test/internal/app"
 	"github.com/Baraulia/X-Labs_Test/internal/models"
@@ -13,24 +14,68 @@ import (
 )
 
 type UserStorage struct {
-	mu              sync.RWMutex
-	users           map[string]*models.User
-	indexByEmail    map[string]string
-	indexByUsername map[string]string
-	listIds         []string
-	logger          app.Logger
+	mu                sync.RWMutex
+	users             map[string]*models.User
+	tenants           map[string]*tenantIndex
+	listIds           []string
+	groups            map[string]*models.Group
+	listGroupIds      []string
+	members           map[string]map[models.GroupMember]struct{}
+	memberOf          map[string]map[string]struct{}
+	invitations       map[string]*models.Invitation
+	listInvitationIds []string
+	byTokenHash       map[string]string
+	events            *eventLog
+	outbox            []*models.OutboxRecord
+	outboxEnabled     bool
+	webhooks          *WebhookStorage
+	logger            app.Logger
+	now               func() time.Time
+}
+
+// tenantIndex makes emails, usernames and group names unique within a tenant only, user and group IDs
+// stay unique across tenants, so they are kept in a single map each.
+type tenantIndex struct {
+	byEmail     map[string]string
+	byUsername  map[string]string
+	byAttribute map[string]map[string]map[string]struct{}
+	byGroupName map[string]string
 }
 
 func NewUserStorage(logger app.Logger) *UserStorage {
+	return NewUserStorageWithClock(logger, time.Now)
+}
+
+func NewUserStorageWithClock(logger app.Logger, now func() time.Time) *UserStorage {
 	return &UserStorage{
-		users:           make(map[string]*models.User),
-		indexByEmail:    make(map[string]string),
-		indexByUsername: make(map[string]string),
-		logger:          logger,
+		users:       make(map[string]*models.User),
+		tenants:     make(map[string]*tenantIndex),
+		groups:      make(map[string]*models.Group),
+		members:     make(map[string]map[models.GroupMember]struct{}),
+		memberOf:    make(map[string]map[string]struct{}),
+		invitations: make(map[string]*models.Invitation),
+		byTokenHash: make(map[string]string),
+		events:      newEventLog(defaultEventsCapacity),
+		logger:      logger,
+		now:         now,
 	}
 }
 
+// InitAdmin creates the super-admin in the default tenant unless a user with its name, e.g. restored from
+// a snapshot, exists there. An existing admin of that name is made a super-admin.
 func (us *UserStorage) InitAdmin(initAdminName, initAdminPassword, secretKey string) error {
+	index := us.index(models.DefaultTenant)
+	if id, exists := index.byUsername[initAdminName]; exists {
+		// snapshots saved before tenants were introduced have no super-admins
+		if admin := us.users[id]; admin.Admin && !admin.SuperAdmin {
+			admin.SuperAdmin = true
+			us.logger.Warn("restored admin was made a super-admin", map[string]interface{}{"id": id})
+		}
+
+		us.logger.Info("admin already exists", map[string]interface{}{"id": id})
+		return nil
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(initAdminPassword+secretKey), bcrypt.DefaultCost)
 	if err != nil {
 		us.logger.Error("Error generating hash", map[string]interface{}{"error": err})
@@ -39,21 +84,24 @@ func (us *UserStorage) InitAdmin(initAdminName, initAdminPassword, secretKey str
 	newUUID := uuid.New().String()
 
 	us.users[newUUID] = &models.User{
-		ID:       newUUID,
-		Email:    "admin@gmail.com",
-		UserName: initAdminName,
-		Password: string(hashedPassword),
-		Admin:    true,
+		ID:         newUUID,
+		TenantID:   models.DefaultTenant,
+		Email:      "admin@gmail.com",
+		UserName:   initAdminName,
+		Password:   string(hashedPassword),
+		Admin:      true,
+		SuperAdmin: true,
+		Status:     models.StatusActive,
 	}
 
 	us.listIds = append(us.listIds, newUUID)
-	us.indexByEmail["admin@gmail.com"] = newUUID
-	us.indexByUsername[initAdminName] = newUUID
+	index.byEmail["admin@gmail.com"] = newUUID
+	index.byUsername[initAdminName] = newUUID
 
 	return nil
 }
 
-func (us *UserStorage) CreateUser(ctx context.Context, user *models.User) (*models.User, error) {
+func (us *UserStorage) CreateUser(ctx context.Context, tenantID string, user *models.User) (*models.User, error) {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
@@ -63,29 +111,88 @@ func (us *UserStorage) CreateUser(ctx context.Context, user *models.User) (*mode
 	default:
 	}
 
-	if existingID, exists := us.indexByEmail[user.Email]; exists {
-		us.logger.Error(
+	if err := us.createUser(ctx, tenantID, user); err != nil {
+		return nil, err
+	}
+
+	us.publish(models.EventUserCreated, user)
+
+	return user, nil
+}
+
+func (us *UserStorage) createUser(ctx context.Context, tenantID string, user *models.User) error {
+	if tenantID == models.AllTenants {
+		return app.ErrTenantRequired
+	}
+
+	index := us.index(tenantID)
+	if existingID, exists := index.byEmail[user.Email]; exists {
+		us.log(ctx).Error(
 			"user with a such email already exists", map[string]interface{}{"email": user.Email, "id": existingID})
-		return nil, fmt.Errorf("user with email %s already exists (ID: %s)", user.Email, existingID)
+		return fmt.Errorf("user with email %s already exists (ID: %s)", user.Email, existingID)
 	}
 
-	if existingID, exists := us.indexByUsername[user.UserName]; exists {
-		us.logger.Error(
+	if existingID, exists := index.byUsername[user.UserName]; exists {
+		us.log(ctx).Error(
 			"user with a such username already exists", map[string]interface{}{"username": user.UserName, "id": existingID})
-		return nil, fmt.Errorf("user with username %s already exists (ID: %s)", user.UserName, existingID)
+		return fmt.Errorf("user with username %s already exists (ID: %s)", user.UserName, existingID)
 	}
 
 	newUUID := uuid.New().String()
-	us.users[newUUID] = user
-	us.indexByEmail[user.Email] = newUUID
-	us.indexByUsername[user.UserName] = newUUID
-	us.listIds = append(us.listIds, newUUID)
 	user.ID = newUUID
+	user.TenantID = tenantID
+	user.CreatedAt = us.now()
+	user.UpdatedAt = user.CreatedAt
+	user.LastLoginAt = nil
+
+	// the caller keeps user, the storage keeps a copy that only changes under the lock
+	stored := copyUser(user)
+	us.users[newUUID] = &stored
+	index.byEmail[user.Email] = newUUID
+	index.byUsername[user.UserName] = newUUID
+	for key, value := range user.Attributes {
+		index.addAttribute(newUUID, key, value)
+	}
+	us.listIds = append(us.listIds, newUUID)
 
-	return user, nil
+	return nil
+}
+
+func (us *UserStorage) DeleteUser(ctx context.Context, tenantID, userID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if err := us.deleteUser(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	us.publish(models.EventUserDeleted, us.users[userID])
+	us.log(ctx).Info("user was deleted", nil)
+
+	return nil
+}
+
+func (us *UserStorage) deleteUser(ctx context.Context, tenantID, userID string) error {
+	user, exists := us.find(tenantID, userID)
+	if !exists || user.DeletedAt != nil {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		return fmt.Errorf("user with ID %s not found", userID)
+	}
+
+	deletedAt := us.now()
+	user.DeletedAt = &deletedAt
+	user.UpdatedAt = deletedAt
+
+	return nil
 }
 
-func (us *UserStorage) DeleteUser(ctx context.Context, userID string) error {
+func (us *UserStorage) UndeleteUser(ctx context.Context, tenantID, userID string) error {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
@@ -95,25 +202,88 @@ func (us *UserStorage) DeleteUser(ctx context.Context, userID string) error {
 	default:
 	}
 
-	user, exists := us.users[userID]
+	user, exists := us.find(tenantID, userID)
 	if !exists {
-		us.logger.Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
 		return fmt.Errorf("user with ID %s not found", userID)
 	}
 
-	delete(us.users, userID)
-	delete(us.indexByEmail, user.Email)
-	delete(us.indexByUsername, user.UserName)
+	if user.DeletedAt == nil {
+		us.log(ctx).Error("user is not deleted", map[string]interface{}{"id": userID})
+		return fmt.Errorf("user with ID %s is not deleted", userID)
+	}
+
+	user.DeletedAt = nil
+	user.UpdatedAt = us.now()
+
+	us.publish(models.EventUserUpdated, user)
+	us.log(ctx).Info("user was restored", nil)
+
+	return nil
+}
+
+func (us *UserStorage) PurgeUser(ctx context.Context, tenantID, userID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	user, exists := us.find(tenantID, userID)
+	if !exists {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		return fmt.Errorf("user with ID %s not found", userID)
+	}
 
-	if err := us.removeID(userID); err != nil {
+	// Soft deleted users have already been announced as deleted.
+	if user.DeletedAt == nil {
+		us.publish(models.EventUserDeleted, user)
+	}
+
+	if err := us.purge(userID); err != nil {
 		return err
 	}
-	us.logger.Info("user was deleted", nil)
+
+	us.log(ctx).Info("user was purged", nil)
 
 	return nil
 }
 
-func (us *UserStorage) UpdateUser(ctx context.Context, userDTO models.UpdateUserDTO, userID string) error {
+func (us *UserStorage) PurgeDeletedUsers(ctx context.Context, tenantID string, deletedBefore time.Time) (int, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	var expired []string
+	for _, id := range us.listIds {
+		user := us.users[id]
+		if !inTenant(user, tenantID) {
+			continue
+		}
+
+		if user.DeletedAt != nil && user.DeletedAt.Before(deletedBefore) {
+			expired = append(expired, id)
+		}
+	}
+
+	for _, id := range expired {
+		if err := us.purge(id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
+
+func (us *UserStorage) UpdateUser(ctx context.Context, tenantID string, userDTO models.UpdateUserDTO, userID string) error {
 	us.mu.Lock()
 	defer us.mu.Unlock()
 
@@ -123,34 +293,49 @@ func (us *UserStorage) UpdateUser(ctx context.Context, userDTO models.UpdateUser
 	default:
 	}
 
-	user, exists := us.users[userID]
-	if !exists {
-		us.logger.Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+	if err := us.updateUser(ctx, tenantID, userDTO, userID); err != nil {
+		return err
+	}
+
+	us.publish(models.EventUserUpdated, us.users[userID])
+	us.log(ctx).Info("user was updated", nil)
+
+	return nil
+}
+
+func (us *UserStorage) updateUser(ctx context.Context, tenantID string, userDTO models.UpdateUserDTO, userID string) error {
+	user, exists := us.find(tenantID, userID)
+	if !exists || user.DeletedAt != nil {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
 		return fmt.Errorf("user with ID %s not found", userID)
 	}
 
+	index := us.index(user.TenantID)
 	if userDTO.UserName != nil {
-		if existingID, ex := us.indexByUsername[*userDTO.UserName]; ex {
-			us.logger.Error(
-				"user with a such username already exists", map[string]interface{}{"username": user.UserName, "id": existingID})
-			return fmt.Errorf("user with username %s already exists (ID: %s)", user.UserName, existingID)
+		if existingID, ex := index.byUsername[*userDTO.UserName]; ex && existingID != userID {
+			us.log(ctx).Error(
+				"user with a such username already exists", map[string]interface{}{"username": *userDTO.UserName, "id": existingID})
+			return fmt.Errorf("user with username %s already exists (ID: %s)", *userDTO.UserName, existingID)
 		}
+	}
 
-		delete(us.indexByUsername, user.UserName)
-		us.indexByUsername[*userDTO.UserName] = userID
+	if userDTO.Email != nil {
+		if existingID, ex := index.byEmail[*userDTO.Email]; ex && existingID != userID {
+			us.log(ctx).Error(
+				"user with a such email already exists", map[string]interface{}{"email": *userDTO.Email, "id": existingID})
+			return fmt.Errorf("user with email %s already exists (ID: %s)", *userDTO.Email, existingID)
+		}
+	}
 
+	if userDTO.UserName != nil {
+		delete(index.byUsername, user.UserName)
+		index.byUsername[*userDTO.UserName] = userID
 		user.UserName = *userDTO.UserName
 	}
 
 	if userDTO.Email != nil {
-		if existingID, ex := us.indexByEmail[*userDTO.Email]; ex {
-			us.logger.Error(
-				"user with a such email already exists", map[string]interface{}{"email": user.Email, "id": existingID})
-			return fmt.Errorf("user with email %s already exists (ID: %s)", user.Email, existingID)
-		}
-
-		delete(us.indexByEmail, user.Email)
-		us.indexByEmail[*userDTO.Email] = userID
+		delete(index.byEmail, user.Email)
+		index.byEmail[*userDTO.Email] = userID
 		user.Email = *userDTO.Email
 	}
 
@@ -158,13 +343,134 @@ func (us *UserStorage) UpdateUser(ctx context.Context, userDTO models.UpdateUser
 		user.Password = *userDTO.Password
 	}
 
-	us.users[userID] = user
-	us.logger.Info("user was updated", nil)
+	if userDTO.Profile != nil {
+		user.Profile = *userDTO.Profile
+	}
+
+	// the readers may still hold the old map, so it is replaced rather than changed in place
+	if len(userDTO.Attributes) > 0 {
+		attributes := make(map[string]string, len(user.Attributes)+len(userDTO.Attributes))
+		for key, value := range user.Attributes {
+			attributes[key] = value
+		}
+
+		for key, value := range userDTO.Attributes {
+			if oldValue, ok := attributes[key]; ok {
+				index.removeAttribute(userID, key, oldValue)
+				delete(attributes, key)
+			}
+
+			if value != "" {
+				attributes[key] = value
+				index.addAttribute(userID, key, value)
+			}
+		}
+
+		user.Attributes = attributes
+	}
+
+	user.UpdatedAt = us.now()
 
 	return nil
 }
 
-func (us *UserStorage) GetUsers(ctx context.Context, offset, limit int) ([]models.User, int, error) {
+func (us *UserStorage) UpdateUserStatus(ctx context.Context, tenantID, userID string, from, to models.UserStatus, reason string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	user, exists := us.find(tenantID, userID)
+	if !exists || user.DeletedAt != nil {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		return fmt.Errorf("user with ID %s not found", userID)
+	}
+
+	if user.Status != from {
+		us.log(ctx).Error("user status was changed concurrently",
+			map[string]interface{}{"id": userID, "expected": from, "actual": user.Status})
+		return fmt.Errorf("user with ID %s has status %s, expected %s", userID, user.Status, from)
+	}
+
+	user.Status = to
+	user.StatusReason = reason
+	user.FailedLogins = 0
+	user.UpdatedAt = us.now()
+
+	us.publish(models.EventUserUpdated, user)
+
+	us.log(ctx).Info("user status was updated", map[string]interface{}{"id": userID, "status": to})
+
+	return nil
+}
+
+func (us *UserStorage) RecordLogin(ctx context.Context, tenantID, userID string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	user, exists := us.find(tenantID, userID)
+	if !exists {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		return fmt.Errorf("user with ID %s not found", userID)
+	}
+
+	lastLoginAt := us.now()
+	user.LastLoginAt = &lastLoginAt
+	user.FailedLogins = 0
+
+	return nil
+}
+
+// RecordFailedLogin counts a wrong password of an active user and locks the user once maxFailedLogins
+// failures follow each other, the counter is reset by a login and by a change of the status.
+func (us *UserStorage) RecordFailedLogin(ctx context.Context, tenantID, userID string, maxFailedLogins int) (bool, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return false, ctx.Err()
+	default:
+	}
+
+	user, exists := us.find(tenantID, userID)
+	if !exists || user.DeletedAt != nil {
+		us.log(ctx).Error("user with a such ID does not exist", map[string]interface{}{"id": userID})
+		return false, fmt.Errorf("user with ID %s not found", userID)
+	}
+
+	if user.Status != models.StatusActive {
+		return false, nil
+	}
+
+	user.FailedLogins++
+	if user.FailedLogins < maxFailedLogins {
+		return false, nil
+	}
+
+	user.Status = models.StatusLocked
+	user.StatusReason = "too many failed logins"
+	user.FailedLogins = 0
+	user.UpdatedAt = us.now()
+
+	us.publish(models.EventUserUpdated, user)
+
+	us.log(ctx).Warn("user was locked after failed logins", map[string]interface{}{"id": userID, "failedLogins": maxFailedLogins})
+
+	return true, nil
+}
+
+func (us *UserStorage) GetUsers(ctx context.Context, tenantID string, filter models.UsersFilter) ([]models.User, int, error) {
 	us.mu.RLock()
 	defer us.mu.RUnlock()
 	var start, finish int
@@ -175,7 +481,18 @@ func (us *UserStorage) GetUsers(ctx context.Context, offset, limit int) ([]model
 	default:
 	}
 
-	count := len(us.listIds)
+	visibleIds := make([]string, 0, len(us.listIds))
+	if tenantID == models.AllTenants || us.lookup(tenantID).hasAttributeCandidates(filter.Attributes) {
+		for _, id := range us.listIds {
+			user := us.users[id]
+			if inTenant(user, tenantID) && us.lookup(user.TenantID).matchAttributes(id, filter.Attributes) && matchFilter(user, filter) {
+				visibleIds = append(visibleIds, id)
+			}
+		}
+	}
+
+	count := len(visibleIds)
+	offset, limit := filter.Offset, filter.Limit
 
 	if offset >= count {
 		return make([]models.User, 0), count, nil
@@ -190,14 +507,14 @@ func (us *UserStorage) GetUsers(ctx context.Context, offset, limit int) ([]model
 	}
 
 	listUsers := make([]models.User, 0)
-	for _, id := range us.listIds[start:finish] {
-		listUsers = append(listUsers, *us.users[id])
+	for _, id := range visibleIds[start:finish] {
+		listUsers = append(listUsers, copyUser(us.users[id]))
 	}
 
 	return listUsers, count, nil
 }
 
-func (us *UserStorage) GetOneUserByID(ctx context.Context, userID string) (*models.User, error) {
+func (us *UserStorage) GetOneUserByID(ctx context.Context, tenantID, userID string, showDeleted bool) (*models.User, error) {
 	us.mu.RLock()
 	defer us.mu.RUnlock()
 
@@ -207,16 +524,18 @@ func (us *UserStorage) GetOneUserByID(ctx context.Context, userID string) (*mode
 	default:
 	}
 
-	user, ok := us.users[userID]
-	if !ok {
-		us.logger.Error("user does not exist", map[string]interface{}{"id": userID})
+	user, ok := us.find(tenantID, userID)
+	if !ok || (!showDeleted && user.DeletedAt != nil) {
+		us.log(ctx).Error("user does not exist", map[string]interface{}{"id": userID})
 		return nil, fmt.Errorf("user with id: %s does not exist", userID)
 	}
 
-	return user, nil
+	userCopy := copyUser(user)
+
+	return &userCopy, nil
 }
 
-func (us *UserStorage) GetOneUserByUsername(ctx context.Context, userName string) (*models.User, error) {
+func (us *UserStorage) GetOneUserByUsername(ctx context.Context, tenantID, userName string, showDeleted bool) (*models.User, error) {
 	us.mu.RLock()
 	defer us.mu.RUnlock()
 
@@ -226,13 +545,117 @@ func (us *UserStorage) GetOneUserByUsername(ctx context.Context, userName string
 	default:
 	}
 
-	existingID, exists := us.indexByUsername[userName]
-	if !exists {
-		us.logger.Error("user with a such username does not exist", map[string]interface{}{"username": userName})
+	if tenantID == models.AllTenants {
+		return nil, app.ErrTenantRequired
+	}
+
+	existingID, exists := us.lookup(tenantID).byUsername[userName]
+	if !exists || (!showDeleted && us.users[existingID].DeletedAt != nil) {
+		us.log(ctx).Error("user with a such username does not exist", map[string]interface{}{"username": userName})
 		return nil, fmt.Errorf("user with username %s does not exist", userName)
 	}
 
-	return us.users[existingID], nil
+	userCopy := copyUser(us.users[existingID])
+
+	return &userCopy, nil
+}
+
+func matchFilter(user *models.User, filter models.UsersFilter) bool {
+	if !filter.ShowDeleted && user.DeletedAt != nil {
+		return false
+	}
+
+	if filter.CreatedAfter != nil && !user.CreatedAt.After(*filter.CreatedAfter) {
+		return false
+	}
+
+	if filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+
+	if filter.UpdatedAfter != nil && !user.UpdatedAt.After(*filter.UpdatedAfter) {
+		return false
+	}
+
+	if filter.UpdatedBefore != nil && !user.UpdatedAt.Before(*filter.UpdatedBefore) {
+		return false
+	}
+
+	if filter.LastLoginAfter != nil && (user.LastLoginAt == nil || !user.LastLoginAt.After(*filter.LastLoginAfter)) {
+		return false
+	}
+
+	// Users that have never logged in are treated as inactive since the beginning of time.
+	if filter.LastLoginBefore != nil && user.LastLoginAt != nil && !user.LastLoginAt.Before(*filter.LastLoginBefore) {
+		return false
+	}
+
+	return true
+}
+
+func (index *tenantIndex) hasAttributeCandidates(attributes map[string]string) bool {
+	for key, value := range attributes {
+		if len(index.byAttribute[key][value]) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (index *tenantIndex) matchAttributes(userID string, attributes map[string]string) bool {
+	for key, value := range attributes {
+		if _, ok := index.byAttribute[key][value][userID]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (index *tenantIndex) addAttribute(userID, key, value string) {
+	values, ok := index.byAttribute[key]
+	if !ok {
+		values = make(map[string]map[string]struct{})
+		index.byAttribute[key] = values
+	}
+
+	ids, ok := values[value]
+	if !ok {
+		ids = make(map[string]struct{})
+		values[value] = ids
+	}
+
+	ids[userID] = struct{}{}
+}
+
+func (index *tenantIndex) removeAttribute(userID, key, value string) {
+	ids := index.byAttribute[key][value]
+	delete(ids, userID)
+
+	if len(ids) == 0 {
+		delete(index.byAttribute[key], value)
+	}
+
+	if len(index.byAttribute[key]) == 0 {
+		delete(index.byAttribute, key)
+	}
+}
+
+func (us *UserStorage) purge(userID string) error {
+	user := us.users[userID]
+	index := us.index(user.TenantID)
+
+	delete(us.users, userID)
+	delete(index.byEmail, user.Email)
+	delete(index.byUsername, user.UserName)
+	for key, value := range user.Attributes {
+		index.removeAttribute(userID, key, value)
+	}
+	us.leaveGroups(models.GroupMember{Type: models.MemberUser, ID: userID})
+	us.dropInvitation(userID)
+
+	return us.removeID(userID)
 }
 
 func (us *UserStorage) removeID(targetID string) error {
@@ -253,3 +676,60 @@ func (us *UserStorage) removeID(targetID string) error {
 
 	return nil
 }
+
+// index returns the indexes of the tenant, creating them on first use, so it needs the write lock.
+func (us *UserStorage) index(tenantID string) *tenantIndex {
+	index, ok := us.tenants[tenantID]
+	if !ok {
+		index = &tenantIndex{
+			byEmail:     make(map[string]string),
+			byUsername:  make(map[string]string),
+			byAttribute: make(map[string]map[string]map[string]struct{}),
+			byGroupName: make(map[string]string),
+		}
+		us.tenants[tenantID] = index
+	}
+
+	return index
+}
+
+// lookup returns the indexes of the tenant for reading, an unknown tenant has empty ones.
+func (us *UserStorage) lookup(tenantID string) *tenantIndex {
+	if index, ok := us.tenants[tenantID]; ok {
+		return index
+	}
+
+	return &tenantIndex{}
+}
+
+// find hides the users of other tenants as if they did not exist.
+func (us *UserStorage) find(tenantID, userID string) (*models.User, bool) {
+	user, ok := us.users[userID]
+	if !ok || !inTenant(user, tenantID) {
+		return nil, false
+	}
+
+	return user, true
+}
+
+func inTenant(user *models.User, tenantID string) bool {
+	return tenantID == models.AllTenants || user.TenantID == tenantID
+}
+
+// Ping takes the read lock, so it fails the health probe when the storage mutex is held for too long.
+func (us *UserStorage) Ping(ctx context.Context) error {
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	return nil
+}
+
+func (us *UserStorage) log(ctx context.Context) app.Logger {
+	return app.ContextLogger(ctx, us.logger)
+}
@@ -2,8 +2,12 @@ package memorystorage
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/Baraulia/X-Labs_Test/internal/app"
 	"github.com/Baraulia/X-Labs_Test/internal/models"
 	"github.com/Baraulia/X-Labs_Test/pkg/logger"
 	"github.com/stretchr/testify/require"
@@ -78,7 +82,7 @@ func TestCreateUser(t *testing.T) {
 	storage := NewUserStorage(logg)
 	ctx := context.Background()
 
-	user, err := storage.CreateUser(ctx, &testUsers[0])
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &testUsers[0])
 	require.NoError(t, err)
 
 	userFromStorage, ok := storage.users[user.ID]
@@ -95,12 +99,12 @@ func TestUpdateUser(t *testing.T) {
 	storage := NewUserStorage(logg)
 	ctx := context.Background()
 
-	user, err := storage.CreateUser(ctx, &testUsers[0])
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &testUsers[0])
 	require.NoError(t, err)
 
 	oldUserName := user.UserName
 	newUsername := "newUsername"
-	err = storage.UpdateUser(ctx, models.UpdateUserDTO{
+	err = storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{
 		UserName: &newUsername,
 	}, user.ID)
 	require.NoError(t, err)
@@ -121,7 +125,7 @@ func TestGetUsers(t *testing.T) {
 	ctx := context.Background()
 
 	for _, user := range testUsers {
-		_, err = storage.CreateUser(ctx, &user)
+		_, err = storage.CreateUser(ctx, models.DefaultTenant, &user)
 		require.NoError(t, err)
 	}
 
@@ -138,7 +142,7 @@ func TestGetUsers(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			users, count, err := storage.GetUsers(ctx, test.offset, test.limit)
+			users, count, err := storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Offset: test.offset, Limit: test.limit})
 			require.NoError(t, err)
 			require.Equal(t, test.expectedCount, len(users))
 			require.Equal(t, count, len(testUsers))
@@ -152,7 +156,7 @@ func TestGetUserById(t *testing.T) {
 	storage := NewUserStorage(logg)
 	ctx := context.Background()
 
-	user, err := storage.CreateUser(ctx, &testUsers[0])
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &testUsers[0])
 	require.NoError(t, err)
 
 	userFromStorage, ok := storage.users[user.ID]
@@ -160,7 +164,7 @@ func TestGetUserById(t *testing.T) {
 		t.Error("User not added to the storage")
 	}
 
-	userByID, err := storage.GetOneUserByID(ctx, user.ID)
+	userByID, err := storage.GetOneUserByID(ctx, models.DefaultTenant, user.ID, false)
 	require.NoError(t, err)
 
 	require.Equal(t, userByID.UserName, userFromStorage.UserName)
@@ -172,7 +176,7 @@ func TestGetUserByUserName(t *testing.T) {
 	storage := NewUserStorage(logg)
 	ctx := context.Background()
 
-	user, err := storage.CreateUser(ctx, &testUsers[0])
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &testUsers[0])
 	require.NoError(t, err)
 
 	userFromStorage, ok := storage.users[user.ID]
@@ -180,8 +184,427 @@ func TestGetUserByUserName(t *testing.T) {
 		t.Error("User not added to the storage")
 	}
 
-	userByUserName, err := storage.GetOneUserByUsername(ctx, user.UserName)
+	userByUserName, err := storage.GetOneUserByUsername(ctx, models.DefaultTenant, user.UserName, false)
 	require.NoError(t, err)
 
 	require.Equal(t, userByUserName.UserName, userFromStorage.UserName)
 }
+
+func TestDeleteUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "deleted@gmail.com", UserName: "deleted"})
+	require.NoError(t, err)
+
+	err = storage.DeleteUser(ctx, models.DefaultTenant, user.ID)
+	require.NoError(t, err)
+
+	require.NotNil(t, storage.users[user.ID].DeletedAt)
+
+	_, err = storage.GetOneUserByID(ctx, models.DefaultTenant, user.ID, false)
+	require.Error(t, err)
+
+	_, err = storage.GetOneUserByUsername(ctx, models.DefaultTenant, user.UserName, false)
+	require.Error(t, err)
+
+	deletedUser, err := storage.GetOneUserByID(ctx, models.DefaultTenant, user.ID, true)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, deletedUser.ID)
+
+	users, count, err := storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Empty(t, users)
+
+	users, count, err = storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10, ShowDeleted: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Len(t, users, 1)
+
+	err = storage.DeleteUser(ctx, models.DefaultTenant, user.ID)
+	require.Error(t, err)
+}
+
+func TestUndeleteUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "restored@gmail.com", UserName: "restored"})
+	require.NoError(t, err)
+
+	err = storage.UndeleteUser(ctx, models.DefaultTenant, user.ID)
+	require.Error(t, err)
+
+	err = storage.DeleteUser(ctx, models.DefaultTenant, user.ID)
+	require.NoError(t, err)
+
+	err = storage.UndeleteUser(ctx, models.DefaultTenant, user.ID)
+	require.NoError(t, err)
+
+	restoredUser, err := storage.GetOneUserByID(ctx, models.DefaultTenant, user.ID, false)
+	require.NoError(t, err)
+	require.Nil(t, restoredUser.DeletedAt)
+}
+
+func TestPurgeUser(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "purged@gmail.com", UserName: "purged"})
+	require.NoError(t, err)
+
+	err = storage.PurgeUser(ctx, models.DefaultTenant, user.ID)
+	require.NoError(t, err)
+
+	_, ok := storage.users[user.ID]
+	require.False(t, ok)
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byEmail, user.Email)
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byUsername, user.UserName)
+	require.NotContains(t, storage.listIds, user.ID)
+
+	err = storage.PurgeUser(ctx, models.DefaultTenant, user.ID)
+	require.Error(t, err)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	expiredUser, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "expired@gmail.com", UserName: "expired"})
+	require.NoError(t, err)
+	recentUser, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "recent@gmail.com", UserName: "recent"})
+	require.NoError(t, err)
+	activeUser, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "active@gmail.com", UserName: "active"})
+	require.NoError(t, err)
+
+	require.NoError(t, storage.DeleteUser(ctx, models.DefaultTenant, expiredUser.ID))
+	require.NoError(t, storage.DeleteUser(ctx, models.DefaultTenant, recentUser.ID))
+
+	expiredAt := time.Now().Add(-48 * time.Hour)
+	storage.users[expiredUser.ID].DeletedAt = &expiredAt
+
+	purged, err := storage.PurgeDeletedUsers(ctx, models.DefaultTenant, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	require.NotContains(t, storage.users, expiredUser.ID)
+	require.Contains(t, storage.users, recentUser.ID)
+	require.Contains(t, storage.users, activeUser.ID)
+}
+
+func TestUpdateUserStatus(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:    "status@gmail.com",
+		UserName: "status",
+		Status:   models.StatusActive,
+	})
+	require.NoError(t, err)
+
+	err = storage.UpdateUserStatus(ctx, models.DefaultTenant, user.ID, models.StatusActive, models.StatusSuspended, "fraud")
+	require.NoError(t, err)
+	require.Equal(t, models.StatusSuspended, storage.users[user.ID].Status)
+	require.Equal(t, "fraud", storage.users[user.ID].StatusReason)
+
+	err = storage.UpdateUserStatus(ctx, models.DefaultTenant, user.ID, models.StatusActive, models.StatusLocked, "")
+	require.Error(t, err)
+	require.Equal(t, models.StatusSuspended, storage.users[user.ID].Status)
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestTimestamps(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	storage := NewUserStorageWithClock(logg, clock.Now)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "time@gmail.com", UserName: "time"})
+	require.NoError(t, err)
+	require.Equal(t, clock.now, user.CreatedAt)
+	require.Equal(t, clock.now, user.UpdatedAt)
+	require.Nil(t, user.LastLoginAt)
+
+	createdAt := clock.now
+	clock.Advance(time.Hour)
+	newEmail := "time2@gmail.com"
+	err = storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{Email: &newEmail}, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, createdAt, storage.users[user.ID].CreatedAt)
+	require.Equal(t, clock.now, storage.users[user.ID].UpdatedAt)
+
+	clock.Advance(time.Hour)
+	err = storage.RecordLogin(ctx, models.DefaultTenant, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, clock.now, *storage.users[user.ID].LastLoginAt)
+}
+
+func TestRecordFailedLogin(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	user, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email: "locked@gmail.com", UserName: "locked", Status: models.StatusActive,
+	})
+	require.NoError(t, err)
+
+	locked, err := storage.RecordFailedLogin(ctx, models.DefaultTenant, user.ID, 3)
+	require.NoError(t, err)
+	require.False(t, locked)
+	require.NoError(t, storage.RecordLogin(ctx, models.DefaultTenant, user.ID))
+	require.Zero(t, storage.users[user.ID].FailedLogins, "a login resets the failures")
+
+	for i := 1; i <= 3; i++ {
+		locked, err = storage.RecordFailedLogin(ctx, models.DefaultTenant, user.ID, 3)
+		require.NoError(t, err)
+		require.Equal(t, i == 3, locked)
+	}
+	require.Equal(t, models.StatusLocked, storage.users[user.ID].Status)
+	require.Equal(t, uint64(2), storage.events.last(), "the lock is published")
+
+	locked, err = storage.RecordFailedLogin(ctx, models.DefaultTenant, user.ID, 3)
+	require.NoError(t, err)
+	require.False(t, locked, "only active users are counted")
+
+	require.NoError(t, storage.UpdateUserStatus(ctx, models.DefaultTenant, user.ID, models.StatusLocked, models.StatusActive, "unlocked"))
+	locked, err = storage.RecordFailedLogin(ctx, models.DefaultTenant, user.ID, 3)
+	require.NoError(t, err)
+	require.False(t, locked, "an unlocked user starts over")
+
+	_, err = storage.RecordFailedLogin(ctx, "acme", user.ID, 3)
+	require.Error(t, err)
+}
+
+func TestGetUsersTimeFilters(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	clock := &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	storage := NewUserStorageWithClock(logg, clock.Now)
+	ctx := context.Background()
+
+	oldUser, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "old@gmail.com", UserName: "old"})
+	require.NoError(t, err)
+	require.NoError(t, storage.RecordLogin(ctx, models.DefaultTenant, oldUser.ID))
+
+	clock.Advance(100 * 24 * time.Hour)
+	_, err = storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "never@gmail.com", UserName: "never"})
+	require.NoError(t, err)
+	recentUser, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{Email: "recent@gmail.com", UserName: "recent"})
+	require.NoError(t, err)
+	require.NoError(t, storage.RecordLogin(ctx, models.DefaultTenant, recentUser.ID))
+
+	ninetyDaysAgo := clock.now.Add(-90 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		filter        models.UsersFilter
+		expectedNames []string
+	}{
+		{
+			name:          "created after",
+			filter:        models.UsersFilter{Limit: 10, CreatedAfter: &ninetyDaysAgo},
+			expectedNames: []string{"never", "recent"},
+		},
+		{
+			name:          "created before",
+			filter:        models.UsersFilter{Limit: 10, CreatedBefore: &ninetyDaysAgo},
+			expectedNames: []string{"old"},
+		},
+		{
+			name:          "last login before",
+			filter:        models.UsersFilter{Limit: 10, LastLoginBefore: &ninetyDaysAgo},
+			expectedNames: []string{"old", "never"},
+		},
+		{
+			name:          "last login after",
+			filter:        models.UsersFilter{Limit: 10, LastLoginAfter: &ninetyDaysAgo},
+			expectedNames: []string{"recent"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users, count, err := storage.GetUsers(ctx, models.DefaultTenant, test.filter)
+			require.NoError(t, err)
+			require.Equal(t, len(test.expectedNames), count)
+
+			names := make([]string, 0, len(users))
+			for _, user := range users {
+				names = append(names, user.UserName)
+			}
+			require.Equal(t, test.expectedNames, names)
+		})
+	}
+}
+
+func TestAttributesIndex(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	sales, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:      "sales@gmail.com",
+		UserName:   "sales",
+		Attributes: map[string]string{"department": "sales", "newsletter": "true"},
+	})
+	require.NoError(t, err)
+	_, err = storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:      "support@gmail.com",
+		UserName:   "support",
+		Attributes: map[string]string{"department": "support", "newsletter": "true"},
+	})
+	require.NoError(t, err)
+
+	users, count, err := storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10, Attributes: map[string]string{"newsletter": "true"}})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, users, 2)
+
+	users, count, err = storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{
+		Limit:      10,
+		Attributes: map[string]string{"newsletter": "true", "department": "sales"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, sales.ID, users[0].ID)
+
+	err = storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{
+		Attributes: map[string]string{"department": "marketing", "newsletter": ""},
+	}, sales.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"department": "marketing"}, storage.users[sales.ID].Attributes)
+
+	_, count, err = storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10, Attributes: map[string]string{"department": "sales"}})
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byAttribute["department"], "sales")
+
+	_, count, err = storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10, Attributes: map[string]string{"department": "marketing"}})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	require.NoError(t, storage.PurgeUser(ctx, models.DefaultTenant, sales.ID))
+	require.NotContains(t, storage.lookup(models.DefaultTenant).byAttribute["department"], "marketing")
+}
+
+func TestReadersDoNotShareUsers(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	created, err := storage.CreateUser(ctx, models.DefaultTenant, &models.User{
+		Email:      "sales@gmail.com",
+		UserName:   "sales",
+		Attributes: map[string]string{"department": "sales"},
+	})
+	require.NoError(t, err)
+
+	byID, err := storage.GetOneUserByID(ctx, models.DefaultTenant, created.ID, false)
+	require.NoError(t, err)
+	byUsername, err := storage.GetOneUserByUsername(ctx, models.DefaultTenant, "sales", false)
+	require.NoError(t, err)
+	users, _, err := storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = storage.UpdateUser(ctx, models.DefaultTenant, models.UpdateUserDTO{
+				Attributes: map[string]string{"department": "marketing", "shift": strconv.Itoa(i)},
+			}, created.ID)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		listed, _, err := storage.GetUsers(ctx, models.DefaultTenant, models.UsersFilter{Limit: 10})
+		require.NoError(t, err)
+		for key := range listed[0].Attributes {
+			_ = listed[0].Attributes[key]
+		}
+	}
+	wg.Wait()
+
+	for _, user := range []models.User{*created, *byID, *byUsername, users[0]} {
+		require.Equal(t, map[string]string{"department": "sales"}, user.Attributes, "a returned user is a snapshot")
+	}
+}
+
+func TestTenants(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+	ctx := context.Background()
+
+	acme, err := storage.CreateUser(ctx, "acme", &models.User{Email: "admin@gmail.com", UserName: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, "acme", acme.TenantID)
+	globex, err := storage.CreateUser(ctx, "globex", &models.User{Email: "admin@gmail.com", UserName: "admin"})
+	require.NoError(t, err, "emails and usernames are unique within a tenant")
+
+	_, err = storage.CreateUser(ctx, "acme", &models.User{Email: "admin@gmail.com", UserName: "other"})
+	require.Error(t, err)
+	_, err = storage.CreateUser(ctx, models.AllTenants, &models.User{Email: "all@gmail.com", UserName: "all"})
+	require.ErrorIs(t, err, app.ErrTenantRequired)
+
+	found, err := storage.GetOneUserByUsername(ctx, "globex", "admin", false)
+	require.NoError(t, err)
+	require.Equal(t, globex.ID, found.ID)
+
+	_, err = storage.GetOneUserByID(ctx, "globex", acme.ID, false)
+	require.Error(t, err, "a user is not found in another tenant")
+	require.Error(t, storage.DeleteUser(ctx, "globex", acme.ID))
+
+	_, count, err := storage.GetUsers(ctx, "acme", models.UsersFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	_, count, err = storage.GetUsers(ctx, models.AllTenants, models.UsersFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	found, err = storage.GetOneUserByID(ctx, models.AllTenants, acme.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, "acme", found.TenantID)
+}
+
+func TestPing(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := NewUserStorage(logg)
+
+	require.NoError(t, storage.Ping(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, storage.Ping(ctx), context.Canceled)
+}
@@ -0,0 +1,369 @@
+package memorystorage
+
+//nolint:depguard
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/google/uuid"
+)
+
+// WebhookStorage keeps the subscriptions and the delivery queue. It is saved with the users snapshot once
+// attached by UserStorage.SetWebhookStorage. Webhooks and dead letters of other tenants are hidden as if
+// they did not exist, GetWebhook and the queue methods are left unscoped for the dispatcher.
+type WebhookStorage struct {
+	mu           sync.RWMutex
+	webhooks     map[string]*models.Webhook
+	listIds      []string
+	deliveries   map[string]*models.WebhookDelivery
+	deadLetters  map[string]*models.WebhookDelivery
+	listDeadIds  []string
+	lastSequence uint64
+	logger       app.Logger
+	now          func() time.Time
+}
+
+func NewWebhookStorage(logger app.Logger) *WebhookStorage {
+	return &WebhookStorage{
+		webhooks:    make(map[string]*models.Webhook),
+		deliveries:  make(map[string]*models.WebhookDelivery),
+		deadLetters: make(map[string]*models.WebhookDelivery),
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+func (ws *WebhookStorage) CreateWebhook(ctx context.Context, tenantID string, webhook *models.Webhook) (*models.Webhook, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if tenantID == models.AllTenants {
+		return nil, app.ErrTenantRequired
+	}
+
+	newUUID := uuid.New().String()
+	webhook.ID = newUUID
+	webhook.TenantID = tenantID
+	webhook.CreatedAt = ws.now()
+	ws.webhooks[newUUID] = webhook
+	ws.listIds = append(ws.listIds, newUUID)
+
+	return webhook, nil
+}
+
+func (ws *WebhookStorage) DeleteWebhook(ctx context.Context, tenantID, webhookID string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if webhook, exists := ws.webhooks[webhookID]; !exists || !webhookInTenant(webhook.TenantID, tenantID) {
+		ws.log(ctx).Error("webhook with a such ID does not exist", map[string]interface{}{"id": webhookID})
+		return fmt.Errorf("webhook with ID %s not found", webhookID)
+	}
+
+	delete(ws.webhooks, webhookID)
+	ws.listIds = removeString(ws.listIds, webhookID)
+
+	for id, delivery := range ws.deliveries {
+		if delivery.WebhookID == webhookID {
+			delete(ws.deliveries, id)
+		}
+	}
+
+	ws.log(ctx).Info("webhook was deleted", map[string]interface{}{"id": webhookID})
+
+	return nil
+}
+
+func (ws *WebhookStorage) GetWebhook(ctx context.Context, webhookID string) (*models.Webhook, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	webhook, exists := ws.webhooks[webhookID]
+	if !exists {
+		return nil, fmt.Errorf("webhook with ID %s not found", webhookID)
+	}
+
+	webhookCopy := *webhook
+
+	return &webhookCopy, nil
+}
+
+func (ws *WebhookStorage) GetWebhooks(ctx context.Context, tenantID string) ([]models.Webhook, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	webhooks := make([]models.Webhook, 0, len(ws.listIds))
+	for _, id := range ws.listIds {
+		if webhookInTenant(ws.webhooks[id].TenantID, tenantID) {
+			webhooks = append(webhooks, *ws.webhooks[id])
+		}
+	}
+
+	return webhooks, nil
+}
+
+// EnqueueDeliveries adds the deliveries of the event with the sequence and records it as the last consumed
+// one, deliveries may be empty when no webhook is subscribed to the event. A delivery belongs to the tenant
+// of its webhook.
+func (ws *WebhookStorage) EnqueueDeliveries(ctx context.Context, sequence uint64, deliveries []models.WebhookDelivery) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	ws.lastSequence = sequence
+
+	for i := range deliveries {
+		delivery := deliveries[i]
+		delivery.ID = uuid.New().String()
+		if webhook, exists := ws.webhooks[delivery.WebhookID]; exists {
+			delivery.TenantID = webhook.TenantID
+		}
+
+		delivery.CreatedAt = ws.now()
+		if delivery.NextAttemptAt.IsZero() {
+			delivery.NextAttemptAt = delivery.CreatedAt
+		}
+
+		ws.deliveries[delivery.ID] = &delivery
+	}
+
+	return nil
+}
+
+// LastSequence returns the sequence of the last event passed to EnqueueDeliveries, 0 before the first one.
+func (ws *WebhookStorage) LastSequence(ctx context.Context) (uint64, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	return ws.lastSequence, nil
+}
+
+// ClaimDueDeliveries returns deliveries that are due and hides them from other claims for the lease duration,
+// so a delivery whose worker died is picked up again once the lease expires.
+func (ws *WebhookStorage) ClaimDueDeliveries(
+	ctx context.Context, now time.Time, lease time.Duration, limit int,
+) ([]models.WebhookDelivery, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	due := make([]*models.WebhookDelivery, 0)
+	for _, delivery := range ws.deliveries {
+		if !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Event.Sequence < due[j].Event.Sequence
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]models.WebhookDelivery, 0, len(due))
+	for _, delivery := range due {
+		claimed = append(claimed, *delivery)
+		delivery.NextAttemptAt = now.Add(lease)
+	}
+
+	return claimed, nil
+}
+
+func (ws *WebhookStorage) CompleteDelivery(ctx context.Context, deliveryID string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	delete(ws.deliveries, deliveryID)
+
+	return nil
+}
+
+func (ws *WebhookStorage) RescheduleDelivery(
+	ctx context.Context, deliveryID string, nextAttemptAt time.Time, lastError string,
+) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	delivery, exists := ws.deliveries[deliveryID]
+	if !exists {
+		return fmt.Errorf("delivery with ID %s not found", deliveryID)
+	}
+
+	delivery.Attempts++
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.LastError = lastError
+
+	return nil
+}
+
+func (ws *WebhookStorage) DeadLetterDelivery(ctx context.Context, deliveryID string, lastError string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	delivery, exists := ws.deliveries[deliveryID]
+	if !exists {
+		return fmt.Errorf("delivery with ID %s not found", deliveryID)
+	}
+
+	delivery.Attempts++
+	delivery.LastError = lastError
+
+	delete(ws.deliveries, deliveryID)
+	ws.deadLetters[deliveryID] = delivery
+	ws.listDeadIds = append(ws.listDeadIds, deliveryID)
+
+	ws.log(ctx).Warn("webhook delivery was moved to dead letters",
+		map[string]interface{}{"id": deliveryID, "webhookID": delivery.WebhookID, "error": lastError})
+
+	return nil
+}
+
+func (ws *WebhookStorage) GetDeadLetters(
+	ctx context.Context, tenantID string, offset, limit int,
+) ([]models.WebhookDelivery, int, error) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	default:
+	}
+
+	visibleIds := make([]string, 0, len(ws.listDeadIds))
+	for _, id := range ws.listDeadIds {
+		if webhookInTenant(ws.deadLetters[id].TenantID, tenantID) {
+			visibleIds = append(visibleIds, id)
+		}
+	}
+
+	count := len(visibleIds)
+	if offset >= count {
+		return make([]models.WebhookDelivery, 0), count, nil
+	}
+
+	finish := count
+	if offset+limit < count {
+		finish = offset + limit
+	}
+
+	deadLetters := make([]models.WebhookDelivery, 0, finish-offset)
+	for _, id := range visibleIds[offset:finish] {
+		deadLetters = append(deadLetters, *ws.deadLetters[id])
+	}
+
+	return deadLetters, count, nil
+}
+
+func (ws *WebhookStorage) RetryDeadLetter(ctx context.Context, tenantID, deliveryID string) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	delivery, exists := ws.deadLetters[deliveryID]
+	if !exists || !webhookInTenant(delivery.TenantID, tenantID) {
+		ws.log(ctx).Error("dead letter with a such ID does not exist", map[string]interface{}{"id": deliveryID})
+		return fmt.Errorf("dead letter with ID %s not found", deliveryID)
+	}
+
+	if _, exists = ws.webhooks[delivery.WebhookID]; !exists {
+		return fmt.Errorf("webhook with ID %s not found", delivery.WebhookID)
+	}
+
+	delete(ws.deadLetters, deliveryID)
+	ws.listDeadIds = removeString(ws.listDeadIds, deliveryID)
+
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = ws.now()
+	ws.deliveries[deliveryID] = delivery
+
+	return nil
+}
+
+func webhookInTenant(webhookTenantID, tenantID string) bool {
+	return tenantID == models.AllTenants || webhookTenantID == tenantID
+}
+
+func removeString(list []string, target string) []string {
+	for i, item := range list {
+		if item == target {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+
+	return list
+}
+
+func (ws *WebhookStorage) log(ctx context.Context) app.Logger {
+	return app.ContextLogger(ctx, ws.logger)
+}
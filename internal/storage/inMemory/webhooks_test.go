@@ -0,0 +1,153 @@
+package memorystorage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhookStorage(t *testing.T) (*WebhookStorage, *models.Webhook) {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	storage := NewWebhookStorage(logg)
+	webhook, err := storage.CreateWebhook(context.Background(), models.DefaultTenant, &models.Webhook{
+		URL:        "https://crm.example.com/hooks/users",
+		EventTypes: []models.UserEventType{models.EventUserCreated},
+		Secret:     "secret",
+	})
+	require.NoError(t, err)
+
+	return storage, webhook
+}
+
+func TestClaimDueDeliveries(t *testing.T) {
+	storage, webhook := newTestWebhookStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, storage.EnqueueDeliveries(ctx, 3, []models.WebhookDelivery{
+		{WebhookID: webhook.ID, Event: models.UserEvent{Sequence: 2}},
+		{WebhookID: webhook.ID, Event: models.UserEvent{Sequence: 1}},
+		{WebhookID: webhook.ID, Event: models.UserEvent{Sequence: 3}, NextAttemptAt: time.Now().Add(time.Hour)},
+	}))
+	now := time.Now()
+
+	claimed, err := storage.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, uint64(1), claimed[0].Event.Sequence)
+	require.Equal(t, uint64(2), claimed[1].Event.Sequence)
+
+	claimed, err = storage.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed, "claimed deliveries are leased")
+
+	claimed, err = storage.ClaimDueDeliveries(ctx, now.Add(2*time.Minute), time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "expired lease makes a delivery due again")
+}
+
+func TestRescheduleAndDeadLetter(t *testing.T) {
+	storage, webhook := newTestWebhookStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, storage.EnqueueDeliveries(ctx, 1, []models.WebhookDelivery{{WebhookID: webhook.ID}}))
+	now := time.Now()
+	claimed, err := storage.ClaimDueDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	deliveryID := claimed[0].ID
+
+	require.NoError(t, storage.RescheduleDelivery(ctx, deliveryID, now.Add(time.Second), "timeout"))
+	claimed, err = storage.ClaimDueDeliveries(ctx, now.Add(time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, 1, claimed[0].Attempts)
+	require.Equal(t, "timeout", claimed[0].LastError)
+
+	require.NoError(t, storage.DeadLetterDelivery(ctx, deliveryID, "gone"))
+	deadLetters, count, err := storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Equal(t, 2, deadLetters[0].Attempts)
+	require.Equal(t, "gone", deadLetters[0].LastError)
+
+	require.NoError(t, storage.RetryDeadLetter(ctx, models.DefaultTenant, deliveryID))
+	_, count, err = storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	claimed, err = storage.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, 0, claimed[0].Attempts)
+
+	require.Error(t, storage.RetryDeadLetter(ctx, models.DefaultTenant, deliveryID))
+}
+
+func TestDeleteWebhookDropsDeliveries(t *testing.T) {
+	storage, webhook := newTestWebhookStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, storage.EnqueueDeliveries(ctx, 1, []models.WebhookDelivery{{WebhookID: webhook.ID}}))
+	require.NoError(t, storage.DeleteWebhook(ctx, models.DefaultTenant, webhook.ID))
+
+	webhooks, err := storage.GetWebhooks(ctx, models.DefaultTenant)
+	require.NoError(t, err)
+	require.Empty(t, webhooks)
+
+	claimed, err := storage.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	require.Error(t, storage.DeleteWebhook(ctx, models.DefaultTenant, webhook.ID))
+}
+
+func TestWebhooksTenantIsolation(t *testing.T) {
+	storage, webhook := newTestWebhookStorage(t)
+	ctx := context.Background()
+
+	_, err := storage.CreateWebhook(ctx, models.AllTenants, &models.Webhook{URL: "https://crm.example.com/hooks/users"})
+	require.Error(t, err)
+
+	acmeWebhook, err := storage.CreateWebhook(ctx, "acme", &models.Webhook{
+		URL:        "https://acme.example.com/hooks/users",
+		EventTypes: []models.UserEventType{models.EventUserCreated},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "acme", acmeWebhook.TenantID)
+
+	webhooks, err := storage.GetWebhooks(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	require.Equal(t, acmeWebhook.ID, webhooks[0].ID)
+
+	webhooks, err = storage.GetWebhooks(ctx, models.AllTenants)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+
+	require.Error(t, storage.DeleteWebhook(ctx, "acme", webhook.ID), "a webhook of another tenant is not found")
+
+	require.NoError(t, storage.EnqueueDeliveries(ctx, 1, []models.WebhookDelivery{{WebhookID: webhook.ID}}))
+	claimed, err := storage.ClaimDueDeliveries(ctx, time.Now(), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, models.DefaultTenant, claimed[0].TenantID)
+	require.NoError(t, storage.DeadLetterDelivery(ctx, claimed[0].ID, "gone"))
+
+	deadLetters, count, err := storage.GetDeadLetters(ctx, "acme", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Empty(t, deadLetters)
+	require.Error(t, storage.RetryDeadLetter(ctx, "acme", claimed[0].ID))
+
+	_, count, err = storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
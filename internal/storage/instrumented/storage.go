@@ -0,0 +1,276 @@
+package instrumented
+
+//nolint:depguard
+import (
+	"context"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+)
+
+// Observer is called before every storage operation, it may derive ctx (e.g. start a span)
+// and returns the callback invoked with the operation result.
+type Observer func(ctx context.Context, operation string) (context.Context, func(err error))
+
+// Storage decorates a StorageInterface implementation with observers such as metrics and tracing.
+type Storage struct {
+	storage   app.StorageInterface
+	observers []Observer
+}
+
+// New wraps storage, the first observer being the outermost one.
+func New(storage app.StorageInterface, observers ...Observer) *Storage {
+	return &Storage{storage: storage, observers: observers}
+}
+
+func (s *Storage) start(ctx context.Context, operation string) (context.Context, func(err error)) {
+	finishers := make([]func(err error), len(s.observers))
+	for i, observer := range s.observers {
+		ctx, finishers[i] = observer(ctx, operation)
+	}
+
+	return ctx, func(err error) {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			finishers[i](err)
+		}
+	}
+}
+
+func (s *Storage) CreateUser(ctx context.Context, tenantID string, userDTO *models.User) (user *models.User, err error) {
+	ctx, finish := s.start(ctx, "CreateUser")
+	defer func() { finish(err) }()
+
+	return s.storage.CreateUser(ctx, tenantID, userDTO)
+}
+
+func (s *Storage) UpdateUser(ctx context.Context, tenantID string, userDTO models.UpdateUserDTO, userID string) (err error) {
+	ctx, finish := s.start(ctx, "UpdateUser")
+	defer func() { finish(err) }()
+
+	return s.storage.UpdateUser(ctx, tenantID, userDTO, userID)
+}
+
+func (s *Storage) DeleteUser(ctx context.Context, tenantID, userID string) (err error) {
+	ctx, finish := s.start(ctx, "DeleteUser")
+	defer func() { finish(err) }()
+
+	return s.storage.DeleteUser(ctx, tenantID, userID)
+}
+
+func (s *Storage) UndeleteUser(ctx context.Context, tenantID, userID string) (err error) {
+	ctx, finish := s.start(ctx, "UndeleteUser")
+	defer func() { finish(err) }()
+
+	return s.storage.UndeleteUser(ctx, tenantID, userID)
+}
+
+func (s *Storage) PurgeUser(ctx context.Context, tenantID, userID string) (err error) {
+	ctx, finish := s.start(ctx, "PurgeUser")
+	defer func() { finish(err) }()
+
+	return s.storage.PurgeUser(ctx, tenantID, userID)
+}
+
+func (s *Storage) PurgeDeletedUsers(ctx context.Context, tenantID string, deletedBefore time.Time) (purged int, err error) {
+	ctx, finish := s.start(ctx, "PurgeDeletedUsers")
+	defer func() { finish(err) }()
+
+	return s.storage.PurgeDeletedUsers(ctx, tenantID, deletedBefore)
+}
+
+func (s *Storage) UpdateUserStatus(ctx context.Context, tenantID, userID string, from, to models.UserStatus, reason string) (err error) {
+	ctx, finish := s.start(ctx, "UpdateUserStatus")
+	defer func() { finish(err) }()
+
+	return s.storage.UpdateUserStatus(ctx, tenantID, userID, from, to, reason)
+}
+
+func (s *Storage) RecordLogin(ctx context.Context, tenantID, userID string) (err error) {
+	ctx, finish := s.start(ctx, "RecordLogin")
+	defer func() { finish(err) }()
+
+	return s.storage.RecordLogin(ctx, tenantID, userID)
+}
+
+func (s *Storage) RecordFailedLogin(ctx context.Context, tenantID, userID string, maxFailedLogins int) (locked bool, err error) {
+	ctx, finish := s.start(ctx, "RecordFailedLogin")
+	defer func() { finish(err) }()
+
+	return s.storage.RecordFailedLogin(ctx, tenantID, userID, maxFailedLogins)
+}
+
+func (s *Storage) BatchCreateUsers(ctx context.Context, tenantID string, users []*models.User, allOrNothing bool) (errs []error, err error) {
+	ctx, finish := s.start(ctx, "BatchCreateUsers")
+	defer func() { finish(err) }()
+
+	return s.storage.BatchCreateUsers(ctx, tenantID, users, allOrNothing)
+}
+
+func (s *Storage) BatchUpdateUsers(ctx context.Context, tenantID string, updates []models.UserUpdate, allOrNothing bool) (errs []error, err error) {
+	ctx, finish := s.start(ctx, "BatchUpdateUsers")
+	defer func() { finish(err) }()
+
+	return s.storage.BatchUpdateUsers(ctx, tenantID, updates, allOrNothing)
+}
+
+func (s *Storage) BatchDeleteUsers(ctx context.Context, tenantID string, userIDs []string, allOrNothing bool) (errs []error, err error) {
+	ctx, finish := s.start(ctx, "BatchDeleteUsers")
+	defer func() { finish(err) }()
+
+	return s.storage.BatchDeleteUsers(ctx, tenantID, userIDs, allOrNothing)
+}
+
+// WatchUsers is not observed: it blocks for the lifetime of the subscription.
+func (s *Storage) WatchUsers(ctx context.Context, tenantID string, afterSequence uint64, fromNow bool, send func(models.UserEvent) error) error {
+	return s.storage.WatchUsers(ctx, tenantID, afterSequence, fromNow, send)
+}
+
+func (s *Storage) FetchOutbox(ctx context.Context, limit int) (records []models.OutboxRecord, err error) {
+	ctx, finish := s.start(ctx, "FetchOutbox")
+	defer func() { finish(err) }()
+
+	return s.storage.FetchOutbox(ctx, limit)
+}
+
+func (s *Storage) MarkOutboxDelivered(ctx context.Context, recordIDs []string) (err error) {
+	ctx, finish := s.start(ctx, "MarkOutboxDelivered")
+	defer func() { finish(err) }()
+
+	return s.storage.MarkOutboxDelivered(ctx, recordIDs)
+}
+
+func (s *Storage) GetUsers(ctx context.Context, tenantID string, filter models.UsersFilter) (users []models.User, total int, err error) {
+	ctx, finish := s.start(ctx, "GetUsers")
+	defer func() { finish(err) }()
+
+	return s.storage.GetUsers(ctx, tenantID, filter)
+}
+
+func (s *Storage) GetOneUserByID(ctx context.Context, tenantID, userID string, showDeleted bool) (user *models.User, err error) {
+	ctx, finish := s.start(ctx, "GetOneUserByID")
+	defer func() { finish(err) }()
+
+	return s.storage.GetOneUserByID(ctx, tenantID, userID, showDeleted)
+}
+
+func (s *Storage) GetOneUserByUsername(ctx context.Context, tenantID string, userName string, showDeleted bool) (user *models.User, err error) {
+	ctx, finish := s.start(ctx, "GetOneUserByUsername")
+	defer func() { finish(err) }()
+
+	return s.storage.GetOneUserByUsername(ctx, tenantID, userName, showDeleted)
+}
+
+func (s *Storage) CreateGroup(ctx context.Context, tenantID string, group *models.Group) (created *models.Group, err error) {
+	ctx, finish := s.start(ctx, "CreateGroup")
+	defer func() { finish(err) }()
+
+	return s.storage.CreateGroup(ctx, tenantID, group)
+}
+
+func (s *Storage) UpdateGroup(ctx context.Context, tenantID string, groupDTO models.UpdateGroupDTO, groupID string) (err error) {
+	ctx, finish := s.start(ctx, "UpdateGroup")
+	defer func() { finish(err) }()
+
+	return s.storage.UpdateGroup(ctx, tenantID, groupDTO, groupID)
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, tenantID, groupID string) (err error) {
+	ctx, finish := s.start(ctx, "DeleteGroup")
+	defer func() { finish(err) }()
+
+	return s.storage.DeleteGroup(ctx, tenantID, groupID)
+}
+
+func (s *Storage) GetGroup(ctx context.Context, tenantID, groupID string) (group *models.Group, err error) {
+	ctx, finish := s.start(ctx, "GetGroup")
+	defer func() { finish(err) }()
+
+	return s.storage.GetGroup(ctx, tenantID, groupID)
+}
+
+func (s *Storage) GetGroups(ctx context.Context, tenantID string, offset, limit int) (groups []models.Group, total int, err error) {
+	ctx, finish := s.start(ctx, "GetGroups")
+	defer func() { finish(err) }()
+
+	return s.storage.GetGroups(ctx, tenantID, offset, limit)
+}
+
+func (s *Storage) AddGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) (err error) {
+	ctx, finish := s.start(ctx, "AddGroupMember")
+	defer func() { finish(err) }()
+
+	return s.storage.AddGroupMember(ctx, tenantID, groupID, member)
+}
+
+func (s *Storage) RemoveGroupMember(ctx context.Context, tenantID, groupID string, member models.GroupMember) (err error) {
+	ctx, finish := s.start(ctx, "RemoveGroupMember")
+	defer func() { finish(err) }()
+
+	return s.storage.RemoveGroupMember(ctx, tenantID, groupID, member)
+}
+
+func (s *Storage) GetGroupMembers(ctx context.Context, tenantID, groupID string, recursive bool) (members *models.GroupMembers, err error) {
+	ctx, finish := s.start(ctx, "GetGroupMembers")
+	defer func() { finish(err) }()
+
+	return s.storage.GetGroupMembers(ctx, tenantID, groupID, recursive)
+}
+
+func (s *Storage) GetUserGroups(ctx context.Context, tenantID, userID string, recursive bool) (groups []models.Group, err error) {
+	ctx, finish := s.start(ctx, "GetUserGroups")
+	defer func() { finish(err) }()
+
+	return s.storage.GetUserGroups(ctx, tenantID, userID, recursive)
+}
+
+func (s *Storage) CreateInvitation(
+	ctx context.Context, tenantID string, user *models.User, invitation *models.Invitation,
+) (created *models.Invitation, err error) {
+	ctx, finish := s.start(ctx, "CreateInvitation")
+	defer func() { finish(err) }()
+
+	return s.storage.CreateInvitation(ctx, tenantID, user, invitation)
+}
+
+func (s *Storage) GetInvitations(ctx context.Context, tenantID string, offset, limit int) (invitations []models.Invitation, total int, err error) {
+	ctx, finish := s.start(ctx, "GetInvitations")
+	defer func() { finish(err) }()
+
+	return s.storage.GetInvitations(ctx, tenantID, offset, limit)
+}
+
+func (s *Storage) CheckInvitation(ctx context.Context, tokenHash string) (err error) {
+	ctx, finish := s.start(ctx, "CheckInvitation")
+	defer func() { finish(err) }()
+
+	return s.storage.CheckInvitation(ctx, tokenHash)
+}
+
+func (s *Storage) AcceptInvitation(ctx context.Context, tokenHash, userName, password string) (user *models.User, err error) {
+	ctx, finish := s.start(ctx, "AcceptInvitation")
+	defer func() { finish(err) }()
+
+	return s.storage.AcceptInvitation(ctx, tokenHash, userName, password)
+}
+
+func (s *Storage) RevokeInvitation(ctx context.Context, tenantID, invitationID string) (err error) {
+	ctx, finish := s.start(ctx, "RevokeInvitation")
+	defer func() { finish(err) }()
+
+	return s.storage.RevokeInvitation(ctx, tenantID, invitationID)
+}
+
+func (s *Storage) PurgeExpiredInvitations(ctx context.Context, tenantID string, expiredBefore time.Time) (purged int, err error) {
+	ctx, finish := s.start(ctx, "PurgeExpiredInvitations")
+	defer func() { finish(err) }()
+
+	return s.storage.PurgeExpiredInvitations(ctx, tenantID, expiredBefore)
+}
+
+func (s *Storage) Ping(ctx context.Context) (err error) {
+	ctx, finish := s.start(ctx, "Ping")
+	defer func() { finish(err) }()
+
+	return s.storage.Ping(ctx)
+}
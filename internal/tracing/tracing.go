@@ -0,0 +1,65 @@
+package tracing
+
+//nolint:depguard
+import (
+	"context"
+
+	"github.com/Baraulia/X-Labs_Test/internal/storage/instrumented"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/Baraulia/X-Labs_Test/internal/tracing"
+
+// Setup installs a tracer provider exporting through exporter and the W3C trace-context propagator as globals,
+// so App and storage spans created with otel.Tracer join the request spans. The returned provider must be shut
+// down on exit to flush buffered spans.
+func Setup(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider
+}
+
+// StorageObserver wraps every storage operation of the given backend into a child span.
+// Operations outside a sampled request, e.g. background workers, are not traced.
+func StorageObserver(backend string) instrumented.Observer {
+	tracer := otel.Tracer(instrumentationName)
+
+	return func(ctx context.Context, operation string) (context.Context, func(err error)) {
+		if !trace.SpanFromContext(ctx).IsRecording() {
+			return ctx, func(error) {}
+		}
+
+		ctx, span := tracer.Start(ctx, "storage."+operation, trace.WithAttributes(
+			attribute.String("db.system", backend),
+			attribute.String("db.operation", operation),
+		))
+
+		return ctx, func(err error) {
+			End(span, err)
+		}
+	}
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
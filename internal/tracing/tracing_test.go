@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	memorystorage "github.com/Baraulia/X-Labs_Test/internal/storage/inMemory"
+	"github.com/Baraulia/X-Labs_Test/internal/storage/instrumented"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/Baraulia/X-Labs_Test/pkg/validation"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func setupRecorder(t *testing.T) func() tracetest.SpanStubs {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := Setup(exporter, "test", 1)
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	return func() tracetest.SpanStubs {
+		require.NoError(t, provider.ForceFlush(context.Background()))
+		return exporter.GetSpans()
+	}
+}
+
+func TestUnaryInterceptorPropagation(t *testing.T) {
+	spans := setupRecorder(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceParent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetOneUserByID"}
+
+	_, err := UnaryInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(grpccodes.NotFound, "user does not exist")
+	})
+	require.Error(t, err)
+
+	recorded := spans()
+	require.Len(t, recorded, 1)
+	span := recorded[0]
+	require.Equal(t, "user.UserService/GetOneUserByID", span.Name)
+	require.Equal(t, traceID, span.SpanContext.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	require.True(t, span.Parent.IsRemote())
+	require.Equal(t, codes.Error, span.Status.Code)
+	require.Equal(t, "user does not exist", span.Status.Description)
+}
+
+func TestChildSpans(t *testing.T) {
+	spans := setupRecorder(t)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := instrumented.New(memorystorage.NewUserStorage(logg), StorageObserver("memory"))
+	service := app.NewApp(logg, storage, validation.New(), "secret")
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceParent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/CreateUser"}
+	_, err = UnaryInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return service.CreateUser(ctx, &models.User{Email: "traced@gmail.com", UserName: "traced", Password: "password"})
+	})
+	require.NoError(t, err)
+
+	parents := make(map[string]string)
+	ids := make(map[string]string)
+	for _, span := range spans() {
+		require.Equal(t, traceID, span.SpanContext.TraceID().String())
+		ids[span.SpanContext.SpanID().String()] = span.Name
+		parents[span.Name] = span.Parent.SpanID().String()
+	}
+
+	require.Equal(t, "user.UserService/CreateUser", ids[parents["App.CreateUser"]])
+	require.Equal(t, "App.CreateUser", ids[parents["App.validateUser"]])
+	require.Equal(t, "App.CreateUser", ids[parents["App.hashPassword"]])
+	require.Equal(t, "App.CreateUser", ids[parents["storage.CreateUser"]])
+}
+
+func TestUntracedStorage(t *testing.T) {
+	spans := setupRecorder(t)
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	storage := instrumented.New(memorystorage.NewUserStorage(logg), StorageObserver("memory"))
+
+	_, _, err = storage.GetUsers(context.Background(), models.DefaultTenant, models.UsersFilter{})
+	require.NoError(t, err)
+	require.Empty(t, spans())
+}
@@ -0,0 +1,282 @@
+package webhook
+
+//nolint:depguard
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/internal/outbox"
+)
+
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	maxErrorBodySize = 512
+)
+
+type EventSource interface {
+	WatchUsers(ctx context.Context, afterSequence uint64, fromNow bool, send func(models.UserEvent) error) error
+}
+
+type Config struct {
+	Workers        int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	PollInterval   time.Duration
+	Timeout        time.Duration
+}
+
+type Dispatcher struct {
+	logger  app.Logger
+	storage app.WebhookStorage
+	events  EventSource
+	client  *http.Client
+	config  Config
+	now     func() time.Time
+}
+
+func NewDispatcher(logger app.Logger, storage app.WebhookStorage, events EventSource, config Config) *Dispatcher {
+	return &Dispatcher{
+		logger:  logger,
+		storage: storage,
+		events:  events,
+		client:  &http.Client{Timeout: config.Timeout},
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// Run enqueues a delivery for every subscribed webhook on each user event and delivers due items
+// until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	if d.config.PollInterval <= 0 || d.config.Workers <= 0 {
+		d.logger.Warn("webhook dispatcher is disabled", map[string]interface{}{
+			"pollInterval": d.config.PollInterval, "workers": d.config.Workers,
+		})
+
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.consume(ctx)
+	}()
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			d.deliverDue(ctx)
+		}
+	}
+}
+
+// consume resumes after the last enqueued event, which survives restarts with the storage snapshot.
+// It watches the events of all tenants, enqueue routes each of them to the webhooks of its tenant.
+func (d *Dispatcher) consume(ctx context.Context) {
+	watchCtx := app.WithTenant(ctx, models.AllTenants)
+
+	after, err := d.storage.LastSequence(ctx)
+	if err != nil {
+		d.logger.Error("error while reading the last enqueued sequence", map[string]interface{}{"error": err})
+	}
+
+	fromNow := false
+
+	for {
+		err = d.events.WatchUsers(watchCtx, after, fromNow, func(event models.UserEvent) error {
+			after, fromNow = event.Sequence, false
+			return d.enqueue(ctx, event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, app.ErrSequenceExpired) {
+			d.logger.Warn("webhook dispatcher fell behind the change feed, some events were skipped",
+				map[string]interface{}{"afterSequence": after})
+			fromNow = true
+
+			continue
+		}
+
+		d.logger.Error("webhook dispatcher lost the change feed", map[string]interface{}{"error": err})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.config.PollInterval):
+		}
+	}
+}
+
+// enqueue records the sequence even when no webhook is subscribed, so the event is not replayed.
+// Only the webhooks of the tenant of the user receive the event.
+func (d *Dispatcher) enqueue(ctx context.Context, event models.UserEvent) error {
+	webhooks, err := d.storage.GetWebhooks(ctx, event.User.TenantID)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if subscribed(webhook, event.Type) {
+			deliveries = append(deliveries, models.WebhookDelivery{WebhookID: webhook.ID, Event: event})
+		}
+	}
+
+	return d.storage.EnqueueDeliveries(ctx, event.Sequence, deliveries)
+}
+
+// deliverDue drains the due deliveries, sending at most Workers of them concurrently.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	lease := d.config.Timeout + d.config.PollInterval
+
+	for ctx.Err() == nil {
+		deliveries, err := d.storage.ClaimDueDeliveries(ctx, d.now(), lease, d.config.Workers)
+		if err != nil {
+			d.logger.Error("error while claiming webhook deliveries", map[string]interface{}{"error": err})
+			return
+		}
+
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery models.WebhookDelivery) {
+				defer wg.Done()
+				d.deliver(ctx, delivery)
+			}(deliveries[i])
+		}
+
+		wg.Wait()
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	webhook, err := d.storage.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		// the subscription was removed after the delivery had been enqueued
+		if err = d.storage.CompleteDelivery(ctx, delivery.ID); err != nil {
+			d.logger.Error("error while dropping webhook delivery", map[string]interface{}{"id": delivery.ID, "error": err})
+		}
+
+		return
+	}
+
+	sendErr := d.send(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case sendErr == nil:
+		err = d.storage.CompleteDelivery(ctx, delivery.ID)
+	case delivery.Attempts+1 >= d.config.MaxAttempts:
+		err = d.storage.DeadLetterDelivery(ctx, delivery.ID, sendErr.Error())
+	default:
+		d.logger.Warn("webhook delivery failed, will retry",
+			map[string]interface{}{"id": delivery.ID, "webhookID": webhook.ID, "attempt": delivery.Attempts + 1, "error": sendErr})
+		err = d.storage.RescheduleDelivery(ctx, delivery.ID, d.now().Add(d.backoff(delivery.Attempts)), sendErr.Error())
+	}
+
+	if err != nil {
+		d.logger.Error("error while updating webhook delivery", map[string]interface{}{"id": delivery.ID, "error": err})
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook *models.Webhook, delivery models.WebhookDelivery) error {
+	body, err := json.Marshal(outbox.NewMessage(delivery.ID, delivery.Event))
+	if err != nil {
+		return fmt.Errorf("error while marshal payload: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error while build request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderDelivery, delivery.ID)
+	request.Header.Set(HeaderEvent, string(delivery.Event.Type))
+	request.Header.Set(HeaderTimestamp, timestamp)
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+		return fmt.Errorf("receiver responded with %d: %s", response.StatusCode, message)
+	}
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	return nil
+}
+
+// backoff doubles the initial delay with every failed attempt, capped at MaxBackoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+// Sign returns the value of the signature header: a hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the webhook secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribed(webhook models.Webhook, eventType models.UserEventType) bool {
+	for _, subscribedType := range webhook.EventTypes {
+		if subscribedType == eventType {
+			return true
+		}
+	}
+
+	return false
+}
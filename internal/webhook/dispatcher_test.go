@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Baraulia/X-Labs_Test/internal/app"
+	"github.com/Baraulia/X-Labs_Test/internal/models"
+	"github.com/Baraulia/X-Labs_Test/internal/outbox"
+	memorystorage "github.com/Baraulia/X-Labs_Test/internal/storage/inMemory"
+	"github.com/Baraulia/X-Labs_Test/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type eventSource struct {
+	events chan models.UserEvent
+	after  chan uint64
+}
+
+func (s eventSource) WatchUsers(ctx context.Context, afterSequence uint64, _ bool, send func(models.UserEvent) error) error {
+	if tenantID := app.TenantFromContext(ctx); tenantID != models.AllTenants {
+		return fmt.Errorf("the change feed of tenant %s only", tenantID)
+	}
+
+	if s.after != nil {
+		s.after <- afterSequence
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-s.events:
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	failures int32
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+
+	if atomic.AddInt32(&r.failures, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.requests)
+}
+
+func setup(t *testing.T, failures int32, eventTypes ...models.UserEventType) (*receiver, *memorystorage.WebhookStorage, chan models.UserEvent) {
+	t.Helper()
+
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+
+	rec := &receiver{failures: failures}
+	server := httptest.NewServer(rec)
+	t.Cleanup(server.Close)
+
+	storage := memorystorage.NewWebhookStorage(logg)
+	_, err = storage.CreateWebhook(context.Background(), models.DefaultTenant, &models.Webhook{
+		URL:        server.URL,
+		EventTypes: eventTypes,
+		Secret:     "secret",
+	})
+	require.NoError(t, err)
+
+	events := make(chan models.UserEvent)
+	dispatcher := NewDispatcher(logg, storage, eventSource{events: events}, Config{
+		Workers:        2,
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		PollInterval:   5 * time.Millisecond,
+		Timeout:        time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return rec, storage, events
+}
+
+func testEvent(sequence uint64, eventType models.UserEventType) models.UserEvent {
+	return models.UserEvent{
+		Sequence:   sequence,
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		User: models.User{
+			ID:       "0b6f2a4e-55a4-4c1c-8d3c-6a6d5c1c9b51",
+			TenantID: models.DefaultTenant,
+			Email:    "test@gmail.com",
+			UserName: "testUserName",
+			Password: "$2a$10$hash",
+			Status:   models.StatusActive,
+		},
+	}
+}
+
+func TestDeliverSignedPayload(t *testing.T) {
+	rec, _, events := setup(t, 0, models.EventUserCreated)
+
+	events <- testEvent(1, models.EventUserCreated)
+	require.Eventually(t, func() bool { return rec.received() == 1 }, time.Second, 5*time.Millisecond)
+
+	rec.mu.Lock()
+	req, body := rec.requests[0], rec.bodies[0]
+	rec.mu.Unlock()
+
+	require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	require.Equal(t, "created", req.Header.Get(HeaderEvent))
+	require.NotEmpty(t, req.Header.Get(HeaderDelivery))
+	require.Equal(t, Sign("secret", req.Header.Get(HeaderTimestamp), body), req.Header.Get(HeaderSignature))
+	require.NotContains(t, string(body), "$2a$10$hash")
+
+	var received outbox.Message
+	require.NoError(t, json.Unmarshal(body, &received))
+	require.Equal(t, "user.created", received.Event)
+	require.Equal(t, uint64(1), received.Sequence)
+	require.Equal(t, "testUserName", received.User.UserName)
+	require.Equal(t, models.DefaultTenant, received.User.TenantID)
+}
+
+func TestDeliverToWebhooksOfEventTenant(t *testing.T) {
+	rec, storage, events := setup(t, 0, models.EventUserCreated)
+
+	acmeRec := &receiver{}
+	acmeServer := httptest.NewServer(acmeRec)
+	t.Cleanup(acmeServer.Close)
+
+	_, err := storage.CreateWebhook(context.Background(), "acme", &models.Webhook{
+		URL:        acmeServer.URL,
+		EventTypes: []models.UserEventType{models.EventUserCreated},
+		Secret:     "acme-secret",
+	})
+	require.NoError(t, err)
+
+	acmeEvent := testEvent(1, models.EventUserCreated)
+	acmeEvent.User.TenantID = "acme"
+	events <- acmeEvent
+	events <- testEvent(2, models.EventUserCreated)
+	require.Eventually(t, func() bool { return rec.received() == 1 && acmeRec.received() == 1 }, time.Second, 5*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 1, rec.received(), "an event of another tenant is not delivered")
+	require.Equal(t, 1, acmeRec.received())
+
+	acmeRec.mu.Lock()
+	defer acmeRec.mu.Unlock()
+
+	var received outbox.Message
+	require.NoError(t, json.Unmarshal(acmeRec.bodies[0], &received))
+	require.Equal(t, uint64(1), received.Sequence)
+	require.Equal(t, "acme", received.User.TenantID)
+}
+
+func TestSkipUnsubscribedEvents(t *testing.T) {
+	rec, _, events := setup(t, 0, models.EventUserDeleted)
+
+	events <- testEvent(1, models.EventUserCreated)
+	events <- testEvent(2, models.EventUserDeleted)
+	require.Eventually(t, func() bool { return rec.received() == 1 }, time.Second, 5*time.Millisecond)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	require.Equal(t, "deleted", rec.requests[0].Header.Get(HeaderEvent))
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	rec, storage, events := setup(t, 2, models.EventUserUpdated)
+
+	events <- testEvent(1, models.EventUserUpdated)
+	require.Eventually(t, func() bool { return rec.received() == 3 }, time.Second, 5*time.Millisecond)
+
+	rec.mu.Lock()
+	require.Equal(t, rec.requests[0].Header.Get(HeaderDelivery), rec.requests[2].Header.Get(HeaderDelivery))
+	rec.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 3, rec.received())
+
+	deadLetters, count, err := storage.GetDeadLetters(context.Background(), models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	require.Empty(t, deadLetters)
+}
+
+func TestDeadLetter(t *testing.T) {
+	rec, storage, events := setup(t, 100, models.EventUserCreated)
+	ctx := context.Background()
+
+	events <- testEvent(1, models.EventUserCreated)
+	require.Eventually(t, func() bool {
+		_, count, err := storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+		return err == nil && count == 1
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, 3, rec.received())
+
+	deadLetters, _, err := storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, deadLetters[0].Attempts)
+	require.Contains(t, deadLetters[0].LastError, "503")
+
+	atomic.StoreInt32(&rec.failures, 0)
+	require.NoError(t, storage.RetryDeadLetter(ctx, models.DefaultTenant, deadLetters[0].ID))
+	require.Eventually(t, func() bool { return rec.received() == 4 }, time.Second, 5*time.Millisecond)
+
+	_, count, err := storage.GetDeadLetters(ctx, models.DefaultTenant, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestResumeAfterLastEnqueued(t *testing.T) {
+	logg, err := logger.GetLogger("INFO")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := memorystorage.NewWebhookStorage(logg)
+	require.NoError(t, storage.EnqueueDeliveries(ctx, 7, nil))
+
+	source := eventSource{events: make(chan models.UserEvent), after: make(chan uint64, 1)}
+	dispatcher := NewDispatcher(logg, storage, source, Config{Workers: 1, PollInterval: time.Second})
+	go dispatcher.consume(ctx)
+
+	select {
+	case after := <-source.after:
+		require.Equal(t, uint64(7), after)
+	case <-time.After(time.Second):
+		t.Fatal("the dispatcher did not watch the change feed")
+	}
+
+	source.events <- testEvent(8, models.EventUserDeleted)
+	require.Eventually(t, func() bool {
+		lastSequence, err := storage.LastSequence(ctx)
+		return err == nil && lastSequence == 8
+	}, time.Second, 5*time.Millisecond, "events without subscribers advance the sequence too")
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher := Dispatcher{config: Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	require.Equal(t, time.Second, dispatcher.backoff(0))
+	require.Equal(t, 2*time.Second, dispatcher.backoff(1))
+	require.Equal(t, 8*time.Second, dispatcher.backoff(3))
+	require.Equal(t, 10*time.Second, dispatcher.backoff(4))
+	require.Equal(t, 10*time.Second, dispatcher.backoff(40))
+}
@@ -0,0 +1,294 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+)
+
+// Config describes where and how records are written. Without sinks the logger writes to stdout.
+// RedactKeys and RedactPatterns extend the built-in redaction rules.
+type Config struct {
+	Level          string
+	Format         string
+	Sinks          []SinkConfig
+	RedactKeys     []string
+	RedactPatterns []string
+}
+
+// SinkConfig is one output. Empty Level and Format fall back to the logger ones; a sink never
+// writes records below the logger level.
+type SinkConfig struct {
+	Type     string
+	Level    string
+	Format   string
+	File     FileConfig
+	Sampling SamplingConfig
+}
+
+// FileConfig configures rotation of a file sink: the file is rotated once it reaches MaxSizeMB,
+// rotated files older than MaxAgeDays or beyond MaxBackups are removed.
+type FileConfig struct {
+	Path       string
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+	Compress   bool
+}
+
+// SamplingConfig keeps the first Initial records with the same level and message within every Tick
+// and then every Thereafter-th one. Only records at or below Level (DEBUG by default) are sampled,
+// sampling is disabled when Initial is zero.
+type SamplingConfig struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+	Level      string
+}
+
+// New builds a logger from config, it fails on unknown levels, formats or sink types.
+func New(config Config) (*ZapLogger, error) {
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor, err := newRedactor(config.RedactKeys, config.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{Type: SinkStdout}}
+	}
+
+	levels := newLevels(level)
+	cores := make([]zapcore.Core, 0, len(sinks))
+	closers := make([]io.Closer, 0)
+	for _, sink := range sinks {
+		core, closer, err := newSinkCore(sink, config.Format, levels.atomic)
+		if err != nil {
+			return nil, err
+		}
+
+		cores = append(cores, core)
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+	}
+
+	return &ZapLogger{logger: zap.New(zapcore.NewTee(cores...)).Sugar(), redactor: redactor, levels: levels, closers: closers}, nil
+}
+
+// Validate reports all the errors New could fail with, without opening any sink.
+func (c Config) Validate() error {
+	var errs []error
+	if _, err := parseLevel(c.Level); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := newEncoder(c.Format); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := newRedactor(c.RedactKeys, c.RedactPatterns); err != nil {
+		errs = append(errs, err)
+	}
+
+	for i, sink := range c.Sinks {
+		if err := sink.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("sink %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s SinkConfig) validate() error {
+	var errs []error
+	for _, level := range []string{s.Level, s.Sampling.Level} {
+		if level == "" {
+			continue
+		}
+
+		if _, err := parseLevel(level); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, err := newEncoder(s.Format); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch s.Type {
+	case SinkStdout, SinkStderr, "":
+	case SinkFile:
+		if s.File.Path == "" {
+			errs = append(errs, errors.New("file sink of logger requires a path"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unsupported sink of logger: %s", s.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
+// newSinkCore builds the core of one sink, it writes records enabled both by the sink level and the
+// logger level, which may change at runtime.
+func newSinkCore(sink SinkConfig, defaultFormat string, loggerLevel zap.AtomicLevel) (zapcore.Core, io.Closer, error) {
+	sinkLevel := zapcore.DebugLevel
+	if sink.Level != "" {
+		var err error
+		if sinkLevel, err = parseLevel(sink.Level); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	level := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return level >= sinkLevel && loggerLevel.Enabled(level)
+	})
+
+	format := sink.Format
+	if format == "" {
+		format = defaultFormat
+	}
+
+	encoder, err := newEncoder(format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		writer zapcore.WriteSyncer
+		closer io.Closer
+	)
+
+	switch sink.Type {
+	case SinkStdout, "":
+		writer = zapcore.Lock(os.Stdout)
+	case SinkStderr:
+		writer = zapcore.Lock(os.Stderr)
+	case SinkFile:
+		if sink.File.Path == "" {
+			return nil, nil, errors.New("file sink of logger requires a path")
+		}
+
+		rotator := &lumberjack.Logger{
+			Filename:   sink.File.Path,
+			MaxSize:    sink.File.MaxSizeMB,
+			MaxAge:     sink.File.MaxAgeDays,
+			MaxBackups: sink.File.MaxBackups,
+			Compress:   sink.File.Compress,
+		}
+		writer, closer = zapcore.AddSync(rotator), rotator
+	default:
+		return nil, nil, fmt.Errorf("unsupported sink of logger: %s", sink.Type)
+	}
+
+	core, err := sampled(zapcore.NewCore(encoder, writer, level), sink.Sampling)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return core, closer, nil
+}
+
+func newEncoder(format string) (zapcore.Encoder, error) {
+	switch strings.ToLower(format) {
+	case FormatConsole, "":
+		return zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), nil
+	case FormatJSON:
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("unsupported format of logger: %s", format)
+	}
+}
+
+// sampled wraps core so that records at or below the sampling level go through a sampler
+// while more severe records are always written.
+func sampled(core zapcore.Core, sampling SamplingConfig) (zapcore.Core, error) {
+	if sampling.Initial <= 0 {
+		return core, nil
+	}
+
+	maxLevel := zapcore.DebugLevel
+	if sampling.Level != "" {
+		var err error
+		if maxLevel, err = parseLevel(sampling.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	tick := sampling.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	sampledCore := zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+
+	return zapcore.NewTee(
+		&levelFilter{Core: sampledCore, enabled: func(level zapcore.Level) bool { return level <= maxLevel }},
+		&levelFilter{Core: core, enabled: func(level zapcore.Level) bool { return level > maxLevel }},
+	), nil
+}
+
+// levelFilter restricts core to the levels accepted by enabled on top of its own level.
+type levelFilter struct {
+	zapcore.Core
+	enabled func(zapcore.Level) bool
+}
+
+func (f *levelFilter) Enabled(level zapcore.Level) bool {
+	return f.enabled(level) && f.Core.Enabled(level)
+}
+
+func (f *levelFilter) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilter{Core: f.Core.With(fields), enabled: f.enabled}
+}
+
+func (f *levelFilter) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !f.enabled(entry.Level) {
+		return checked
+	}
+
+	return f.Core.Check(entry, checked)
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return zapcore.DebugLevel, nil
+	case "INFO":
+		return zapcore.InfoLevel, nil
+	case "WARN":
+		return zapcore.WarnLevel, nil
+	case "ERROR":
+		return zapcore.ErrorLevel, nil
+	case "PANIC":
+		return zapcore.PanicLevel, nil
+	case "FATAL":
+		return zapcore.FatalLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("unsupported level of logger: %s", strings.ToUpper(level))
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelState describes the current level; ExpiresAt is set while a temporary override is active,
+// DefaultLevel is restored when it expires.
+type LevelState struct {
+	Level        string    `json:"level"`
+	DefaultLevel string    `json:"defaultLevel"`
+	ExpiresAt    time.Time `json:"expiresAt,omitempty"`
+}
+
+// levels backs the atomic level shared by a logger and all its children.
+type levels struct {
+	mu        sync.Mutex
+	atomic    zap.AtomicLevel
+	base      zapcore.Level
+	expiresAt time.Time
+	revert    *time.Timer
+}
+
+func newLevels(level zapcore.Level) *levels {
+	return &levels{atomic: zap.NewAtomicLevelAt(level), base: level}
+}
+
+func (lv *levels) set(level zapcore.Level, ttl time.Duration) LevelState {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if lv.revert != nil {
+		lv.revert.Stop()
+		lv.revert = nil
+	}
+
+	lv.atomic.SetLevel(level)
+	lv.expiresAt = time.Time{}
+
+	if ttl <= 0 {
+		lv.base = level
+		return lv.stateLocked()
+	}
+
+	lv.expiresAt = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		lv.mu.Lock()
+		defer lv.mu.Unlock()
+
+		// a newer call has replaced this override
+		if lv.revert != timer {
+			return
+		}
+
+		lv.atomic.SetLevel(lv.base)
+		lv.expiresAt = time.Time{}
+		lv.revert = nil
+	})
+	lv.revert = timer
+
+	return lv.stateLocked()
+}
+
+func (lv *levels) state() LevelState {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	return lv.stateLocked()
+}
+
+func (lv *levels) stateLocked() LevelState {
+	return LevelState{
+		Level:        levelName(lv.atomic.Level()),
+		DefaultLevel: levelName(lv.base),
+		ExpiresAt:    lv.expiresAt,
+	}
+}
+
+func levelName(level zapcore.Level) string {
+	return level.CapitalString()
+}
+
+// SetLevel changes the level of the logger and all its children at runtime. With a positive ttl the level
+// is a temporary override and the default level is restored once it expires; otherwise it becomes the new default.
+func (l *ZapLogger) SetLevel(level string, ttl time.Duration) (LevelState, error) {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return LevelState{}, err
+	}
+
+	return l.levels.set(parsed, ttl), nil
+}
+
+func (l *ZapLogger) LevelState() LevelState {
+	return l.levels.state()
+}
+
+type setLevelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// LevelHandler serves the level over HTTP: GET returns the LevelState, PUT takes {"level": "DEBUG", "ttl": "10m"}
+// where ttl is optional. It has no authentication of its own, the caller must let only admins reach it.
+func (l *ZapLogger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeLevelJSON(w, http.StatusOK, l.LevelState())
+		case http.MethodPut, http.MethodPost:
+			var req setLevelRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+				return
+			}
+
+			var ttl time.Duration
+			if req.TTL != "" {
+				var err error
+				if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl < 0 {
+					writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ttl: " + req.TTL})
+					return
+				}
+			}
+
+			state, err := l.SetLevel(req.Level, ttl)
+			if err != nil {
+				writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+
+			l.Warn("log level was changed over http", map[string]interface{}{"level": state.Level, "ttl": ttl.String()})
+			writeLevelJSON(w, http.StatusOK, state)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		}
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	logger, err := New(Config{Level: "INFO", Format: FormatJSON, Sinks: []SinkConfig{{Type: SinkFile, File: FileConfig{Path: path}}}})
+	require.NoError(t, err)
+	child := logger.With(map[string]interface{}{"request_id": "req-1"})
+
+	child.Debug("hidden debug", nil)
+
+	state, err := logger.SetLevel("debug", 0)
+	require.NoError(t, err)
+	require.Equal(t, LevelState{Level: "DEBUG", DefaultLevel: "DEBUG"}, state)
+	child.Debug("visible debug", nil)
+
+	_, err = logger.SetLevel("verbose", 0)
+	require.Error(t, err)
+	require.Equal(t, "DEBUG", logger.LevelState().Level)
+
+	require.NoError(t, logger.Sync())
+	lines := readLines(t, path)
+	require.Len(t, lines, 1)
+	require.Equal(t, "visible debug", lines[0]["msg"])
+}
+
+func TestSetLevelOverride(t *testing.T) {
+	logger, err := GetLogger("WARN")
+	require.NoError(t, err)
+
+	state, err := logger.SetLevel("DEBUG", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, "DEBUG", state.Level)
+	require.Equal(t, "WARN", state.DefaultLevel)
+	require.False(t, state.ExpiresAt.IsZero())
+
+	require.Eventually(t, func() bool {
+		return logger.LevelState() == LevelState{Level: "WARN", DefaultLevel: "WARN"}
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSetLevelReplacesOverride(t *testing.T) {
+	logger, err := GetLogger("WARN")
+	require.NoError(t, err)
+
+	_, err = logger.SetLevel("DEBUG", 30*time.Millisecond)
+	require.NoError(t, err)
+	_, err = logger.SetLevel("INFO", time.Hour)
+	require.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, "INFO", logger.LevelState().Level)
+
+	// a permanent change cancels the pending override
+	_, err = logger.SetLevel("ERROR", 0)
+	require.NoError(t, err)
+	require.Equal(t, LevelState{Level: "ERROR", DefaultLevel: "ERROR"}, logger.LevelState())
+}
+
+func TestSinkLevelWithRuntimeLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	logger, err := New(Config{
+		Level:  "ERROR",
+		Format: FormatJSON,
+		Sinks:  []SinkConfig{{Type: SinkFile, Level: "INFO", File: FileConfig{Path: path}}},
+	})
+	require.NoError(t, err)
+
+	logger.Warn("hidden warn", nil)
+	_, err = logger.SetLevel("DEBUG", 0)
+	require.NoError(t, err)
+	logger.Debug("hidden debug", nil)
+	logger.Warn("visible warn", nil)
+	require.NoError(t, logger.Sync())
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 1)
+	require.Equal(t, "visible warn", lines[0]["msg"])
+}
+
+func TestLevelHandler(t *testing.T) {
+	logger, err := GetLogger("INFO")
+	require.NoError(t, err)
+	server := httptest.NewServer(logger.LevelHandler())
+	defer server.Close()
+
+	do := func(method, body string) (int, string) {
+		req, err := http.NewRequestWithContext(context.Background(), method, server.URL, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(data)
+	}
+
+	code, body := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, `{"level":"INFO","defaultLevel":"INFO","expiresAt":"0001-01-01T00:00:00Z"}`, body)
+
+	code, body = do(http.MethodPut, `{"level":"DEBUG","ttl":"1h"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, `"level":"DEBUG"`)
+	require.Contains(t, body, `"defaultLevel":"INFO"`)
+	require.Equal(t, "DEBUG", logger.LevelState().Level)
+
+	code, _ = do(http.MethodPut, `{"level":"loud"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do(http.MethodPut, `{"level":"INFO","ttl":"soon"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do(http.MethodDelete, "")
+	require.Equal(t, http.StatusMethodNotAllowed, code)
+}
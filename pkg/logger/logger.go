@@ -2,52 +2,38 @@ package logger
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"strings"
+	"errors"
+	"io"
+	"sort"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 )
 
+// ZapLogger passes every message and field through the redactor, so secrets never reach the encoder.
 type ZapLogger struct {
-	logger *zap.SugaredLogger
+	logger   *zap.SugaredLogger
+	redactor *redactor
+	levels   *levels
+	closers  []io.Closer
 }
 
 type KeyLogger string
 
+// GetLogger builds a console logger writing to stdout, see New for configurable output.
 func GetLogger(level string) (*ZapLogger, error) {
-	level = strings.ToUpper(level)
-	var zapLevel zapcore.Level
-	switch level {
-	case "DEBUG":
-		zapLevel = zapcore.DebugLevel
-	case "INFO":
-		zapLevel = zapcore.InfoLevel
-	case "WARN":
-		zapLevel = zapcore.WarnLevel
-	case "ERROR":
-		zapLevel = zapcore.ErrorLevel
-	case "PANIC":
-		zapLevel = zapcore.PanicLevel
-	case "FATAL":
-		zapLevel = zapcore.FatalLevel
-	default:
-		return nil, fmt.Errorf("unsupported level of logger: %s", level)
-	}
-
-	logger := zap.New(zapcore.NewTee(
-		zapcore.NewCore(
-			zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
-			zapcore.Lock(os.Stdout),
-			zapLevel,
-		),
-	),
-	)
+	return New(Config{Level: level})
+}
 
-	logg := logger.Sugar()
+// Sync flushes buffered records. On the root logger it also closes file sinks, so it is called once on exit;
+// child loggers only flush.
+func (l *ZapLogger) Sync() error {
+	err := l.logger.Sync()
+	for _, closer := range l.closers {
+		err = errors.Join(err, closer.Close())
+	}
 
-	return &ZapLogger{logg}, nil
+	return err
 }
 
 func ContextWithLogger(ctx context.Context, logger *ZapLogger) context.Context {
@@ -55,29 +41,70 @@ func ContextWithLogger(ctx context.Context, logger *ZapLogger) context.Context {
 }
 
 func GetLoggerFromContext(ctx context.Context) (*ZapLogger, error) {
-	if l, ok := ctx.Value(KeyLogger("logger")).(*ZapLogger); ok {
+	if l, ok := FromContext(ctx); ok {
 		return l, nil
 	}
 
 	return GetLogger("info")
 }
 
+// FromContext returns the logger stored by ContextWithLogger, unlike GetLoggerFromContext it lets
+// the caller choose the fallback.
+func FromContext(ctx context.Context) (*ZapLogger, bool) {
+	l, ok := ctx.Value(KeyLogger("logger")).(*ZapLogger)
+	return l, ok
+}
+
+// With returns a child logger adding fields to every record.
+func (l *ZapLogger) With(fields map[string]interface{}) *ZapLogger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, zap.Any(key, l.redactor.field(key, fields[key], 0)))
+	}
+
+	return &ZapLogger{logger: l.logger.With(args...), redactor: l.redactor, levels: l.levels}
+}
+
+// WithContext returns a child logger adding trace_id and span_id of the span carried by ctx to every record.
+// The logger itself is returned when ctx has no valid span.
+func (l *ZapLogger) WithContext(ctx context.Context) *ZapLogger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return l
+	}
+
+	return &ZapLogger{
+		logger: l.logger.With(
+			zap.String("trace_id", spanContext.TraceID().String()),
+			zap.String("span_id", spanContext.SpanID().String()),
+		),
+		redactor: l.redactor,
+		levels:   l.levels,
+	}
+}
+
 func (l *ZapLogger) Debug(msg string, fields map[string]interface{}) {
-	l.logger.Debug(msg, zap.Any("args", fields))
+	l.logger.Debugw(l.redactor.message(msg), zap.Any("args", l.redactor.fields(fields)))
 }
 
 func (l *ZapLogger) Info(msg string, fields map[string]interface{}) {
-	l.logger.Infow(msg, zap.Any("args", fields))
+	l.logger.Infow(l.redactor.message(msg), zap.Any("args", l.redactor.fields(fields)))
 }
 
 func (l *ZapLogger) Warn(msg string, fields map[string]interface{}) {
-	l.logger.Warnw(msg, zap.Any("args", fields))
+	l.logger.Warnw(l.redactor.message(msg), zap.Any("args", l.redactor.fields(fields)))
 }
 
 func (l *ZapLogger) Error(msg string, fields map[string]interface{}) {
-	l.logger.Errorw(msg, zap.Any("args", fields))
+	l.logger.Errorw(l.redactor.message(msg), zap.Any("args", l.redactor.fields(fields)))
 }
 
 func (l *ZapLogger) Fatal(msg string, fields map[string]interface{}) {
-	l.logger.Fatalw(msg, zap.Any("args", fields))
+	l.logger.Fatalw(l.redactor.message(msg), zap.Any("args", l.redactor.fields(fields)))
 }
@@ -2,10 +2,16 @@ package logger
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -76,3 +82,193 @@ func TestLogOutputToStdout(t *testing.T) {
 
 	require.Contains(t, string(got), "Test logger message", "Expected log message not found in file output")
 }
+
+func TestLogWithTraceContext(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	logger, err := GetLogger("debug")
+	require.NoError(t, err)
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	require.Same(t, logger, logger.WithContext(context.Background()))
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	logger.WithContext(ctx).Info("Traced message", nil)
+	err = w.Close()
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	require.Contains(t, string(got), `"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736"`)
+	require.Contains(t, string(got), `"span_id": "00f067aa0ba902b7"`)
+}
+
+func TestLoggerWith(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	logger, err := GetLogger("debug")
+	require.NoError(t, err)
+	defer func() {
+		os.Stdout = oldStdout
+	}()
+
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+
+	ctx := ContextWithLogger(context.Background(), logger.With(map[string]interface{}{"request_id": "req-1"}))
+	requestLogger, ok := FromContext(ctx)
+	require.True(t, ok)
+	requestLogger.Info("Scoped message", nil)
+	logger.Info("Plain message", nil)
+	require.NoError(t, w.Close())
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"request_id": "req-1"`)
+	require.NotContains(t, lines[1], "request_id")
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"invalid level", Config{Level: "verbose"}},
+		{"invalid format", Config{Level: "INFO", Format: "xml"}},
+		{"invalid sink", Config{Level: "INFO", Sinks: []SinkConfig{{Type: "syslog"}}}},
+		{"invalid sink level", Config{Level: "INFO", Sinks: []SinkConfig{{Type: SinkStdout, Level: "loud"}}}},
+		{"file without path", Config{Level: "INFO", Sinks: []SinkConfig{{Type: SinkFile}}}},
+		{"invalid sampling level", Config{Level: "INFO", Sinks: []SinkConfig{{Type: SinkStdout, Sampling: SamplingConfig{Initial: 1, Level: "x"}}}}},
+		{"invalid redaction pattern", Config{Level: "INFO", RedactPatterns: []string{"("}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Error(t, test.config.Validate())
+
+			logger, err := New(test.config)
+			require.Error(t, err)
+			require.Nil(t, logger)
+		})
+	}
+}
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+		lines = append(lines, decoded)
+	}
+
+	return lines
+}
+
+func TestFileSinks(t *testing.T) {
+	dir := t.TempDir()
+	allPath := filepath.Join(dir, "all.log")
+	warnPath := filepath.Join(dir, "warn.log")
+
+	logger, err := New(Config{
+		Level:  "DEBUG",
+		Format: FormatJSON,
+		Sinks: []SinkConfig{
+			{Type: SinkFile, File: FileConfig{Path: allPath, MaxSizeMB: 1}},
+			{Type: SinkFile, Level: "WARN", File: FileConfig{Path: warnPath, MaxSizeMB: 1}},
+		},
+	})
+	require.NoError(t, err)
+
+	logger.Debug("debug message", nil)
+	logger.Info("info message", map[string]interface{}{"id": "42"})
+	logger.Warn("warn message", nil)
+	logger.Error("error message", nil)
+	require.NoError(t, logger.Sync())
+
+	all := readLines(t, allPath)
+	require.Len(t, all, 4)
+	require.Equal(t, "info message", all[1]["msg"])
+	require.Equal(t, "info", all[1]["level"])
+	require.Equal(t, "42", all[1]["args"].(map[string]interface{})["id"])
+
+	warn := readLines(t, warnPath)
+	require.Len(t, warn, 2)
+	require.Equal(t, "warn message", warn[0]["msg"])
+	require.Equal(t, "error message", warn[1]["msg"])
+}
+
+func TestSinkNeverBelowLoggerLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	logger, err := New(Config{
+		Level:  "WARN",
+		Format: FormatJSON,
+		Sinks:  []SinkConfig{{Type: SinkFile, Level: "DEBUG", File: FileConfig{Path: path}}},
+	})
+	require.NoError(t, err)
+
+	logger.Info("info message", nil)
+	logger.Warn("warn message", nil)
+	require.NoError(t, logger.Sync())
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 1)
+	require.Equal(t, "warn message", lines[0]["msg"])
+}
+
+func TestSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	logger, err := New(Config{
+		Level:  "DEBUG",
+		Format: FormatJSON,
+		Sinks: []SinkConfig{{
+			Type:     SinkFile,
+			File:     FileConfig{Path: path},
+			Sampling: SamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Minute},
+		}},
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		logger.Debug("noisy message", nil)
+		logger.Info("important message", nil)
+	}
+	require.NoError(t, logger.Sync())
+
+	counts := make(map[string]int)
+	for _, line := range readLines(t, path) {
+		counts[line["msg"].(string)]++
+	}
+
+	// the first 2 debug records and then every 3rd of the remaining 8
+	require.Equal(t, 4, counts["noisy message"])
+	require.Equal(t, 10, counts["important message"])
+}
@@ -0,0 +1,221 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces every secret before the record reaches the encoder.
+const Redacted = "[REDACTED]"
+
+const maxRedactDepth = 8
+
+// defaultSensitiveKeys are matched against field names lowercased and stripped of '_' and '-',
+// so "initAdminPassword", "secret_key" and "X-Api-Key" are all redacted.
+var defaultSensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "authorization", "apikey", "pepper", "privatekey", "cookie", "credential",
+}
+
+// defaultSensitivePatterns are replaced inside messages and string values whatever the field name is.
+var defaultSensitivePatterns = []redactPattern{
+	{re: regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`)}, // bcrypt hash
+	{re: regexp.MustCompile(`\b(Basic|Bearer) [A-Za-z0-9+/._~-]{8,}=*`), replace: redactAuthorization},
+}
+
+type redactPattern struct {
+	re *regexp.Regexp
+	// replace decides per match, nil replaces every match with Redacted
+	replace func(match string) string
+}
+
+// redactAuthorization keeps the scheme of an Authorization value and hides its credentials. Plain words
+// ("Basic validation") are left alone: credentials contain digits, symbols or inner upper case letters.
+func redactAuthorization(match string) string {
+	scheme, credentials, _ := strings.Cut(match, " ")
+	if strings.IndexFunc(credentials[1:], func(r rune) bool { return !unicode.IsLower(r) }) < 0 {
+		return match
+	}
+
+	return scheme + " " + Redacted
+}
+
+// Sensitive wraps a value that must never be logged, it is written as [REDACTED] whatever its field name is.
+type Sensitive struct {
+	Value interface{}
+}
+
+func (s Sensitive) String() string {
+	return Redacted
+}
+
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+type redactor struct {
+	keys     []string
+	patterns []redactPattern
+}
+
+func newRedactor(keys, patterns []string) (*redactor, error) {
+	r := &redactor{}
+	for _, key := range append(append([]string{}, defaultSensitiveKeys...), keys...) {
+		r.keys = append(r.keys, normalizeKey(key))
+	}
+
+	r.patterns = append(r.patterns, defaultSensitivePatterns...)
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+
+		r.patterns = append(r.patterns, redactPattern{re: compiled})
+	}
+
+	return r, nil
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(key))
+}
+
+func (r *redactor) sensitiveKey(key string) bool {
+	normalized := normalizeKey(key)
+	for _, sensitive := range r.keys {
+		if strings.Contains(normalized, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *redactor) message(msg string) string {
+	for _, pattern := range r.patterns {
+		if pattern.replace == nil {
+			msg = pattern.re.ReplaceAllString(msg, Redacted)
+		} else {
+			msg = pattern.re.ReplaceAllStringFunc(msg, pattern.replace)
+		}
+	}
+
+	return msg
+}
+
+func (r *redactor) fields(fields map[string]interface{}) map[string]interface{} {
+	if fields == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		redacted[key] = r.field(key, value, 0)
+	}
+
+	return redacted
+}
+
+// field returns a copy of value safe to encode: values of sensitive keys are replaced, strings and errors
+// are matched against the patterns, and maps, slices and structs are walked recursively.
+func (r *redactor) field(key string, value interface{}, depth int) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	if r.sensitiveKey(key) {
+		return Redacted
+	}
+
+	switch v := value.(type) {
+	case Sensitive, *Sensitive:
+		return Redacted
+	case string:
+		return r.message(v)
+	case error:
+		return r.message(v.Error())
+	case fmt.Stringer:
+		if _, ok := value.(json.Marshaler); !ok {
+			return r.message(v.String())
+		}
+	}
+
+	if depth >= maxRedactDepth {
+		return fmt.Sprintf("[too deep: %T]", value)
+	}
+
+	return r.reflectValue(reflect.ValueOf(value), depth)
+}
+
+func (r *redactor) reflectValue(value reflect.Value, depth int) interface{} {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		return r.field("", value.Elem().Interface(), depth+1)
+	case reflect.Map:
+		redacted := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			redacted[key] = r.field(key, iter.Value().Interface(), depth+1)
+		}
+
+		return redacted
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return Redacted // raw bytes are never logged, they may hold keys or hashes
+		}
+
+		redacted := make([]interface{}, value.Len())
+		for i := range redacted {
+			redacted[i] = r.field("", value.Index(i).Interface(), depth+1)
+		}
+
+		return redacted
+	case reflect.Struct:
+		if _, ok := value.Interface().(encoding.TextMarshaler); ok {
+			return value.Interface() // e.g. time.Time, encoded as is
+		}
+
+		return r.reflectStruct(value, depth)
+	case reflect.String:
+		return r.message(value.String())
+	default:
+		return value.Interface()
+	}
+}
+
+func (r *redactor) reflectStruct(value reflect.Value, depth int) map[string]interface{} {
+	redacted := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		// the Go field name is checked too, so a renamed `json:"p"` Password field is still redacted
+		if r.sensitiveKey(field.Name) {
+			redacted[name] = Redacted
+			continue
+		}
+
+		redacted[name] = r.field(name, value.Field(i).Interface(), depth+1)
+	}
+
+	return redacted
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const (
+	bcryptHash  = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+	basicHeader = "Basic YWRtaW46YWRtaW4="
+)
+
+type account struct {
+	Name     string `json:"name"`
+	Password string `json:"p"`
+	Created  time.Time
+	Nested   *account
+}
+
+// fatalHook keeps Fatal from exiting the test binary, zap refuses zapcore.WriteThenNoop for it.
+type fatalHook struct{}
+
+func (fatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
+
+// observedLogger returns a logger whose core records entries exactly as they would be handed to the encoder.
+func observedLogger(t *testing.T, keys, patterns []string) (*ZapLogger, *observer.ObservedLogs) {
+	t.Helper()
+
+	redactor, err := newRedactor(keys, patterns)
+	require.NoError(t, err)
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	return &ZapLogger{logger: zap.New(core).Sugar(), redactor: redactor}, logs
+}
+
+// encoded renders every observed entry with the JSON encoder.
+func encoded(t *testing.T, logs *observer.ObservedLogs) string {
+	t.Helper()
+
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	var sb strings.Builder
+	for _, entry := range logs.All() {
+		buf, err := encoder.EncodeEntry(entry.Entry, entry.Context)
+		require.NoError(t, err)
+		sb.WriteString(buf.String())
+	}
+
+	return sb.String()
+}
+
+func TestRedaction(t *testing.T) {
+	secrets := []string{"hunter2", "s3cr3t-key", "tok-123456", bcryptHash, "YWRtaW46YWRtaW4=", "api-key-1", "very-private", "deep-secret"}
+
+	tests := []struct {
+		name string
+		log  func(l *ZapLogger)
+	}{
+		{"sensitive keys", func(l *ZapLogger) {
+			l.Fatal("init failed", map[string]interface{}{"initAdminPassword": "hunter2", "secretKey": "s3cr3t-key", "name": "admin"})
+		}},
+		{"key variants", func(l *ZapLogger) {
+			l.Info("request", map[string]interface{}{"access_token": "tok-123456", "X-Api-Key": "api-key-1"})
+		}},
+		{"bcrypt hash value", func(l *ZapLogger) {
+			l.Debug("user loaded", map[string]interface{}{"hash": bcryptHash, "list": []string{bcryptHash}})
+		}},
+		{"basic header in message", func(l *ZapLogger) {
+			l.Warn("bad header "+basicHeader, map[string]interface{}{"header": basicHeader})
+		}},
+		{"error value", func(l *ZapLogger) {
+			l.Error("failed", map[string]interface{}{"error": errors.New("cannot parse " + basicHeader)})
+		}},
+		{"sensitive wrapper", func(l *ZapLogger) {
+			l.Info("custom", map[string]interface{}{"value": Sensitive{Value: "very-private"}, "ptr": &Sensitive{Value: "very-private"}})
+		}},
+		{"structs", func(l *ZapLogger) {
+			l.Info("account", map[string]interface{}{"account": account{
+				Name: "admin", Password: "hunter2", Nested: &account{Name: "child", Password: "deep-secret"},
+			}})
+		}},
+		{"nested maps", func(l *ZapLogger) {
+			l.Info("nested", map[string]interface{}{"data": map[string]interface{}{"inner": map[string]string{"password": "hunter2"}}})
+		}},
+		{"child logger", func(l *ZapLogger) {
+			l.With(map[string]interface{}{"token": "tok-123456"}).Info("scoped", nil)
+		}},
+		{"custom key", func(l *ZapLogger) {
+			l.Info("custom key", map[string]interface{}{"ssn": "very-private"})
+		}},
+		{"custom pattern", func(l *ZapLogger) {
+			l.Info("custom pattern deep-secret", nil)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, logs := observedLogger(t, []string{"ssn"}, []string{`deep-\w+`})
+			l.logger = l.logger.WithOptions(zap.WithFatalHook(fatalHook{}))
+
+			test.log(l)
+
+			require.Equal(t, 1, logs.Len())
+			output := encoded(t, logs)
+			require.Contains(t, output, Redacted)
+			for _, secret := range secrets {
+				require.NotContains(t, output, secret)
+			}
+		})
+	}
+}
+
+func TestRedactionKeepsOtherValues(t *testing.T) {
+	l, logs := observedLogger(t, nil, nil)
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l.Info("Basic validation passed", map[string]interface{}{
+		"name":    "admin",
+		"count":   3,
+		"account": account{Name: "admin", Created: created},
+	})
+
+	output := encoded(t, logs)
+	require.Contains(t, output, `"msg":"Basic validation passed"`)
+	require.Contains(t, output, `"name":"admin"`)
+	require.Contains(t, output, `"count":3`)
+	require.Contains(t, output, `"Created":"2024-01-01T00:00:00Z"`)
+	require.Contains(t, output, `"p":"[REDACTED]"`)
+}
+
+func TestInvalidRedactPattern(t *testing.T) {
+	_, err := New(Config{Level: "INFO", RedactPatterns: []string{"("}})
+	require.Error(t, err)
+}
@@ -6,13 +6,19 @@ import (
 
 type Validator struct {
 	EmailRegex *regexp.Regexp
+	PhoneRegex *regexp.Regexp
 }
 
 func New() *Validator {
 	regex := regexp.MustCompile(`[a-zA-Z0-9]+(?:\.[a-zA-Z0-9]+)*@[a-zA-Z0-9]+(?:\.[a-zA-Z0-9]+)*`)
-	return &Validator{EmailRegex: regex}
+	phoneRegex := regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	return &Validator{EmailRegex: regex, PhoneRegex: phoneRegex}
 }
 
 func (v *Validator) IsEmail(email string) bool {
 	return v.EmailRegex.MatchString(email)
 }
+
+func (v *Validator) IsPhoneNumber(phone string) bool {
+	return v.PhoneRegex.MatchString(phone)
+}
@@ -22,3 +22,25 @@ func TestIsEmail(t *testing.T) {
 		}
 	}
 }
+
+func TestIsPhoneNumber(t *testing.T) {
+	validator := New()
+	tests := []struct {
+		phone    string
+		expected bool
+	}{
+		{"+375291234567", true},
+		{"+14155552671", true},
+		{"375291234567", false},
+		{"+0291234567", false},
+		{"+37529-123-45-67", false},
+		{"+123", false},
+	}
+
+	for _, test := range tests {
+		result := validator.IsPhoneNumber(test.phone)
+		if result != test.expected {
+			t.Errorf("Expected IsPhoneNumber(%s) to be %v, but got %v", test.phone, test.expected, result)
+		}
+	}
+}